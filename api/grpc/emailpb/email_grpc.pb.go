@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package emailpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// EmailServiceClient is the client API for EmailService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EmailServiceClient interface {
+	// SendEmail sends an email, or schedules it if send_at is set.
+	SendEmail(ctx context.Context, in *SendEmailRequest, opts ...grpc.CallOption) (*Email, error)
+	// GetEmail returns an email with its current status. Only for the caller that sent it, or an admin.
+	GetEmail(ctx context.Context, in *GetEmailRequest, opts ...grpc.CallOption) (*Email, error)
+	// WatchEmailStatus returns the email right away, and again whenever its status changes. Only for the caller that sent it, or an admin.
+	//
+	// The stream ends once the status can no longer change, that is failed, cancelled, bounced or complained.
+	WatchEmailStatus(ctx context.Context, in *WatchEmailStatusRequest, opts ...grpc.CallOption) (EmailService_WatchEmailStatusClient, error)
+}
+
+type emailServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEmailServiceClient(cc grpc.ClientConnInterface) EmailServiceClient {
+	return &emailServiceClient{cc}
+}
+
+func (c *emailServiceClient) SendEmail(ctx context.Context, in *SendEmailRequest, opts ...grpc.CallOption) (*Email, error) {
+	out := new(Email)
+	err := c.cc.Invoke(ctx, "/mailer.email.v1.EmailService/SendEmail", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emailServiceClient) GetEmail(ctx context.Context, in *GetEmailRequest, opts ...grpc.CallOption) (*Email, error) {
+	out := new(Email)
+	err := c.cc.Invoke(ctx, "/mailer.email.v1.EmailService/GetEmail", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emailServiceClient) WatchEmailStatus(ctx context.Context, in *WatchEmailStatusRequest, opts ...grpc.CallOption) (EmailService_WatchEmailStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EmailService_ServiceDesc.Streams[0], "/mailer.email.v1.EmailService/WatchEmailStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &emailServiceWatchEmailStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EmailService_WatchEmailStatusClient interface {
+	Recv() (*Email, error)
+	grpc.ClientStream
+}
+
+type emailServiceWatchEmailStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *emailServiceWatchEmailStatusClient) Recv() (*Email, error) {
+	m := new(Email)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// EmailServiceServer is the server API for EmailService service.
+// All implementations must embed UnimplementedEmailServiceServer
+// for forward compatibility
+type EmailServiceServer interface {
+	// SendEmail sends an email, or schedules it if send_at is set.
+	SendEmail(context.Context, *SendEmailRequest) (*Email, error)
+	// GetEmail returns an email with its current status. Only for the caller that sent it, or an admin.
+	GetEmail(context.Context, *GetEmailRequest) (*Email, error)
+	// WatchEmailStatus returns the email right away, and again whenever its status changes. Only for the caller that sent it, or an admin.
+	//
+	// The stream ends once the status can no longer change, that is failed, cancelled, bounced or complained.
+	WatchEmailStatus(*WatchEmailStatusRequest, EmailService_WatchEmailStatusServer) error
+	mustEmbedUnimplementedEmailServiceServer()
+}
+
+// UnimplementedEmailServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedEmailServiceServer struct {
+}
+
+func (UnimplementedEmailServiceServer) SendEmail(context.Context, *SendEmailRequest) (*Email, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendEmail not implemented")
+}
+func (UnimplementedEmailServiceServer) GetEmail(context.Context, *GetEmailRequest) (*Email, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEmail not implemented")
+}
+func (UnimplementedEmailServiceServer) WatchEmailStatus(*WatchEmailStatusRequest, EmailService_WatchEmailStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEmailStatus not implemented")
+}
+func (UnimplementedEmailServiceServer) mustEmbedUnimplementedEmailServiceServer() {}
+
+// UnsafeEmailServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EmailServiceServer will
+// result in compilation errors.
+type UnsafeEmailServiceServer interface {
+	mustEmbedUnimplementedEmailServiceServer()
+}
+
+func RegisterEmailServiceServer(s grpc.ServiceRegistrar, srv EmailServiceServer) {
+	s.RegisterService(&EmailService_ServiceDesc, srv)
+}
+
+func _EmailService_SendEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmailServiceServer).SendEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mailer.email.v1.EmailService/SendEmail",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmailServiceServer).SendEmail(ctx, req.(*SendEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmailService_GetEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmailServiceServer).GetEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mailer.email.v1.EmailService/GetEmail",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmailServiceServer).GetEmail(ctx, req.(*GetEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmailService_WatchEmailStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEmailStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EmailServiceServer).WatchEmailStatus(m, &emailServiceWatchEmailStatusServer{stream})
+}
+
+type EmailService_WatchEmailStatusServer interface {
+	Send(*Email) error
+	grpc.ServerStream
+}
+
+type emailServiceWatchEmailStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *emailServiceWatchEmailStatusServer) Send(m *Email) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// EmailService_ServiceDesc is the grpc.ServiceDesc for EmailService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EmailService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mailer.email.v1.EmailService",
+	HandlerType: (*EmailServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendEmail",
+			Handler:    _EmailService_SendEmail_Handler,
+		},
+		{
+			MethodName: "GetEmail",
+			Handler:    _EmailService_GetEmail_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEmailStatus",
+			Handler:       _EmailService_WatchEmailStatus_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/grpc/emailpb/email.proto",
+}
@@ -8,7 +8,9 @@ type ErrorDto struct {
 	Timestamp string `json:"timestamp"`
 	// The request id associated with this request
 	RequestId string `json:"requestid"`
-	// The error code
+	// The error code, the api description lists them all
+	//
+	// enum: ["auth.unauthorized.error","auth.forbidden.error","request.toolarge.error","request.validation.error","response.validation.error","swagger.spec.error","email.parse.error","email.validation.error","email.send.error","email.notfound.error","email.notpending.error","email.cancel.error","email.query.error","email.read.error","audit.query.error","audit.read.error","bounce.parse.error","bounce.notcorrelated.error","bounce.process.error","subject.address.invalid","subject.erase.error","webhook.parse.error","webhook.validation.error","webhook.notfound.error","webhook.error","logger.parse.error","logger.notfound.error","logger.validation.error"]
 	Message   string `json:"message"`
 	// Additional details
 	Details   []string `json:"details"`
@@ -16,7 +18,7 @@ type ErrorDto struct {
 
 // this seems necessary to reference a model
 
-// The generic error response.
+// The generic error response, an errorDto, or a problemDto if requested with Accept: application/problem+json.
 //
 // swagger:response errorResponse
 type ErrorResponse struct {
@@ -24,4 +26,31 @@ type ErrorResponse struct {
 	//
 	// in:body
 	Body ErrorDto
-}
\ No newline at end of file
+}
+// ProblemContentType is the media type of ProblemDto, see RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix is followed by the error code in ProblemDto.Type.
+const ProblemTypePrefix = "urn:mailer-service:problem:"
+
+// Model for the error response as an RFC 7807 problem.
+//
+// Sent instead of errorDto if the request accepts application/problem+json rather than application/json.
+//
+// swagger:model problemDto
+type ProblemDto struct {
+	// The error code prefixed with urn:mailer-service:problem:
+	Type string `json:"type"`
+	// A short description of the error code, the same for every occurrence
+	Title string `json:"title"`
+	// The http status
+	Status int `json:"status"`
+	// The details of this occurrence, if any
+	Detail string `json:"detail,omitempty"`
+	// The path of the request
+	Instance string `json:"instance"`
+	// The request id associated with this request
+	RequestId string `json:"requestid"`
+	// The timestamp at which the error occurred
+	Timestamp string `json:"timestamp"`
+}
@@ -0,0 +1,63 @@
+package apierrors
+
+import "net/http"
+
+// ErrorCode is one of the values of ErrorDto.Message, together with the status and title it is always reported with.
+//
+// All codes are registered here, so they can be documented in one place. When you add one, also add it to
+// the description in docs/swagger.go and to the enum of ErrorDto.Message.
+type ErrorCode struct {
+	Code   string
+	Status int
+	Title  string
+}
+
+var registry []ErrorCode
+
+func register(code string, status int, title string) ErrorCode {
+	errorCode := ErrorCode{Code: code, Status: status, Title: title}
+	registry = append(registry, errorCode)
+	return errorCode
+}
+
+var (
+	AuthUnauthorized   = register("auth.unauthorized.error", http.StatusUnauthorized, "Authentication required")
+	AuthForbidden      = register("auth.forbidden.error", http.StatusForbidden, "Not allowed")
+	RequestTooLarge    = register("request.toolarge.error", http.StatusRequestEntityTooLarge, "Request body too large")
+	RequestValidation  = register("request.validation.error", http.StatusBadRequest, "Request does not match the api spec")
+	ResponseValidation = register("response.validation.error", http.StatusInternalServerError, "Response does not match the api spec")
+	SwaggerSpec        = register("swagger.spec.error", http.StatusInternalServerError, "Api spec unavailable")
+
+	EmailParse      = register("email.parse.error", http.StatusBadRequest, "Email could not be parsed")
+	EmailValidation = register("email.validation.error", http.StatusBadRequest, "Email is invalid")
+	EmailSend       = register("email.send.error", http.StatusInternalServerError, "Email could not be sent")
+	EmailNotFound   = register("email.notfound.error", http.StatusNotFound, "Email not found")
+	EmailNotPending = register("email.notpending.error", http.StatusConflict, "Email is no longer pending")
+	EmailCancel     = register("email.cancel.error", http.StatusInternalServerError, "Email could not be cancelled")
+	EmailQuery      = register("email.query.error", http.StatusBadRequest, "Invalid email query")
+	EmailRead       = register("email.read.error", http.StatusInternalServerError, "Email could not be read")
+
+	AuditQuery = register("audit.query.error", http.StatusBadRequest, "Invalid audit query")
+	AuditRead  = register("audit.read.error", http.StatusInternalServerError, "Audit log could not be read")
+
+	BounceParse         = register("bounce.parse.error", http.StatusBadRequest, "Bounce could not be parsed")
+	BounceNotCorrelated = register("bounce.notcorrelated.error", http.StatusNotFound, "Bounce does not match a sent email")
+	BounceProcess       = register("bounce.process.error", http.StatusInternalServerError, "Bounce could not be processed")
+
+	SubjectAddressInvalid = register("subject.address.invalid", http.StatusBadRequest, "Invalid email address")
+	SubjectErase          = register("subject.erase.error", http.StatusInternalServerError, "Subject could not be erased")
+
+	WebhookParse      = register("webhook.parse.error", http.StatusBadRequest, "Webhook could not be parsed")
+	WebhookValidation = register("webhook.validation.error", http.StatusBadRequest, "Webhook is invalid")
+	WebhookNotFound   = register("webhook.notfound.error", http.StatusNotFound, "Webhook not found")
+	WebhookFailed     = register("webhook.error", http.StatusInternalServerError, "Webhook could not be stored")
+
+	LoggerParse      = register("logger.parse.error", http.StatusBadRequest, "Logger could not be parsed")
+	LoggerNotFound   = register("logger.notfound.error", http.StatusNotFound, "Logger not found")
+	LoggerValidation = register("logger.validation.error", http.StatusBadRequest, "Invalid log level")
+)
+
+// ErrorCodes lists all registered codes in the order they were registered.
+func ErrorCodes() []ErrorCode {
+	return append([]ErrorCode{}, registry...)
+}
@@ -0,0 +1,138 @@
+package audit
+
+import "github.com/gin-gonic/gin"
+
+// --- models ---
+
+// Model for AuditEntryDto.
+//
+// swagger:model auditEntryDto
+type AuditEntryDto struct {
+	// Position in the hash chain, starting at 1
+	Sequence uint64 `json:"seq"`
+	// When the entry was recorded, in RFC 3339 format
+	Timestamp string `json:"timestamp"`
+	// The subject of the caller, kafka:<topic> for commands, blank for the scheduler
+	Subject string `json:"subject,omitempty"`
+	// The roles of the caller
+	Roles []string `json:"roles,omitempty"`
+	// The id of the request that caused the send attempt
+	RequestId string `json:"request_id,omitempty"`
+	// The id of the email, blank if it was rejected
+	EmailId string `json:"email_id,omitempty"`
+	// The recipient addresses, or hmac-sha256:<hex> of the lower case addresses if audit.recipients is hashed
+	Recipients []string `json:"recipients"`
+	// The id of the template the email was rendered from, currently always blank
+	TemplateId string `json:"template_id,omitempty"`
+	// One of accepted, rejected, sent, failed
+	Outcome string `json:"outcome"`
+	// The rejection reason or transport error
+	Detail string `json:"detail,omitempty"`
+	// The hash of the previous entry, blank for the first entry
+	PrevHash string `json:"prev_hash"`
+	// The hex encoded sha256 of this entry rendered with an empty hash
+	Hash string `json:"hash"`
+}
+
+// Model for AuditEntryListDto.
+//
+// swagger:model auditEntryListDto
+type AuditEntryListDto struct {
+	// The matching entries, oldest first
+	Entries []AuditEntryDto `json:"entries"`
+}
+
+// Model for AuditVerificationDto.
+//
+// swagger:model auditVerificationDto
+type AuditVerificationDto struct {
+	// The number of entries checked
+	Entries int `json:"entries"`
+	// Whether the whole chain is intact
+	Valid bool `json:"valid"`
+	// The sequence number of the first entry that fails verification
+	BrokenAt uint64 `json:"broken_at,omitempty"`
+	// Why verification failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// --- parameters and responses --- needed to use models
+
+// Parameters for querying the audit log
+//
+// swagger:parameters queryAuditParams
+type QueryAuditParams struct {
+	// Only entries for this email
+	//
+	// in:query
+	EmailId string `json:"email_id"`
+
+	// Only entries caused by this subject
+	//
+	// in:query
+	Subject string `json:"subject"`
+
+	// Only entries for this recipient address, also matches hashed entries
+	//
+	// in:query
+	Recipient string `json:"recipient"`
+
+	// Only entries with this outcome
+	//
+	// in:query
+	Outcome string `json:"outcome"`
+
+	// Return at most this many of the most recent matches, 1..1000, defaults to 100
+	//
+	// in:query
+	Limit int `json:"limit"`
+}
+
+// The matching audit log entries
+//
+// swagger:response auditEntryListResponse
+type AuditEntryListResponse struct {
+	// in:body
+	Body AuditEntryListDto
+}
+
+// The result of verifying the audit log
+//
+// swagger:response auditVerificationResponse
+type AuditVerificationResponse struct {
+	// in:body
+	Body AuditVerificationDto
+}
+
+// --- routes ---
+
+type AuditApi interface {
+	// swagger:route GET /api/rest/v1/audit audit-tag queryAuditParams
+	// Query the audit log of send attempts. Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: auditEntryListResponse
+	//   400: errorResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   500: errorResponse
+	QueryAudit(*gin.Context)
+
+	// swagger:route GET /api/rest/v1/audit/verify audit-tag verifyAuditParams
+	// Verify the hash chain of the audit log, reports the first entry that was changed, removed or reordered. Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: auditVerificationResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   500: errorResponse
+	VerifyAudit(*gin.Context)
+}
@@ -0,0 +1,64 @@
+package bounce
+
+import "github.com/gin-gonic/gin"
+
+// --- models ---
+
+// Model for BounceResultDto.
+//
+// swagger:model bounceResultDto
+type BounceResultDto struct {
+	// The id of the email the report was correlated to
+	EmailId      string `json:"email_id"`
+	// The kind of report, one of hard, soft, complaint, none
+	Kind         string `json:"kind"`
+	// The recipient address the report is about
+	Recipient    string `json:"recipient,omitempty"`
+	// The RFC 3463 status code, for delivery status notifications
+	Status       string `json:"status,omitempty"`
+	// The feedback type, for feedback reports
+	FeedbackType string `json:"feedback_type,omitempty"`
+}
+
+// --- parameters and responses --- needed to use models
+
+// Parameters for submitting bounces
+//
+// swagger:parameters submitBounceParams
+type SubmitBounceParams struct {
+	// The complete bounce message in rfc822 format, with content type multipart/report
+	//
+	// in:body
+	Body string
+}
+
+// The submit bounce response with what was extracted from the report
+//
+// swagger:response submitBounceResponse
+type SubmitBounceResponse struct {
+	// in:body
+	Body BounceResultDto
+}
+
+// --- routes ---
+
+type BounceApi interface {
+	// swagger:route POST /api/rest/v1/bounces bounce-tag submitBounceParams
+	// Submit a delivery status notification (RFC 3464) or feedback report (RFC 5965) for processing.
+	//
+	// Consumes:
+	// - message/rfc822
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: submitBounceResponse
+	//   400: errorResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   500: errorResponse
+	SubmitBounce(*gin.Context)
+}
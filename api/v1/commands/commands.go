@@ -0,0 +1,26 @@
+package commands
+
+import "encoding/json"
+
+// Besides the REST API, the mailer accepts send-email commands from a kafka topic (kafka.topic.commands).
+//
+// A command is a json encoded email.EmailDto. Commands that cannot be parsed, fail validation or cannot
+// be sent are published to the error topic (kafka.topic.errors) as a CommandErrorDto, with the same key
+// and headers as the original command. An X-Request-Id header on the command is used as the request id
+// of the email, so it appears in the mail headers and in events.
+
+// --- models ---
+
+// Model for CommandErrorDto, published for each failed command.
+//
+// swagger:model commandErrorDto
+type CommandErrorDto struct {
+	// The timestamp at which the error occurred
+	Timestamp string          `json:"timestamp"`
+	// The error code, same as the message field of an errorDto
+	Message   string          `json:"message"`
+	// Additional details
+	Details   []string        `json:"details"`
+	// The original command, as a string if it was not valid json
+	Command   json.RawMessage `json:"command"`
+}
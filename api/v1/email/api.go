@@ -9,11 +9,32 @@ import "github.com/gin-gonic/gin"
 // swagger:model emailDto
 type EmailDto struct {
 	// The email address to send to
+	//
+	// required: true
+	// swagger:strfmt email
 	ToAddress string `json:"to_address"`
 	// The email subject
+	//
+	// required: true
 	Subject   string `json:"subject"`
 	// The email body
+	//
+	// required: true
 	Body      string `json:"body"`
+	// Optional RFC 3339 timestamp, if set the email is held and sent at this time
+	//
+	// swagger:strfmt date-time
+	SendAt    string `json:"send_at,omitempty"`
+}
+
+// Model for EmailResultDto.
+//
+// swagger:model emailResultDto
+type EmailResultDto struct {
+	// The id assigned to the email, use it to cancel a scheduled email
+	Id     string `json:"id"`
+	// The status of the email, scheduled or sent
+	Status string `json:"status"`
 }
 
 // --- parameters and responses --- needed to use models
@@ -23,26 +44,73 @@ type EmailDto struct {
 // swagger:parameters sendEmailParams
 type SendEmailParams struct {
 	// in:body
+	// required: true
 	Body EmailDto
 }
 
-// The send email response with just a success status
+// The send email response with the id and status of the email
 //
 // swagger:response sendEmailResponse
 type SendEmailResponse struct {
+	// This endpoint is deprecated since this time, as @ followed by unix seconds (RFC 9745)
+	Deprecation string `json:"Deprecation"`
+	// This endpoint will be removed at this time, as an http date (RFC 8594), absent until it is decided
+	Sunset string `json:"Sunset"`
+	// Points to the successor, POST /api/rest/v2/emails, with rel="successor-version"
+	Link string `json:"Link"`
+	// in:body
+	Body EmailResultDto
+}
+
+// Parameters for cancelling scheduled Emails
+//
+// swagger:parameters cancelEmailParams
+type CancelEmailParams struct {
+	// The id of the scheduled email
+	//
+	// in:path
+	// required: true
+	Id string `json:"id"`
+}
+
+// The cancel email response, which has no body
+//
+// swagger:response cancelEmailResponse
+type CancelEmailResponse struct {
 }
 
 // --- routes ---
 
 type EmailApi interface {
 	// swagger:route POST /api/rest/v1/sendmail email-tag sendEmailParams
-	// This will send an email.
+	// This will send an email, or schedule it if send_at is set. Deprecated, use POST /api/rest/v2/emails.
+	//
+	// deprecated: true
 	//
 	// responses:
 	//   200: sendEmailResponse
 	//   400: errorResponse
 	//   401: errorResponse
 	//   403: errorResponse
+	//   413: errorResponse
 	//   500: errorResponse
 	SendEmail(*gin.Context)
+
+	// swagger:route DELETE /api/rest/v1/scheduled/{id} email-tag cancelEmailParams
+	// This will cancel a scheduled email that has not been sent yet. Deprecated, use DELETE /api/rest/v2/emails/{id}.
+	//
+	// deprecated: true
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   204: cancelEmailResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   409: errorResponse
+	//   500: errorResponse
+	CancelEmail(*gin.Context)
 }
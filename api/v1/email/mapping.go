@@ -0,0 +1,24 @@
+package email
+
+import (
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"time"
+)
+
+// MapToEmail copies the dto into e. It is shared by the REST endpoint and the kafka command consumer.
+//
+// Only send_at can fail to map.
+func (dto *EmailDto) MapToEmail(e *entity.Email) error {
+	e.Subject = dto.Subject
+	e.Body = dto.Body
+	e.ToAddress = dto.ToAddress
+	if dto.SendAt != "" {
+		sendAt, err := time.Parse(time.RFC3339, dto.SendAt)
+		if err != nil {
+			return fmt.Errorf("send_at is not a valid RFC 3339 timestamp: %v", err)
+		}
+		e.SendAt = sendAt
+	}
+	return nil
+}
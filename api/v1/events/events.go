@@ -0,0 +1,78 @@
+package events
+
+import "encoding/json"
+
+// All events are CloudEvents 1.0, see https://github.com/cloudevents/spec.
+//
+// In structured mode, the complete CloudEventDto is sent with content type application/cloudevents+json.
+// In binary mode, the attributes are sent as headers (ce-id, ce-source, ... for http, ce_id, ce_source, ...
+// for kafka) and the body is just the data, with content type application/json.
+//
+// The type attribute determines the data model, for example com.example.mailer.email.sent.v1 carries
+// an EmailSentDataDto. JSON schemas for all data models are served under /schemas.
+
+// --- models ---
+
+// Model for CloudEventDto, the structured mode envelope.
+//
+// swagger:model cloudEventDto
+type CloudEventDto struct {
+	// Always 1.0
+	SpecVersion     string          `json:"specversion"`
+	// Unique id of this event, use it to detect duplicate deliveries
+	Id              string          `json:"id"`
+	// The service name of the mailer instance that emitted the event
+	Source          string          `json:"source"`
+	// The versioned event type, such as com.example.mailer.email.sent.v1
+	Type            string          `json:"type"`
+	// The id of the email this event is about
+	Subject         string          `json:"subject"`
+	// The RFC 3339 timestamp at which the event occurred
+	Time            string          `json:"time"`
+	// Always application/json
+	DataContentType string          `json:"datacontenttype"`
+	// Extension attribute with the id of the request that caused the event, missing for scheduled emails
+	RequestId       string          `json:"requestid,omitempty"`
+	// The event data, see the type attribute for the model
+	Data            json.RawMessage `json:"data"`
+}
+
+// Model for EmailAcceptedDataDto, the data of email.accepted.v1 events.
+//
+// swagger:model emailAcceptedDataDto
+type EmailAcceptedDataDto struct {
+	// The id of the email
+	EmailId string `json:"email_id"`
+	// The RFC 3339 timestamp the email is scheduled for, missing if it is sent right away
+	SendAt  string `json:"send_at,omitempty"`
+}
+
+// Model for EmailSentDataDto, the data of email.sent.v1 events.
+//
+// swagger:model emailSentDataDto
+type EmailSentDataDto struct {
+	// The id of the email
+	EmailId string `json:"email_id"`
+	// The RFC 3339 timestamp at which the email was handed to the mail server
+	SentAt  string `json:"sent_at"`
+}
+
+// Model for EmailFailedDataDto, the data of email.failed.v1 events.
+//
+// swagger:model emailFailedDataDto
+type EmailFailedDataDto struct {
+	// The id of the email
+	EmailId string `json:"email_id"`
+	// Why sending failed, if known
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Model for EmailBouncedDataDto, the data of email.bounced.v1 events.
+//
+// swagger:model emailBouncedDataDto
+type EmailBouncedDataDto struct {
+	// The id of the email
+	EmailId string `json:"email_id"`
+	// The status code and diagnostic from the delivery status notification
+	Reason  string `json:"reason,omitempty"`
+}
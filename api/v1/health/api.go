@@ -0,0 +1,58 @@
+package health
+
+import "github.com/gin-gonic/gin"
+
+// --- models ---
+
+// Model for HealthReportDto.
+//
+// swagger:model healthReportDto
+type HealthReportDto struct {
+	// UP if all components are up, DOWN otherwise
+	Status     string               `json:"status"`
+	// The result for each component, missing for the liveness probe
+	Components []ComponentHealthDto `json:"components,omitempty"`
+}
+
+// Model for ComponentHealthDto.
+//
+// swagger:model componentHealthDto
+type ComponentHealthDto struct {
+	// The component name, such as configuration, storage, mail or broker
+	Name       string `json:"name"`
+	// UP or DOWN
+	Status     string `json:"status"`
+	// Why the component is down
+	Error      string `json:"error,omitempty"`
+	// How long the check took, in milliseconds
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// --- parameters and responses --- needed to use models
+
+// The health report
+//
+// swagger:response healthReportResponse
+type HealthReportResponse struct {
+	// in:body
+	Body HealthReportDto
+}
+
+// --- routes ---
+
+type HealthApi interface {
+	// swagger:route GET /health/live health-tag liveParams
+	// Liveness probe, succeeds as long as the process can serve requests.
+	//
+	// responses:
+	//   200: healthReportResponse
+	Live(*gin.Context)
+
+	// swagger:route GET /health/ready health-tag readyParams
+	// Readiness probe, checks all components the service depends on.
+	//
+	// responses:
+	//   200: healthReportResponse
+	//   503: healthReportResponse
+	Ready(*gin.Context)
+}
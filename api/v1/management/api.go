@@ -0,0 +1,172 @@
+package management
+
+import "github.com/gin-gonic/gin"
+
+// --- models ---
+
+// Model for InfoDto.
+//
+// swagger:model infoDto
+type InfoDto struct {
+	// The service name from the configuration
+	ServiceName string   `json:"service_name"`
+	// The version, injected at build time
+	Version     string   `json:"version"`
+	// The git commit the binary was built from, injected at build time
+	GitCommit   string   `json:"git_commit"`
+	// The build timestamp, injected at build time
+	BuildTime   string   `json:"build_time"`
+	// The go version the binary was built with
+	GoVersion   string   `json:"go_version"`
+	// The active configuration profiles
+	Profiles    []string `json:"profiles"`
+}
+
+// Model for ConfigDto.
+//
+// swagger:model configDto
+type ConfigDto struct {
+	// All configuration items, in the order they are defined
+	Items []ConfigItemDto `json:"items"`
+}
+
+// Model for ConfigItemDto.
+//
+// swagger:model configItemDto
+type ConfigItemDto struct {
+	// The configuration key, such as server.port
+	Key         string      `json:"key"`
+	// The effective value after merging all configuration sources, ***** for secrets
+	Value       interface{} `json:"value"`
+	// What the configuration item is for
+	Description string      `json:"description"`
+}
+
+// Model for LoggersDto.
+//
+// swagger:model loggersDto
+type LoggersDto struct {
+	// The valid log levels
+	Levels  []string    `json:"levels"`
+	// All loggers whose level can be changed
+	Loggers []LoggerDto `json:"loggers"`
+}
+
+// Model for LoggerDto.
+//
+// swagger:model loggerDto
+type LoggerDto struct {
+	// The logger name, root for the global level, or a package with its own level from logging.packages
+	Name  string `json:"name"`
+	// The current log level, one of the levels
+	Level string `json:"level"`
+}
+
+// --- parameters and responses --- needed to use models
+
+// The info response
+//
+// swagger:response infoResponse
+type InfoResponse struct {
+	// in:body
+	Body InfoDto
+}
+
+// The config response
+//
+// swagger:response configResponse
+type ConfigResponse struct {
+	// in:body
+	Body ConfigDto
+}
+
+// The loggers response
+//
+// swagger:response loggersResponse
+type LoggersResponse struct {
+	// in:body
+	Body LoggersDto
+}
+
+// Parameters for changing a log level
+//
+// swagger:parameters updateLoggerParams
+type UpdateLoggerParams struct {
+	// The logger name
+	//
+	// in:path
+	// required: true
+	Name string `json:"name"`
+
+	// Only the level field is used
+	//
+	// in:body
+	Body LoggerDto
+}
+
+// The logger response
+//
+// swagger:response loggerResponse
+type LoggerResponse struct {
+	// in:body
+	Body LoggerDto
+}
+
+// --- routes ---
+
+type ManagementApi interface {
+	// swagger:route GET /management/info management-tag infoParams
+	// Show version and build information. Admin only.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: infoResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	Info(*gin.Context)
+
+	// swagger:route GET /management/config management-tag configParams
+	// Show the effective configuration, with secrets redacted. Admin only.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: configResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	Config(*gin.Context)
+
+	// swagger:route GET /management/loggers management-tag loggersParams
+	// Show the current log levels. Admin only.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: loggersResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	Loggers(*gin.Context)
+
+	// swagger:route PUT /management/loggers/{name} management-tag updateLoggerParams
+	// Change a log level at runtime, until the next restart. Admin only.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: loggerResponse
+	//   400: errorResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   413: errorResponse
+	UpdateLogger(*gin.Context)
+}
@@ -0,0 +1,59 @@
+package subject
+
+import "github.com/gin-gonic/gin"
+
+// --- models ---
+
+// Model for ErasureReportDto.
+//
+// swagger:model erasureReportDto
+type ErasureReportDto struct {
+	// The ids of the deleted emails, including scheduled ones
+	DeletedEmails []string `json:"deleted_emails"`
+	// Whether the address was removed from the suppression list, so it may be sent to again
+	SuppressionDeleted bool `json:"suppression_deleted"`
+	// The number of audit log entries for the address, which are kept because the audit log is append-only
+	AuditEntriesRetained int `json:"audit_entries_retained"`
+	// How the retained audit log entries record the address, hashed or clear
+	AuditRecipients string `json:"audit_recipients"`
+}
+
+// --- parameters and responses --- needed to use models
+
+// Parameters for erasing a subject
+//
+// swagger:parameters eraseSubjectParams
+type EraseSubjectParams struct {
+	// The recipient email address
+	//
+	// in:path
+	// required: true
+	Address string `json:"address"`
+}
+
+// What was erased
+//
+// swagger:response erasureReportResponse
+type ErasureReportResponse struct {
+	// in:body
+	Body ErasureReportDto
+}
+
+// --- routes ---
+
+type SubjectApi interface {
+	// swagger:route DELETE /api/rest/v1/subjects/{address} subject-tag eraseSubjectParams
+	// Erase every stored record for a recipient address (GDPR right to erasure). Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: erasureReportResponse
+	//   400: errorResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   500: errorResponse
+	EraseSubject(*gin.Context)
+}
@@ -0,0 +1,227 @@
+package webhook
+
+import "github.com/gin-gonic/gin"
+
+// --- models ---
+
+// Model for WebhookSubscriptionDto.
+//
+// swagger:model webhookSubscriptionDto
+type WebhookSubscriptionDto struct {
+	// The id of the subscription, assigned on creation
+	//
+	// read only: true
+	Id         string   `json:"id,omitempty"`
+	// The absolute http or https url to post events to
+	//
+	// required: true
+	Url        string   `json:"url"`
+	// Secret used to sign the callbacks with HMAC-SHA256, at least 16 characters, never returned.
+	// Can be omitted on update to keep the current secret.
+	Secret     string   `json:"secret,omitempty"`
+	// The event types to receive, one or more of accepted, sent, failed, bounced. Empty means all.
+	EventTypes []string `json:"event_types,omitempty"`
+	// The RFC 3339 timestamp at which the subscription was created
+	//
+	// read only: true
+	CreatedAt  string   `json:"created_at,omitempty"`
+}
+
+// Model for WebhookSubscriptionListDto.
+//
+// swagger:model webhookSubscriptionListDto
+type WebhookSubscriptionListDto struct {
+	// All subscriptions, oldest first
+	Subscriptions []WebhookSubscriptionDto `json:"subscriptions"`
+}
+
+// Model for WebhookDeliveryDto.
+//
+// swagger:model webhookDeliveryDto
+type WebhookDeliveryDto struct {
+	// The id of the event that was delivered
+	EventId    string `json:"event_id"`
+	// The type of the event that was delivered
+	EventType  string `json:"event_type"`
+	// Counts up from 1 for retries of the same event
+	Attempt    int    `json:"attempt"`
+	// The http status the receiver responded with, 0 if there was no response
+	StatusCode int    `json:"status_code"`
+	// Why the attempt failed
+	Error      string `json:"error,omitempty"`
+	// Whether the receiver accepted the event
+	Success    bool   `json:"success"`
+	// The RFC 3339 timestamp of the attempt
+	Timestamp  string `json:"timestamp"`
+}
+
+// Model for WebhookDeliveryListDto.
+//
+// swagger:model webhookDeliveryListDto
+type WebhookDeliveryListDto struct {
+	// The most recent delivery attempts, oldest first
+	Deliveries []WebhookDeliveryDto `json:"deliveries"`
+}
+
+// --- parameters and responses --- needed to use models
+
+// Parameters for creating webhook subscriptions
+//
+// swagger:parameters createWebhookParams
+type CreateWebhookParams struct {
+	// in:body
+	Body WebhookSubscriptionDto
+}
+
+// Parameters for updating webhook subscriptions
+//
+// swagger:parameters updateWebhookParams
+type UpdateWebhookParams struct {
+	// The id of the subscription
+	//
+	// in:path
+	// required: true
+	Id string `json:"id"`
+
+	// in:body
+	Body WebhookSubscriptionDto
+}
+
+// Parameters for addressing a single webhook subscription
+//
+// swagger:parameters getWebhookParams deleteWebhookParams listWebhookDeliveriesParams
+type WebhookIdParams struct {
+	// The id of the subscription
+	//
+	// in:path
+	// required: true
+	Id string `json:"id"`
+}
+
+// A webhook subscription
+//
+// swagger:response webhookResponse
+type WebhookResponse struct {
+	// in:body
+	Body WebhookSubscriptionDto
+}
+
+// All webhook subscriptions
+//
+// swagger:response webhookListResponse
+type WebhookListResponse struct {
+	// in:body
+	Body WebhookSubscriptionListDto
+}
+
+// The delivery log of a webhook subscription
+//
+// swagger:response webhookDeliveryListResponse
+type WebhookDeliveryListResponse struct {
+	// in:body
+	Body WebhookDeliveryListDto
+}
+
+// The delete webhook response, which has no body
+//
+// swagger:response deleteWebhookResponse
+type DeleteWebhookResponse struct {
+}
+
+// --- routes ---
+
+// Callbacks are POSTed as CloudEvents, see package events, with the headers X-Mailer-Timestamp (unix seconds) and
+// X-Mailer-Signature (sha256=hex encoded HMAC-SHA256 of timestamp + "." + body, keyed with the secret).
+type WebhookApi interface {
+	// swagger:route POST /api/rest/v1/webhooks webhook-tag createWebhookParams
+	// Subscribe to email events. Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   201: webhookResponse
+	//   400: errorResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   413: errorResponse
+	//   500: errorResponse
+	CreateWebhook(*gin.Context)
+
+	// swagger:route GET /api/rest/v1/webhooks webhook-tag listWebhooksParams
+	// List all webhook subscriptions. Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: webhookListResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   500: errorResponse
+	ListWebhooks(*gin.Context)
+
+	// swagger:route GET /api/rest/v1/webhooks/{id} webhook-tag getWebhookParams
+	// Get a webhook subscription. Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: webhookResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   500: errorResponse
+	GetWebhook(*gin.Context)
+
+	// swagger:route PUT /api/rest/v1/webhooks/{id} webhook-tag updateWebhookParams
+	// Change a webhook subscription. Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: webhookResponse
+	//   400: errorResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   413: errorResponse
+	//   500: errorResponse
+	UpdateWebhook(*gin.Context)
+
+	// swagger:route DELETE /api/rest/v1/webhooks/{id} webhook-tag deleteWebhookParams
+	// Unsubscribe, also deletes the delivery log. Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   204: deleteWebhookResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   500: errorResponse
+	DeleteWebhook(*gin.Context)
+
+	// swagger:route GET /api/rest/v1/webhooks/{id}/deliveries webhook-tag listWebhookDeliveriesParams
+	// Get the most recent delivery attempts of a webhook subscription. Requires the admin role.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: webhookDeliveryListResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   500: errorResponse
+	ListWebhookDeliveries(*gin.Context)
+}
@@ -0,0 +1,218 @@
+package email
+
+import "github.com/gin-gonic/gin"
+
+// --- models ---
+
+// Model for NewEmailDto.
+//
+// swagger:model newEmailDto
+type NewEmailDto struct {
+	// The email address to send to
+	//
+	// required: true
+	// swagger:strfmt email
+	ToAddress string `json:"to_address"`
+	// The email subject
+	//
+	// required: true
+	Subject string `json:"subject"`
+	// The email body
+	//
+	// required: true
+	Body string `json:"body"`
+	// Optional RFC 3339 timestamp, if set the email is held and sent at this time
+	//
+	// swagger:strfmt date-time
+	SendAt string `json:"send_at,omitempty"`
+}
+
+// Model for EmailResourceDto.
+//
+// swagger:model emailResourceDto
+type EmailResourceDto struct {
+	// The id assigned to the email
+	Id string `json:"id"`
+	// The email address the email is sent to
+	ToAddress string `json:"to_address"`
+	// The email subject, blank once the content has been purged
+	Subject string `json:"subject"`
+	// The email body, blank once the content has been purged
+	Body string `json:"body"`
+	// The status of the email: scheduled, sent, failed, cancelled, bounced or complained
+	Status string `json:"status"`
+	// Human readable detail for the status, such as the reason of a failure
+	StatusDetail string `json:"status_detail,omitempty"`
+	// How often sending was tried
+	Attempts int `json:"attempts"`
+	// When the email was submitted
+	//
+	// swagger:strfmt date-time
+	CreatedAt string `json:"created_at"`
+	// When the email is or was due, blank if it was to be sent right away
+	//
+	// swagger:strfmt date-time
+	SendAt string `json:"send_at,omitempty"`
+	// When the email was sent, blank if it has not been
+	//
+	// swagger:strfmt date-time
+	SentAt string `json:"sent_at,omitempty"`
+	// When subject and body were removed after the retention period, blank if they are still stored
+	//
+	// swagger:strfmt date-time
+	ContentPurgedAt string `json:"content_purged_at,omitempty"`
+}
+
+// Model for EmailPageDto.
+//
+// swagger:model emailPageDto
+type EmailPageDto struct {
+	// The emails on this page, oldest first
+	Items []EmailResourceDto `json:"items"`
+	// Pass this as the cursor to get the next page, blank on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// --- parameters and responses --- needed to use models
+
+// Parameters for creating Emails
+//
+// swagger:parameters createEmailParams
+type CreateEmailParams struct {
+	// in:body
+	// required: true
+	Body NewEmailDto
+}
+
+// The created email, which is sent right away or scheduled if send_at is set
+//
+// swagger:response createEmailResponse
+type CreateEmailResponse struct {
+	// The url of the created email
+	Location string `json:"Location"`
+	// in:body
+	Body EmailResourceDto
+}
+
+// Parameters for getting an Email
+//
+// swagger:parameters getEmailParams
+type GetEmailParams struct {
+	// The id of the email
+	//
+	// in:path
+	// required: true
+	Id string `json:"id"`
+}
+
+// The email with its current status
+//
+// swagger:response getEmailResponse
+type GetEmailResponse struct {
+	// in:body
+	Body EmailResourceDto
+}
+
+// Parameters for cancelling an Email
+//
+// swagger:parameters deleteEmailParams
+type DeleteEmailParams struct {
+	// The id of the email
+	//
+	// in:path
+	// required: true
+	Id string `json:"id"`
+}
+
+// The cancelled email response, which has no body
+//
+// swagger:response deleteEmailResponse
+type DeleteEmailResponse struct {
+}
+
+// Parameters for listing Emails
+//
+// swagger:parameters listEmailsParams
+type ListEmailsParams struct {
+	// The next_cursor of the previous page, omit to start with the oldest email
+	//
+	// in:query
+	Cursor string `json:"cursor"`
+	// The maximum number of emails on the page
+	//
+	// in:query
+	// minimum: 1
+	// maximum: 1000
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// A page of emails
+//
+// swagger:response listEmailsResponse
+type ListEmailsResponse struct {
+	// in:body
+	Body EmailPageDto
+}
+
+// --- routes ---
+
+type EmailApi interface {
+	// swagger:route POST /api/rest/v2/emails email-v2-tag createEmailParams
+	// This will send an email, or schedule it if send_at is set.
+	//
+	// responses:
+	//   201: createEmailResponse
+	//   400: errorResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   413: errorResponse
+	//   500: errorResponse
+	CreateEmail(*gin.Context)
+
+	// swagger:route GET /api/rest/v2/emails/{id} email-v2-tag getEmailParams
+	// This will return an email with its current status.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: getEmailResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   500: errorResponse
+	GetEmail(*gin.Context)
+
+	// swagger:route GET /api/rest/v2/emails email-v2-tag listEmailsParams
+	// This will list all emails, oldest first, one page at a time. Admin only.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   200: listEmailsResponse
+	//   400: errorResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   500: errorResponse
+	ListEmails(*gin.Context)
+
+	// swagger:route DELETE /api/rest/v2/emails/{id} email-v2-tag deleteEmailParams
+	// This will cancel an email that is still scheduled. It can still be read afterwards, with status cancelled.
+	//
+	// security:
+	//   bearer:
+	//   apikey:
+	//
+	// responses:
+	//   204: deleteEmailResponse
+	//   401: errorResponse
+	//   403: errorResponse
+	//   404: errorResponse
+	//   409: errorResponse
+	//   500: errorResponse
+	DeleteEmail(*gin.Context)
+}
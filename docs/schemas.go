@@ -0,0 +1,9 @@
+package docs
+
+import "embed"
+
+// Schemas holds the json schemas for the data of the cloudevents we emit, under schemas/. They are compiled
+// into the binary, so they are served no matter which directory the service is started from.
+//
+//go:embed schemas
+var Schemas embed.FS
@@ -0,0 +1,47 @@
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// the spec is generated by swagger.sh from the annotations in the api package, and compiled into the binary,
+// so it is served no matter which directory the service is started from
+
+//go:embed swagger.json
+var swaggerJson []byte
+
+// Swagger returns a fresh copy of the swagger 2.0 spec.
+func Swagger() (*openapi2.T, error) {
+	doc := &openapi2.T{}
+	if err := json.Unmarshal(swaggerJson, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// OpenApi3 returns a fresh copy of the spec, converted to openapi 3.0.
+//
+// Unlike swagger 2.0, openapi 3.0 can describe that errors come as problemDto if application/problem+json is
+// requested, so this is added to the error response.
+func OpenApi3() (*openapi3.T, error) {
+	doc, err := Swagger()
+	if err != nil {
+		return nil, err
+	}
+	doc3, err := openapi2conv.ToV3(doc)
+	if err != nil {
+		return nil, err
+	}
+	errorResponse, okResponse := doc3.Components.Responses["errorResponse"]
+	problem, okProblem := doc3.Components.Schemas["problemDto"]
+	if okResponse && okProblem {
+		errorResponse.Value.Content[apierrors.ProblemContentType] = openapi3.NewMediaType().
+			WithSchemaRef(openapi3.NewSchemaRef("#/components/schemas/problemDto", problem.Value))
+	}
+	return doc3, nil
+}
@@ -0,0 +1,82 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/require"
+)
+
+// If this fails, the annotations in the api package have changed, but docs/swagger.json has not been
+// regenerated. Run swagger.sh, see the README.
+//
+// Needs go-swagger on the path, skipped otherwise.
+func TestSpec_ShouldMatchApiAnnotations(t *testing.T) {
+	if _, err := exec.LookPath("swagger"); err != nil {
+		t.Skip("swagger not installed, cannot regenerate the spec")
+	}
+	generated := filepath.Join(t.TempDir(), "swagger.json")
+	cmd := exec.Command("bash", "swagger.sh", generated)
+	cmd.Dir = ".."
+	output, err := cmd.CombinedOutput()
+	require.Nil(t, err, string(output))
+
+	expected, err := ioutil.ReadFile(generated)
+	require.Nil(t, err)
+	actual, err := ioutil.ReadFile("swagger.json")
+	require.Nil(t, err)
+	require.JSONEq(t, string(expected), string(actual), "docs/swagger.json is out of date, run swagger.sh")
+}
+
+func TestOpenApi3_ShouldConvert(t *testing.T) {
+	// formats are open ended in openapi, but kin-openapi only accepts the ones it knows, and not uint64
+	openapi3.SchemaFormatValidationDisabled = true
+	defer func() { openapi3.SchemaFormatValidationDisabled = false }()
+
+	doc, err := OpenApi3()
+	require.Nil(t, err)
+	require.Nil(t, doc.Validate(context.Background()))
+	v2, err := Swagger()
+	require.Nil(t, err)
+	require.Equal(t, len(v2.Paths), len(doc.Paths))
+	require.Equal(t, len(v2.Definitions), len(doc.Components.Schemas))
+}
+
+// If this fails, an error code was added to or changed in apierrors/codes.go, but not in the api description
+// in swagger.go or in the enum of ErrorDto.Message.
+func TestSpec_ShouldDocumentAllErrorCodes(t *testing.T) {
+	doc, err := Swagger()
+	require.Nil(t, err)
+
+	codes := []interface{}{}
+	rows := []string{}
+	for _, code := range apierrors.ErrorCodes() {
+		codes = append(codes, code.Code)
+		rows = append(rows, fmt.Sprintf(" %s | %d | %s |", code.Code, code.Status, code.Title))
+	}
+	require.Equal(t, codes, doc.Definitions["errorDto"].Value.Properties["message"].Value.Enum)
+	// go-swagger drops the leading | of the table rows, the first two are the heading
+	documented := []string{}
+	for _, line := range strings.Split(doc.Info.Description, "\n") {
+		if strings.HasSuffix(line, " |") {
+			documented = append(documented, line)
+		}
+	}
+	require.Equal(t, rows, documented[2:])
+}
+
+func TestOpenApi3_ShouldDescribeProblems(t *testing.T) {
+	doc, err := OpenApi3()
+	require.Nil(t, err)
+
+	errorResponse := doc.Components.Responses["errorResponse"].Value
+	require.Equal(t, "#/components/schemas/errorDto", errorResponse.Content.Get("application/json").Schema.Ref)
+	require.Equal(t, "#/components/schemas/problemDto", errorResponse.Content.Get(apierrors.ProblemContentType).Schema.Ref)
+}
@@ -2,6 +2,40 @@
 //
 // Documentation of our mailer-service API.
 //
+// Errors are reported as errorDto, or as an RFC 7807 problemDto if the request accepts application/problem+json
+// but not application/json. These are all error codes:
+//
+// | code | status | title |
+// | ---- | ------ | ----- |
+// | auth.unauthorized.error | 401 | Authentication required |
+// | auth.forbidden.error | 403 | Not allowed |
+// | request.toolarge.error | 413 | Request body too large |
+// | request.validation.error | 400 | Request does not match the api spec |
+// | response.validation.error | 500 | Response does not match the api spec |
+// | swagger.spec.error | 500 | Api spec unavailable |
+// | email.parse.error | 400 | Email could not be parsed |
+// | email.validation.error | 400 | Email is invalid |
+// | email.send.error | 500 | Email could not be sent |
+// | email.notfound.error | 404 | Email not found |
+// | email.notpending.error | 409 | Email is no longer pending |
+// | email.cancel.error | 500 | Email could not be cancelled |
+// | email.query.error | 400 | Invalid email query |
+// | email.read.error | 500 | Email could not be read |
+// | audit.query.error | 400 | Invalid audit query |
+// | audit.read.error | 500 | Audit log could not be read |
+// | bounce.parse.error | 400 | Bounce could not be parsed |
+// | bounce.notcorrelated.error | 404 | Bounce does not match a sent email |
+// | bounce.process.error | 500 | Bounce could not be processed |
+// | subject.address.invalid | 400 | Invalid email address |
+// | subject.erase.error | 500 | Subject could not be erased |
+// | webhook.parse.error | 400 | Webhook could not be parsed |
+// | webhook.validation.error | 400 | Webhook is invalid |
+// | webhook.notfound.error | 404 | Webhook not found |
+// | webhook.error | 500 | Webhook could not be stored |
+// | logger.parse.error | 400 | Logger could not be parsed |
+// | logger.notfound.error | 404 | Logger not found |
+// | logger.validation.error | 400 | Invalid log level |
+//
 //     Schemes: http
 //     BasePath: /
 //     Version: 1.0.0
@@ -13,12 +47,15 @@
 //     Produces:
 //     - application/json
 //
-//     Security:
-//     - basic
-//
 //    SecurityDefinitions:
-//    basic:
-//      type: basic
+//    bearer:
+//      type: apiKey
+//      in: header
+//      name: Authorization
+//    apikey:
+//      type: apiKey
+//      in: header
+//      name: X-API-Key
 //
 // swagger:meta
 package docs
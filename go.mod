@@ -1,6 +1,6 @@
 module github.com/StephanHCB/go-mailer-service
 
-go 1.14
+go 1.16
 
 require (
 	github.com/StephanHCB/go-autumn-config v0.2.0
@@ -9,22 +9,28 @@ require (
 	github.com/armon/go-metrics v0.3.3
 	github.com/auth0/go-jwt-middleware v0.0.0-20200507191422-d30d7b9ece63
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
-	github.com/gin-contrib/logger v0.0.2
+	github.com/getkin/kin-openapi v0.80.0
 	github.com/gin-gonic/gin v1.5.0
 	github.com/go-http-utils/headers v0.0.0-20181008091004-fed159eddc2a
-	github.com/golang/protobuf v1.3.3 // indirect
+	github.com/google/uuid v1.1.2
 	github.com/kr/pretty v0.2.0 // indirect
 	github.com/pact-foundation/pact-go v1.4.3
 	github.com/pelletier/go-toml v1.6.0 // indirect
+	github.com/prometheus/client_golang v1.4.0
 	github.com/rs/zerolog v1.18.0
+	github.com/segmentio/kafka-go v0.3.7
 	github.com/spf13/afero v1.2.2 // indirect
 	github.com/spf13/cast v1.3.1 // indirect
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/spf13/viper v1.6.2
-	github.com/stretchr/testify v1.4.0
-	github.com/thanhhh/gin-requestid v0.0.0-20180527051759-221db8554b0d
-	golang.org/x/sys v0.0.0-20200124204421-9fbb57f87de9 // indirect
+	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.0.1
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.0.1
+	go.opentelemetry.io/otel/sdk v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
 	golang.org/x/text v0.3.2 // indirect
+	google.golang.org/grpc v1.41.0
+	google.golang.org/protobuf v1.27.1
 	gopkg.in/ini.v1 v1.52.0 // indirect
-	gopkg.in/yaml.v2 v2.2.8 // indirect
 )
@@ -0,0 +1,96 @@
+package grpcserver
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/api/grpc/emailpb"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+type EmailServer struct {
+	emailpb.UnimplementedEmailServiceServer
+
+	s                 emailsrv.EmailService
+	watchers          *statusWatchers
+	watchPollInterval time.Duration
+}
+
+func (s *EmailServer) SendEmail(ctx context.Context, request *emailpb.SendEmailRequest) (*emailpb.Email, error) {
+	mail := s.s.NewInstance(ctx)
+	if err := mapSendEmailRequestToEmail(request, mail); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.s.SendEmail(ctx, mail); err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+	return mapEmailToPb(mail), nil
+}
+
+func (s *EmailServer) GetEmail(ctx context.Context, request *emailpb.GetEmailRequest) (*emailpb.Email, error) {
+	if err := authentication.CheckUserIsLoggedIn(ctx); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	mail, err := s.s.GetEmail(ctx, identity.CallerOf(ctx), request.Id)
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+	return mapEmailToPb(mail), nil
+}
+
+// WatchEmailStatus sends the email, then rereads it whenever an event is published for it, and at least every
+// watchPollInterval, because not every change publishes an event. It only sends the email again if its status
+// or status detail changed. On shutdown the watch ends with Unavailable.
+func (s *EmailServer) WatchEmailStatus(request *emailpb.WatchEmailStatusRequest, stream emailpb.EmailService_WatchEmailStatusServer) error {
+	ctx := stream.Context()
+	if err := authentication.CheckUserIsLoggedIn(ctx); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	// before the first read, so no change can slip through in between
+	wakeUp := s.watchers.add(request.Id)
+	defer s.watchers.remove(request.Id, wakeUp)
+	ticker := time.NewTicker(s.watchPollInterval)
+	defer ticker.Stop()
+
+	var sent *entity.Email
+	for {
+		mail, err := s.s.GetEmail(ctx, identity.CallerOf(ctx), request.Id)
+		if err != nil {
+			return statusFromError(ctx, err)
+		}
+		if sent == nil || mail.Status != sent.Status || mail.StatusDetail != sent.StatusDetail {
+			if err := stream.Send(mapEmailToPb(mail)); err != nil {
+				return err
+			}
+			sent = mail
+		}
+		if isFinal(mail.Status) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return statusFromError(ctx, ctx.Err())
+		case <-s.watchers.stopping:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-wakeUp:
+		case <-ticker.C:
+		}
+	}
+}
+
+// a sent email may still bounce, so only these end a watch
+func isFinal(emailStatus entity.EmailStatus) bool {
+	switch emailStatus {
+	case entity.EmailStatusFailed, entity.EmailStatusCancelled, entity.EmailStatusBounced, entity.EmailStatusComplained:
+		return true
+	}
+	return false
+}
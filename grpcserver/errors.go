@@ -0,0 +1,32 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusFromError maps the errors of the email service to grpc status codes, the way the controllers map them
+// to http statuses. Anything unexpected becomes Internal, without passing on the details.
+func statusFromError(ctx context.Context, err error) error {
+	var validationErr *emailsrv.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Reason)
+	case errors.Is(err, emailsrv.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, emailsrv.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, emailsrv.ErrNotPending):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, emailsrv.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	log.Ctx(ctx).Error().Err(err).Msgf("error in email service: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,60 @@
+package grpcserver
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/api/grpc/emailpb"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/StephanHCB/go-mailer-service/internal/service/eventsrv"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"time"
+)
+
+type Server struct {
+	*grpc.Server
+	watchers *statusWatchers
+}
+
+// Create sets up the grpc api, which delegates to the same email service as the web server.
+//
+// Like the middleware of the web server, the interceptors accept calls with and without a token, api key or client
+// certificate, it is up to each method to decide whether it needs a caller. Use options for anything else, such as tls.
+func Create(emailService emailsrv.EmailService, options ...grpc.ServerOption) *Server {
+	return create(emailService, configuration.SecuritySecret(), configuration.SecurityApiKeys(), configuration.GrpcWatchPollInterval(), options...)
+}
+
+func create(emailService emailsrv.EmailService, secret string, keys []configuration.ApiKey, watchPollInterval time.Duration, options ...grpc.ServerOption) *Server {
+	options = append(options,
+		grpc.ChainUnaryInterceptor(unaryInterceptor(secret, keys)),
+		grpc.ChainStreamInterceptor(streamInterceptor(secret, keys)))
+	server := grpc.NewServer(options...)
+
+	watchers := newStatusWatchers()
+	eventsrv.Register(watchers)
+
+	emailpb.RegisterEmailServiceServer(server, &EmailServer{
+		s:                 emailService,
+		watchers:          watchers,
+		watchPollInterval: watchPollInterval,
+	})
+	return &Server{Server: server, watchers: watchers}
+}
+
+// Shutdown ends all status watches, so clients can reconnect elsewhere, then waits for the other in-flight calls
+// until ctx is done, like http.Server.Shutdown.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.watchers.stop()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Error().Msg("grpc server did not finish in-flight calls within the grace period")
+		s.Stop()
+	}
+}
@@ -0,0 +1,129 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// the grpc equivalents of the Authorization and X-API-Key headers, metadata keys are always lower case
+const (
+	authorizationMetadataKey = "authorization"
+	apiKeyMetadataKey        = "x-api-key"
+)
+
+// unaryInterceptor does for calls what the middleware does for web requests: it adds a request id and a logger
+// to the context, identifies the caller and logs the call once it has completed.
+func unaryInterceptor(secret string, keys []configuration.ApiKey) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = addRequestLoggerToContext(ctx)
+
+		ctx, err := authenticate(ctx, secret, keys)
+		var response interface{}
+		if err == nil {
+			response, err = handler(ctx, req)
+		}
+		logCall(ctx, info.FullMethod, start, err)
+		return response, err
+	}
+}
+
+// streamInterceptor is unaryInterceptor for streaming calls.
+func streamInterceptor(secret string, keys []configuration.ApiKey) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := addRequestLoggerToContext(stream.Context())
+
+		ctx, err := authenticate(ctx, secret, keys)
+		if err == nil {
+			err = handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+		}
+		logCall(ctx, info.FullMethod, start, err)
+		return err
+	}
+}
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// unlike the web server, there are no trusted callers yet, so every call gets a new request id
+func addRequestLoggerToContext(ctx context.Context) context.Context {
+	requestId := uuid.New().String()
+	ctx = tracing.WithRequestId(ctx, requestId)
+	sublogger := log.Logger.With().Str(logging.FieldRequestId, requestId).Logger()
+	return sublogger.WithContext(ctx)
+}
+
+// identifies the caller by token, api key or client certificate, like the web server does. Calls without any of them
+// stay anonymous, calls with an invalid token or api key are rejected
+func authenticate(ctx context.Context, secret string, keys []configuration.ApiKey) (context.Context, error) {
+	var tlsState *tls.ConnectionState
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			tlsState = &tlsInfo.State
+		}
+	}
+	authenticatedCtx, err := authentication.AddCallerInfoToContext(ctx, secret, keys,
+		firstMetadataValue(ctx, authorizationMetadataKey), firstMetadataValue(ctx, apiKeyMetadataKey), tlsState)
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return authenticatedCtx, nil
+}
+
+func firstMetadataValue(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// uses the ECS rpc.* fields, server errors are logged as errors and client errors as warnings
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := zerolog.InfoLevel
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		level = zerolog.ErrorLevel
+	default:
+		level = zerolog.WarnLevel
+	}
+
+	event := log.Ctx(ctx).WithLevel(level).
+		Str("event.kind", "event").
+		Str("event.category", "grpc").
+		Int64("event.duration", time.Since(start).Nanoseconds()).
+		Str("rpc.system", "grpc").
+		Str("rpc.method", method).
+		Int("rpc.grpc.status_code", int(code))
+	if userId := authentication.Subject(ctx); userId != "" {
+		event = event.Str("user.id", userId)
+	}
+	if err != nil {
+		event = event.Str("error.message", err.Error())
+	}
+	event.Msgf("%s %s", method, code)
+}
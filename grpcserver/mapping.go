@@ -0,0 +1,56 @@
+package grpcserver
+
+import (
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/api/grpc/emailpb"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+var statusToPb = map[entity.EmailStatus]emailpb.EmailStatus{
+	entity.EmailStatusScheduled:  emailpb.EmailStatus_EMAIL_STATUS_SCHEDULED,
+	entity.EmailStatusSent:       emailpb.EmailStatus_EMAIL_STATUS_SENT,
+	entity.EmailStatusFailed:     emailpb.EmailStatus_EMAIL_STATUS_FAILED,
+	entity.EmailStatusCancelled:  emailpb.EmailStatus_EMAIL_STATUS_CANCELLED,
+	entity.EmailStatusBounced:    emailpb.EmailStatus_EMAIL_STATUS_BOUNCED,
+	entity.EmailStatusComplained: emailpb.EmailStatus_EMAIL_STATUS_COMPLAINED,
+}
+
+// the email service validates the fields
+func mapSendEmailRequestToEmail(request *emailpb.SendEmailRequest, e *entity.Email) error {
+	if request.SendAt != nil {
+		if err := request.SendAt.CheckValid(); err != nil {
+			return errors.New("send_at is not a valid timestamp")
+		}
+		e.SendAt = request.SendAt.AsTime()
+	}
+	e.ToAddress = request.ToAddress
+	e.Subject = request.Subject
+	e.Body = request.Body
+	return nil
+}
+
+func mapEmailToPb(e *entity.Email) *emailpb.Email {
+	return &emailpb.Email{
+		Id:              e.ID,
+		ToAddress:       e.ToAddress,
+		Subject:         e.Subject,
+		Body:            e.Body,
+		Status:          statusToPb[e.Status],
+		StatusDetail:    e.StatusDetail,
+		Attempts:        int32(e.Attempts),
+		CreatedAt:       mapTimestamp(e.CreatedAt),
+		SendAt:          mapTimestamp(e.SendAt),
+		SentAt:          mapTimestamp(e.SentAt),
+		ContentPurgedAt: mapTimestamp(e.ContentPurgedAt),
+	}
+}
+
+// nil for the zero time, so the field is left unset
+func mapTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
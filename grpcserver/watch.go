@@ -0,0 +1,56 @@
+package grpcserver
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"sync"
+)
+
+// statusWatchers wakes up the status watches of an email whenever an event is published for it.
+type statusWatchers struct {
+	mu      sync.Mutex
+	watches map[string]map[chan struct{}]struct{}
+	// closed on shutdown
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+func newStatusWatchers() *statusWatchers {
+	return &statusWatchers{watches: make(map[string]map[chan struct{}]struct{}), stopping: make(chan struct{})}
+}
+
+func (w *statusWatchers) stop() {
+	w.stopOnce.Do(func() { close(w.stopping) })
+}
+
+// Publish never blocks, a watch that has not yet picked up the previous wake up rereads the email only once.
+func (w *statusWatchers) Publish(_ context.Context, event *entity.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for wakeUp := range w.watches[event.EmailID] {
+		select {
+		case wakeUp <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *statusWatchers) add(emailId string) chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wakeUp := make(chan struct{}, 1)
+	if w.watches[emailId] == nil {
+		w.watches[emailId] = make(map[chan struct{}]struct{})
+	}
+	w.watches[emailId][wakeUp] = struct{}{}
+	return wakeUp
+}
+
+func (w *statusWatchers) remove(emailId string, wakeUp chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.watches[emailId], wakeUp)
+	if len(w.watches[emailId]) == 0 {
+		delete(w.watches, emailId)
+	}
+}
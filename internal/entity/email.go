@@ -1,7 +1,44 @@
 package entity
 
+import (
+	"fmt"
+	"time"
+)
+
+type EmailStatus string
+
+const (
+	EmailStatusScheduled  EmailStatus = "scheduled"
+	EmailStatusSent       EmailStatus = "sent"
+	EmailStatusFailed     EmailStatus = "failed"
+	EmailStatusCancelled  EmailStatus = "cancelled"
+	EmailStatusBounced    EmailStatus = "bounced"
+	EmailStatusComplained EmailStatus = "complained"
+)
+
 type Email struct {
+	ID        string
 	ToAddress string
 	Subject   string
 	Body      string
+	// zero value means send immediately
+	SendAt time.Time
+	Status EmailStatus
+	// human readable detail for the status, such as the diagnostic from a bounce
+	StatusDetail string
+	CreatedAt    time.Time
+	SentAt       time.Time
+	// how often sending was tried
+	Attempts int
+	// id of the request that submitted the email, passed on in the X-Request-Id mail header
+	RequestID string
+	// subject of the caller that submitted the email, blank if it was submitted anonymously
+	SubmittedBy string
+	// when the subject and body were removed after the content retention period, zero while they are still stored
+	ContentPurgedAt time.Time
+}
+
+// String leaves out the subject and body, so emails that end up in log messages do not leak their content.
+func (e Email) String() string {
+	return fmt.Sprintf("Email{ID: %s, ToAddress: %s, Status: %s}", e.ID, e.ToAddress, e.Status)
 }
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+type EventType string
+
+const (
+	EventTypeAccepted EventType = "accepted"
+	EventTypeSent     EventType = "sent"
+	EventTypeFailed   EventType = "failed"
+	EventTypeBounced  EventType = "bounced"
+)
+
+var AllEventTypes = []EventType{EventTypeAccepted, EventTypeSent, EventTypeFailed, EventTypeBounced}
+
+// Event is something that happened to an email, which downstream systems may want to know about.
+type Event struct {
+	ID        string
+	Type      EventType
+	EmailID   string
+	Status    EmailStatus
+	Detail    string
+	SendAt    time.Time
+	SentAt    time.Time
+	Timestamp time.Time
+	// empty if the event did not originate from a request
+	RequestID string
+}
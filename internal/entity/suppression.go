@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// Suppression marks a recipient address we must no longer send to, e.g. because of a hard bounce.
+type Suppression struct {
+	// lower case
+	Address   string
+	Reason    string
+	EmailID   string
+	CreatedAt time.Time
+}
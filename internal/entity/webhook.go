@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+type WebhookSubscription struct {
+	ID  string
+	URL string
+	// used to sign the callbacks, never returned by the api
+	Secret string
+	// empty means all event types
+	EventTypes []EventType
+	CreatedAt  time.Time
+}
+
+func (s *WebhookSubscription) WantsEvent(eventType EventType) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records a single delivery attempt of an event to a subscription.
+type WebhookDelivery struct {
+	SubscriptionID string
+	EventID        string
+	EventType      EventType
+	Attempt        int
+	// zero if no response was received
+	StatusCode int
+	Error      string
+	Success    bool
+	Timestamp  time.Time
+}
@@ -0,0 +1,251 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/rs/zerolog/log"
+	"sync"
+	"time"
+)
+
+const (
+	RecipientsHashed = "hashed"
+	// configuration does not allow this, as clear text addresses would survive erasure
+	RecipientsClear = "clear"
+)
+
+// Log is an append-only, hash-chained audit log.
+type Log struct {
+	mu   sync.Mutex
+	sink Sink
+	// for the hmac of the chain and of hashed recipients
+	key            []byte
+	hashRecipients bool
+	lastSequence   uint64
+	lastHash       string
+}
+
+// Filter restricts Query results, blank fields match everything.
+type Filter struct {
+	EmailId string
+	Subject string
+	// clear text address, matched against hashed entries too
+	Recipient string
+	Outcome   Outcome
+	// return at most this many of the most recent matches, 0 for all
+	Limit int
+}
+
+// Report is the result of Verify.
+type Report struct {
+	Entries int
+	Valid   bool
+	// sequence number of the first entry that fails verification, 0 if valid
+	BrokenAt uint64
+	Reason   string
+}
+
+// Create continues the chain already stored in the sink, which must have been written with the same key.
+func Create(sink Sink, key []byte, hashRecipients bool) (*Log, error) {
+	l := &Log{sink: sink, key: key, hashRecipients: hashRecipients}
+	lines, err := sink.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 {
+		last := Entry{}
+		if err := json.Unmarshal(lines[len(lines)-1], &last); err != nil {
+			return nil, fmt.Errorf("last audit log entry is not valid json: %v", err)
+		}
+		l.lastSequence = last.Sequence
+		l.lastHash = last.Hash
+	}
+	return l, nil
+}
+
+// Append fills in caller, request id, timestamp and chain fields, then stores the entry.
+func (l *Log) Append(ctx context.Context, entry Entry) (*Entry, error) {
+	caller := identity.CallerOf(ctx)
+	entry.Subject = caller.Subject
+	entry.Roles = caller.Roles
+	entry.RequestId = tracing.RequestId(ctx)
+	entry.Timestamp = time.Now().UTC()
+	recipients := []string{}
+	for _, recipient := range entry.Recipients {
+		if l.hashRecipients {
+			recipient = hashRecipient(l.key, recipient)
+		}
+		recipients = append(recipients, recipient)
+	}
+	entry.Recipients = recipients
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Sequence = l.lastSequence + 1
+	entry.PrevHash = l.lastHash
+	hash, err := entry.computeHash(l.key)
+	if err != nil {
+		return nil, err
+	}
+	entry.Hash = hash
+	line, err := json.Marshal(&entry)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.sink.Append(line); err != nil {
+		return nil, err
+	}
+	l.lastSequence = entry.Sequence
+	l.lastHash = entry.Hash
+	return &entry, nil
+}
+
+// Query returns the matching entries, oldest first. Lines that cannot be parsed are skipped, Verify reports them.
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	lines, err := l.sink.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	hashedRecipient := ""
+	if filter.Recipient != "" {
+		hashedRecipient = hashRecipient(l.key, filter.Recipient)
+	}
+	result := []Entry{}
+	for _, line := range lines {
+		entry := Entry{}
+		if json.Unmarshal(line, &entry) != nil {
+			continue
+		}
+		if filter.matches(&entry, l.key, hashedRecipient) {
+			result = append(result, entry)
+		}
+	}
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[len(result)-filter.Limit:]
+	}
+	return result, nil
+}
+
+func (f Filter) matches(entry *Entry, key []byte, hashedRecipient string) bool {
+	if f.EmailId != "" && entry.EmailId != f.EmailId {
+		return false
+	}
+	if f.Subject != "" && entry.Subject != f.Subject {
+		return false
+	}
+	if f.Outcome != "" && entry.Outcome != f.Outcome {
+		return false
+	}
+	if f.Recipient != "" {
+		for _, recipient := range entry.Recipients {
+			if recipient == hashedRecipient || hashRecipient(key, recipient) == hashedRecipient {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Verify walks the whole chain and reports the first entry that was changed, removed, inserted or reordered.
+func (l *Log) Verify() (*Report, error) {
+	lines, err := l.sink.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Entries: len(lines), Valid: true}
+	prevHash := ""
+	for i, line := range lines {
+		expected := uint64(i + 1)
+		entry := Entry{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return report.broken(expected, "entry is not valid json"), nil
+		}
+		if entry.Sequence != expected {
+			return report.broken(expected, fmt.Sprintf("found sequence number %d, entries were removed or reordered", entry.Sequence)), nil
+		}
+		if entry.PrevHash != prevHash {
+			return report.broken(expected, "previous hash does not match, the previous entry was changed or removed"), nil
+		}
+		hash, err := entry.computeHash(l.key)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Hash != hash {
+			return report.broken(expected, "hash does not match, the entry was changed or the key is wrong"), nil
+		}
+		prevHash = entry.Hash
+	}
+	return report, nil
+}
+
+func (r *Report) broken(sequence uint64, reason string) *Report {
+	r.Valid = false
+	r.BrokenAt = sequence
+	r.Reason = reason
+	return r
+}
+
+// --- active audit log ---
+
+var (
+	ActiveLog *Log
+)
+
+// Setup opens the audit log configured in audit.file, or an in-memory log if it is blank.
+//
+// A broken chain is logged, but does not prevent startup, because the evidence should be kept as it is.
+func Setup() {
+	var sink Sink
+	if path := configuration.AuditFile(); path != "" {
+		log.Info().Msgf("Opening audit log %s...", path)
+		fileSink, err := CreateFileSink(path)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("failed to open audit log %s", path)
+		}
+		sink = fileSink
+	} else {
+		log.Warn().Msg("no audit log file configured, audit entries are only kept in memory")
+		sink = CreateInMemorySink()
+	}
+	key := []byte(configuration.AuditKey())
+	if len(key) == 0 {
+		// configuration only allows this without a file, so there is no chain to continue after a restart
+		log.Warn().Msg("no audit key configured, using a random one")
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatal().Err(err).Msg("failed to generate audit key")
+		}
+	}
+	l, err := Create(sink, key, configuration.AuditRecipients() == RecipientsHashed)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to read audit log")
+	}
+	report, err := l.Verify()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to verify audit log")
+	}
+	if !report.Valid {
+		log.Error().Msgf("audit log verification failed at entry %d: %s", report.BrokenAt, report.Reason)
+	}
+	ActiveLog = l
+}
+
+func Close() {
+	if ActiveLog != nil {
+		if err := ActiveLog.sink.Close(); err != nil {
+			log.Error().Err(err).Msgf("failed to close audit log: %v", err)
+		}
+		ActiveLog = nil
+	}
+}
+
+func Get() *Log {
+	return ActiveLog
+}
@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var tstKey = []byte("0123456789abcdef0123456789abcdef")
+
+func tstContext() context.Context {
+	ctx := identity.WithCaller(context.Background(), identity.Caller{Subject: "someone", Roles: []string{"admin"}})
+	return tracing.WithRequestId(ctx, "req-1")
+}
+
+func tstAppend(t *testing.T, l *Log, emailId string, outcome Outcome) {
+	_, err := l.Append(tstContext(), Entry{EmailId: emailId, Recipients: []string{"Someone@Example.com"}, Outcome: outcome})
+	require.Nil(t, err)
+}
+
+func TestAppend_ShouldChainEntries(t *testing.T) {
+	l, err := Create(CreateInMemorySink(), tstKey, true)
+	require.Nil(t, err)
+
+	tstAppend(t, l, "1", OutcomeAccepted)
+	tstAppend(t, l, "1", OutcomeSent)
+
+	entries, err := l.Query(Filter{})
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, uint64(1), entries[0].Sequence)
+	require.Equal(t, "", entries[0].PrevHash)
+	require.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	require.Equal(t, "someone", entries[1].Subject)
+	require.Equal(t, []string{"admin"}, entries[1].Roles)
+	require.Equal(t, "req-1", entries[1].RequestId)
+	require.Equal(t, []string{hashRecipient(tstKey, "someone@example.com")}, entries[1].Recipients)
+
+	report, err := l.Verify()
+	require.Nil(t, err)
+	require.Equal(t, &Report{Entries: 2, Valid: true}, report)
+}
+
+func TestQuery_ShouldFilterByRecipientAndLimit(t *testing.T) {
+	l, err := Create(CreateInMemorySink(), tstKey, false)
+	require.Nil(t, err)
+	tstAppend(t, l, "1", OutcomeAccepted)
+	tstAppend(t, l, "1", OutcomeSent)
+	_, err = l.Append(tstContext(), Entry{EmailId: "2", Recipients: []string{"other@example.com"}, Outcome: OutcomeRejected})
+	require.Nil(t, err)
+
+	entries, err := l.Query(Filter{Recipient: "someone@example.com", Limit: 1})
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, OutcomeSent, entries[0].Outcome)
+	require.Equal(t, []string{"Someone@Example.com"}, entries[0].Recipients)
+
+	entries, err = l.Query(Filter{Outcome: OutcomeRejected})
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "2", entries[0].EmailId)
+}
+
+func TestVerify_ShouldDetectChangedEntry(t *testing.T) {
+	sink := CreateInMemorySink()
+	l, err := Create(sink, tstKey, true)
+	require.Nil(t, err)
+	tstAppend(t, l, "1", OutcomeAccepted)
+	tstAppend(t, l, "1", OutcomeFailed)
+	tstAppend(t, l, "2", OutcomeAccepted)
+
+	sink.lines[1] = []byte(strings.Replace(string(sink.lines[1]), `"outcome":"failed"`, `"outcome":"sent"`, 1))
+
+	report, err := l.Verify()
+	require.Nil(t, err)
+	require.False(t, report.Valid)
+	require.Equal(t, uint64(2), report.BrokenAt)
+	require.Contains(t, report.Reason, "entry was changed")
+}
+
+func TestVerify_ShouldDetectRemovedEntry(t *testing.T) {
+	sink := CreateInMemorySink()
+	l, err := Create(sink, tstKey, true)
+	require.Nil(t, err)
+	tstAppend(t, l, "1", OutcomeAccepted)
+	tstAppend(t, l, "1", OutcomeFailed)
+	tstAppend(t, l, "2", OutcomeAccepted)
+
+	sink.lines = append(sink.lines[:1], sink.lines[2:]...)
+
+	report, err := l.Verify()
+	require.Nil(t, err)
+	require.False(t, report.Valid)
+	require.Equal(t, uint64(2), report.BrokenAt)
+	require.Contains(t, report.Reason, "removed or reordered")
+}
+
+func TestVerify_ShouldDetectRehashedEntry(t *testing.T) {
+	sink := CreateInMemorySink()
+	l, err := Create(sink, tstKey, true)
+	require.Nil(t, err)
+	tstAppend(t, l, "1", OutcomeAccepted)
+	tstAppend(t, l, "1", OutcomeFailed)
+
+	// an attacker who recomputes the hash of the changed entry still breaks the link from the next one
+	entries, err := l.Query(Filter{})
+	require.Nil(t, err)
+	forged := entries[0]
+	forged.EmailId = "forged"
+	forged.Hash, err = forged.computeHash(tstKey)
+	require.Nil(t, err)
+	sink.lines[0], err = json.Marshal(&forged)
+	require.Nil(t, err)
+
+	report, err := l.Verify()
+	require.Nil(t, err)
+	require.False(t, report.Valid)
+	require.Equal(t, uint64(2), report.BrokenAt)
+	require.Contains(t, report.Reason, "previous hash")
+}
+
+func TestVerify_ShouldDetectChainRewrittenWithoutKey(t *testing.T) {
+	sink := CreateInMemorySink()
+	l, err := Create(sink, tstKey, true)
+	require.Nil(t, err)
+	tstAppend(t, l, "1", OutcomeAccepted)
+	tstAppend(t, l, "1", OutcomeFailed)
+
+	// an attacker who rewrites the whole chain from the changed entry on does not have the key
+	entries, err := l.Query(Filter{})
+	require.Nil(t, err)
+	prevHash := ""
+	for i := range entries {
+		if i == 1 {
+			entries[i].Outcome = OutcomeSent
+		}
+		entries[i].PrevHash = prevHash
+		entries[i].Hash, err = entries[i].computeHash([]byte("guessed"))
+		require.Nil(t, err)
+		sink.lines[i], err = json.Marshal(&entries[i])
+		require.Nil(t, err)
+		prevHash = entries[i].Hash
+	}
+
+	report, err := l.Verify()
+	require.Nil(t, err)
+	require.False(t, report.Valid)
+	require.Equal(t, uint64(1), report.BrokenAt)
+	require.Contains(t, report.Reason, "key is wrong")
+}
+
+func TestQuery_HashedRecipientsShouldDependOnKey(t *testing.T) {
+	l, err := Create(CreateInMemorySink(), tstKey, true)
+	require.Nil(t, err)
+	tstAppend(t, l, "1", OutcomeAccepted)
+
+	entries, err := l.Query(Filter{Recipient: "someone@example.com"})
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.NotEqual(t, hashRecipient([]byte("guessed"), "someone@example.com"), entries[0].Recipients[0])
+	require.True(t, strings.HasPrefix(entries[0].Recipients[0], "hmac-sha256:"))
+}
+
+func TestFileSink_ShouldContinueChainAfterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := CreateFileSink(path)
+	require.Nil(t, err)
+	l, err := Create(sink, tstKey, true)
+	require.Nil(t, err)
+	tstAppend(t, l, "1", OutcomeAccepted)
+	require.Nil(t, sink.Close())
+
+	sink, err = CreateFileSink(path)
+	require.Nil(t, err)
+	defer sink.Close()
+	l, err = Create(sink, tstKey, true)
+	require.Nil(t, err)
+	tstAppend(t, l, "1", OutcomeSent)
+
+	report, err := l.Verify()
+	require.Nil(t, err)
+	require.Equal(t, &Report{Entries: 2, Valid: true}, report)
+}
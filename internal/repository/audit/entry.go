@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type Outcome string
+
+const (
+	// the email passed validation and was stored
+	OutcomeAccepted Outcome = "accepted"
+	// the email failed business validation
+	OutcomeRejected Outcome = "rejected"
+	OutcomeSent     Outcome = "sent"
+	OutcomeFailed   Outcome = "failed"
+)
+
+// Entry is one line of the audit log.
+//
+// Hash is the hex encoded hmac-sha256 of the json rendering of the entry with an empty Hash, keyed with audit.key,
+// and PrevHash is the Hash of the previous entry, so changing or removing an entry breaks the chain from there on.
+// Without the key, nobody can recompute the chain after changing an entry.
+type Entry struct {
+	Sequence  uint64    `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	Subject   string    `json:"subject,omitempty"`
+	Roles     []string  `json:"roles,omitempty"`
+	RequestId string    `json:"request_id,omitempty"`
+	EmailId   string    `json:"email_id,omitempty"`
+	// clear text or hmac-sha256:<hex>, depending on audit.recipients
+	Recipients []string `json:"recipients"`
+	// emails are not rendered from templates yet, so this is always blank for now
+	TemplateId string  `json:"template_id,omitempty"`
+	Outcome    Outcome `json:"outcome"`
+	Detail     string  `json:"detail,omitempty"`
+	PrevHash   string  `json:"prev_hash"`
+	Hash       string  `json:"hash"`
+}
+
+func (e Entry) computeHash(key []byte) (string, error) {
+	e.Hash = ""
+	rendered, err := json.Marshal(&e)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sign(key, rendered)), nil
+}
+
+// hashRecipient is how recipients are recorded if audit.recipients is hashed.
+//
+// Addresses are compared case insensitively, so the same hash can be used to look up all entries for an address.
+// The hash is keyed, so it cannot be reversed by hashing a list of known addresses.
+func hashRecipient(key []byte, address string) string {
+	return "hmac-sha256:" + hex.EncodeToString(sign(key, []byte(strings.ToLower(strings.TrimSpace(address)))))
+}
+
+func sign(key []byte, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"sync"
+)
+
+// Sink stores the rendered audit log entries, one per line. It must never change or remove lines.
+type Sink interface {
+	Append(line []byte) error
+	// ReadAll returns all lines, oldest first
+	ReadAll() ([][]byte, error)
+	Close() error
+}
+
+// FileSink appends to a JSONL file, which is synced after every entry.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func CreateFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{path: path, file: file}, nil
+}
+
+func (s *FileSink) Append(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) ReadAll() ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := [][]byte{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			lines = append(lines, append([]byte{}, line...))
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// InMemorySink keeps the entries in memory, they do not survive a restart.
+type InMemorySink struct {
+	mu    sync.Mutex
+	lines [][]byte
+}
+
+func CreateInMemorySink() *InMemorySink {
+	return &InMemorySink{lines: [][]byte{}}
+}
+
+func (s *InMemorySink) Append(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, append([]byte{}, line...))
+	return nil
+}
+
+func (s *InMemorySink) ReadAll() ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte{}, s.lines...), nil
+}
+
+func (s *InMemorySink) Close() error {
+	return nil
+}
@@ -0,0 +1,18 @@
+package buildinfo
+
+import "runtime"
+
+// set at build time, e.g.
+//
+//   go build -ldflags "-X github.com/StephanHCB/go-mailer-service/internal/repository/buildinfo.Version=1.2.0 \
+//     -X github.com/StephanHCB/go-mailer-service/internal/repository/buildinfo.GitCommit=$(git rev-parse HEAD) \
+//     -X github.com/StephanHCB/go-mailer-service/internal/repository/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" main.go
+var (
+	Version   = "development"
+	GitCommit = "unknown"
+	BuildTime = "unknown"
+)
+
+func GoVersion() string {
+	return runtime.Version()
+}
@@ -0,0 +1,117 @@
+// Package certtest creates throwaway certificates for tests. Only import it from _test.go files.
+package certtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"time"
+)
+
+// PKI is a throwaway CA that issues a server certificate for localhost and client certificates.
+type PKI struct {
+	CaFile   string
+	CertFile string
+	KeyFile  string
+	// trusts the CA, for clients
+	CaPool *x509.CertPool
+
+	ca     *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	serial int64
+}
+
+// CreatePKI writes ca.crt, tls.crt and tls.key to the directory.
+func CreatePKI(directory string) (*PKI, error) {
+	p := &PKI{
+		CaFile:   filepath.Join(directory, "ca.crt"),
+		CertFile: filepath.Join(directory, "tls.crt"),
+		KeyFile:  filepath.Join(directory, "tls.key"),
+		CaPool:   x509.NewCertPool(),
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := p.template(pkix.Name{CommonName: "test ca"})
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	p.ca, err = x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	p.caKey = key
+	p.CaPool.AddCert(p.ca)
+	if err := ioutil.WriteFile(p.CaFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		return nil, err
+	}
+	return p, p.RenewServerCertificate()
+}
+
+// RenewServerCertificate replaces tls.crt and tls.key with a new certificate, which has a new serial number.
+func (p *PKI) RenewServerCertificate() error {
+	template := p.template(pkix.Name{CommonName: "localhost"})
+	template.DNSNames = []string{"localhost"}
+	template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	certPem, keyPem, err := p.issue(template)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(p.CertFile, certPem, 0600); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.KeyFile, keyPem, 0600)
+}
+
+// ClientCertificate issues a client certificate with the given subject.
+func (p *PKI) ClientCertificate(subject pkix.Name) (tls.Certificate, error) {
+	template := p.template(subject)
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	certPem, keyPem, err := p.issue(template)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPem, keyPem)
+}
+
+func (p *PKI) template(subject pkix.Name) *x509.Certificate {
+	p.serial++
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(p.serial),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+}
+
+func (p *PKI) issue(template *x509.Certificate) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, p.ca, &key.PublicKey, p.caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), nil
+}
@@ -0,0 +1,172 @@
+package certificates
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// Reloader serves the server certificate and the client CA pool from disk, and picks up changed files
+// without a restart, e.g. when cert-manager renews a certificate mounted from a kubernetes secret.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	// blank disables client certificates
+	clientCaFile string
+
+	mu          sync.RWMutex
+	contents    [][]byte
+	certificate *tls.Certificate
+	clientCas   *x509.CertPool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Create loads the files once, failing if they cannot be used.
+func Create(certFile string, keyFile string, clientCaFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCaFile: clientCaFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files and replaces the certificate and client CAs if they changed.
+//
+// If the new files cannot be used, the previous ones are kept. Returns whether anything changed.
+func (r *Reloader) Reload() (bool, error) {
+	contents, err := r.readFiles()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.contents != nil && equalContents(r.contents, contents)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.X509KeyPair(contents[0], contents[1])
+	if err != nil {
+		return false, fmt.Errorf("cannot use certificate %s with key %s: %v", r.certFile, r.keyFile, err)
+	}
+	var clientCas *x509.CertPool
+	if r.clientCaFile != "" {
+		clientCas = x509.NewCertPool()
+		if !clientCas.AppendCertsFromPEM(contents[2]) {
+			return false, fmt.Errorf("client ca file %s contains no pem encoded certificates", r.clientCaFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.contents = contents
+	r.certificate = &certificate
+	r.clientCas = clientCas
+	return true, nil
+}
+
+func (r *Reloader) readFiles() ([][]byte, error) {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCaFile != "" {
+		files = append(files, r.clientCaFile)
+	}
+	contents := [][]byte{}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, content)
+	}
+	return contents, nil
+}
+
+func equalContents(a [][]byte, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Start checks the files for changes every interval until Stop is called.
+func (r *Reloader) Start(interval time.Duration) {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				changed, err := r.Reload()
+				if err != nil {
+					log.Error().Err(err).Msgf("failed to reload tls certificates, keeping the previous ones: %v", err)
+				} else if changed {
+					log.Info().Msgf("reloaded tls certificate %s", r.certFile)
+				}
+			}
+		}
+	}()
+}
+
+func (r *Reloader) Stop() {
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+		r.stop = nil
+	}
+}
+
+// TLSConfig returns a server configuration that always uses the most recently loaded files.
+//
+// If a client CA file was given, client certificates are verified against it, and required if clientCertRequired is set.
+func (r *Reloader) TLSConfig(minVersion uint16, clientCertRequired bool) *tls.Config {
+	config := &tls.Config{MinVersion: minVersion}
+	config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if r.certificate == nil {
+			return nil, errors.New("no tls certificate loaded")
+		}
+		return r.certificate, nil
+	}
+	// client CAs can only be changed per connection by handing out a whole new configuration
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if r.certificate == nil {
+			return nil, errors.New("no tls certificate loaded")
+		}
+		current := &tls.Config{
+			MinVersion:   minVersion,
+			Certificates: []tls.Certificate{*r.certificate},
+			NextProtos:   []string{"h2", "http/1.1"},
+		}
+		if r.clientCas != nil {
+			current.ClientCAs = r.clientCas
+			current.ClientAuth = tls.VerifyClientCertIfGiven
+			if clientCertRequired {
+				current.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+		}
+		return current, nil
+	}
+	return config
+}
@@ -0,0 +1,111 @@
+package certificates
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/certificates/certtest"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func tstServe(reloader *Reloader, clientCertRequired bool) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.String()))
+		}
+	}))
+	server.TLS = reloader.TLSConfig(tls.VersionTLS12, clientCertRequired)
+	server.StartTLS()
+	return server
+}
+
+func tstClient(p *certtest.PKI, certificates ...tls.Certificate) *http.Client {
+	transport := &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: p.CaPool, Certificates: certificates},
+		DisableKeepAlives: true,
+	}
+	return &http.Client{Transport: transport}
+}
+
+func tstServerSerial(t *testing.T, client *http.Client, url string) *big.Int {
+	response, err := client.Get(url)
+	require.Nil(t, err)
+	defer response.Body.Close()
+	return response.TLS.PeerCertificates[0].SerialNumber
+}
+
+func TestReloader_ShouldPickUpRenewedCertificate(t *testing.T) {
+	p, err := certtest.CreatePKI(t.TempDir())
+	require.Nil(t, err)
+	reloader, err := Create(p.CertFile, p.KeyFile, "")
+	require.Nil(t, err)
+	server := tstServe(reloader, false)
+	defer server.Close()
+	client := tstClient(p)
+	before := tstServerSerial(t, client, server.URL)
+
+	changed, err := reloader.Reload()
+	require.Nil(t, err)
+	require.False(t, changed)
+
+	require.Nil(t, p.RenewServerCertificate())
+	changed, err = reloader.Reload()
+	require.Nil(t, err)
+	require.True(t, changed)
+	require.NotEqual(t, before, tstServerSerial(t, client, server.URL))
+}
+
+func TestReloader_ShouldKeepCertificateIfNewOneIsBroken(t *testing.T) {
+	p, err := certtest.CreatePKI(t.TempDir())
+	require.Nil(t, err)
+	reloader, err := Create(p.CertFile, p.KeyFile, "")
+	require.Nil(t, err)
+	server := tstServe(reloader, false)
+	defer server.Close()
+
+	require.Nil(t, ioutil.WriteFile(p.KeyFile, []byte("not a key"), 0600))
+	_, err = reloader.Reload()
+	require.NotNil(t, err)
+
+	response, err := tstClient(p).Get(server.URL)
+	require.Nil(t, err)
+	require.Nil(t, response.Body.Close())
+}
+
+func TestTLSConfig_ShouldVerifyOptionalClientCertificates(t *testing.T) {
+	p, err := certtest.CreatePKI(t.TempDir())
+	require.Nil(t, err)
+	reloader, err := Create(p.CertFile, p.KeyFile, p.CaFile)
+	require.Nil(t, err)
+	server := tstServe(reloader, false)
+	defer server.Close()
+	clientCert, err := p.ClientCertificate(pkix.Name{CommonName: "campaign-service", Organization: []string{"Example"}})
+	require.Nil(t, err)
+
+	response, err := tstClient(p, clientCert).Get(server.URL)
+	require.Nil(t, err)
+	body, err := ioutil.ReadAll(response.Body)
+	require.Nil(t, err)
+	require.Nil(t, response.Body.Close())
+	require.Equal(t, "CN=campaign-service,O=Example", string(body))
+
+	response, err = tstClient(p).Get(server.URL)
+	require.Nil(t, err)
+	require.Nil(t, response.Body.Close())
+}
+
+func TestTLSConfig_ShouldRejectMissingClientCertificateIfRequired(t *testing.T) {
+	p, err := certtest.CreatePKI(t.TempDir())
+	require.Nil(t, err)
+	reloader, err := Create(p.CertFile, p.KeyFile, p.CaFile)
+	require.Nil(t, err)
+	server := tstServe(reloader, true)
+	defer server.Close()
+
+	_, err = tstClient(p).Get(server.URL)
+	require.NotNil(t, err)
+}
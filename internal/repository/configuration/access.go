@@ -1,8 +1,13 @@
 package configuration
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/spf13/viper"
+	"net"
+	"sort"
+	"strings"
+	"time"
 )
 
 // public functions for accessing all configuration values.
@@ -11,19 +16,237 @@ func ServerAddress() string {
 	return fmt.Sprintf("%v:%d", viper.GetString(configKeyServerAddress), viper.GetUint(configKeyServerPort))
 }
 
+func ServerShutdownGracePeriod() time.Duration {
+	return viper.GetDuration(configKeyServerShutdownGracePeriod)
+}
+
+// ServerRequestIdTrustedNetworks returns the networks whose callers may set the request id, invalid entries are skipped.
+func ServerRequestIdTrustedNetworks() []*net.IPNet {
+	result := []*net.IPNet{}
+	for _, entry := range splitList(viper.GetString(configKeyServerRequestIdTrustedNetworks)) {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			result = append(result, network)
+		}
+	}
+	return result
+}
+
+func ServerTlsEnabled() bool {
+	return viper.GetString(configKeyServerTlsCert) != ""
+}
+
+func ServerTlsCert() string {
+	return viper.GetString(configKeyServerTlsCert)
+}
+
+func ServerTlsKey() string {
+	return viper.GetString(configKeyServerTlsKey)
+}
+
+func ServerTlsClientCa() string {
+	return viper.GetString(configKeyServerTlsClientCa)
+}
+
+func ServerTlsClientRequired() bool {
+	return viper.GetBool(configKeyServerTlsClientRequired)
+}
+
+// ServerTlsMinVersion returns the minimum tls version as a crypto/tls constant.
+func ServerTlsMinVersion() uint16 {
+	if viper.GetString(configKeyServerTlsMinVersion) == "1.3" {
+		return tls.VersionTLS13
+	}
+	return tls.VersionTLS12
+}
+
+func ServerTlsReloadInterval() time.Duration {
+	return viper.GetDuration(configKeyServerTlsReloadInterval)
+}
+
+// ServerRequestMaxBodySize returns the maximum request body size in bytes, 0 means unlimited.
+func ServerRequestMaxBodySize() int64 {
+	return int64(viper.GetUint(configKeyServerRequestMaxBodySize))
+}
+
+func ServerRequestStrictJson() bool {
+	return viper.GetBool(configKeyServerRequestStrictJson)
+}
+
+func ServerRequestValidation() bool {
+	return viper.GetBool(configKeyServerRequestValidation)
+}
+
+func ServerResponseValidation() bool {
+	return viper.GetBool(configKeyServerResponseValidation)
+}
+
+// ApiV1Deprecation is the zero time if the v1 endpoints are not deprecated yet.
+func ApiV1Deprecation() time.Time {
+	return parseTimestamp(viper.GetString(configKeyApiV1Deprecation))
+}
+
+func GrpcEnabled() bool {
+	return viper.GetUint(configKeyGrpcPort) != 0
+}
+
+func GrpcAddress() string {
+	return fmt.Sprintf("%v:%d", viper.GetString(configKeyServerAddress), viper.GetUint(configKeyGrpcPort))
+}
+
+func GrpcWatchPollInterval() time.Duration {
+	return viper.GetDuration(configKeyGrpcWatchPollInterval)
+}
+
+// ApiV1Sunset is the zero time if no date has been decided yet.
+func ApiV1Sunset() time.Time {
+	return parseTimestamp(viper.GetString(configKeyApiV1Sunset))
+}
+
 func ServiceName() string {
 	return viper.GetString(configKeyServiceName)
 }
 
+func ActiveProfiles() []string {
+	return viper.GetStringSlice("profiles")
+}
+
 func IsProfileActive(profileName string) bool {
 	profiles := viper.GetStringSlice("profiles")
 	return contains(profiles, profileName)
 }
 
+func LoggingLevel() string {
+	return viper.GetString(configKeyLoggingLevel)
+}
+
+func LoggingFormat() string {
+	return viper.GetString(configKeyLoggingFormat)
+}
+
+// LoggingPackageLevels maps package names to their log level.
+func LoggingPackageLevels() map[string]string {
+	result := make(map[string]string)
+	for _, entry := range splitList(viper.GetString(configKeyLoggingPackages)) {
+		name, level := splitPair(entry)
+		result[name] = level
+	}
+	return result
+}
+
+func LoggingRedactionMode() string {
+	return redactionSetting("mode")
+}
+
+func LoggingRedactionAllow() []string {
+	return splitList(redactionSetting("allow"))
+}
+
+func LoggingRedactionDeny() []string {
+	return splitList(redactionSetting("deny"))
+}
+
+func redactionSetting(setting string) string {
+	return profileSetting("logging.redaction", setting)
+}
+
+// profileSetting returns <prefix>.<setting>, unless an active profile overrides it
+// in <prefix>.profiles.<profile>.<setting>. The last active profile wins.
+func profileSetting(prefix string, setting string) string {
+	value := viper.GetString(prefix + "." + setting)
+	for _, profile := range ActiveProfiles() {
+		if key := prefix + ".profiles." + profile + "." + setting; viper.IsSet(key) {
+			value = viper.GetString(key)
+		}
+	}
+	return value
+}
+
+// CorsOrigins returns the origins browsers may call the api from, empty if cors is off.
+func CorsOrigins() []string {
+	return splitList(profileSetting("cors", "origins"))
+}
+
+func CorsMethods() []string {
+	return splitList(viper.GetString(configKeyCorsMethods))
+}
+
+func CorsHeaders() []string {
+	return splitList(viper.GetString(configKeyCorsHeaders))
+}
+
+func CorsExpose() []string {
+	return splitList(viper.GetString(configKeyCorsExpose))
+}
+
+func CorsCredentials() bool {
+	return viper.GetBool(configKeyCorsCredentials)
+}
+
+func CorsMaxAge() time.Duration {
+	return viper.GetDuration(configKeyCorsMaxAge)
+}
+
 func SecuritySecret() string {
 	return viper.GetString(configKeySecuritySecret)
 }
 
+// ApiKey is a configured api key, only its hash is known.
+type ApiKey struct {
+	Owner string
+	// sha256:<hex>
+	Hash  string
+	Roles []string
+	// zero if the key does not expire
+	Expires time.Time
+}
+
+// SecurityApiKeys returns the configured api keys ordered by owner, entries with an invalid expiry are skipped.
+func SecurityApiKeys() []ApiKey {
+	owners := []string{}
+	for owner := range viper.GetStringMap(configKeySecurityApiKeys) {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	result := []ApiKey{}
+	for _, owner := range owners {
+		prefix := configKeySecurityApiKeys + "." + owner
+		apiKey := ApiKey{
+			Owner: owner,
+			Hash:  viper.GetString(prefix + ".hash"),
+			Roles: splitList(viper.GetString(prefix + ".roles")),
+		}
+		if expires := viper.GetString(prefix + ".expires"); expires != "" {
+			parsed, err := time.Parse(time.RFC3339, expires)
+			if err != nil {
+				continue
+			}
+			apiKey.Expires = parsed
+		}
+		result = append(result, apiKey)
+	}
+	return result
+}
+
+func MetricsMode() string {
+	if mode := viper.GetString(configKeyMetricsMode); mode != "" {
+		return mode
+	}
+	if EnableMetricsPush() {
+		return "push"
+	}
+	return "inmem"
+}
+
+// MetricsDomains returns the recipient domains that get their own label, in lower case.
+func MetricsDomains() []string {
+	result := []string{}
+	for _, domain := range splitList(viper.GetString(configKeyMetricsDomains)) {
+		result = append(result, strings.ToLower(domain))
+	}
+	return result
+}
+
 func EnableMetricsPush() bool {
 	return viper.GetBool(configKeyMetricsEnable)
 }
@@ -35,3 +258,153 @@ func MetricsPushAddress() string {
 func MetricsPushSinkName() string {
 	return viper.GetString(configKeyMetricsName)
 }
+
+func TracingExporter() string {
+	return viper.GetString(configKeyTracingExporter)
+}
+
+func TracingOtlpEndpoint() string {
+	return viper.GetString(configKeyTracingOtlpEndpoint)
+}
+
+func TracingOtlpInsecure() bool {
+	return viper.GetBool(configKeyTracingOtlpInsecure)
+}
+
+func DatabaseDirectory() string {
+	return viper.GetString(configKeyDatabaseDirectory)
+}
+
+func RetentionContent() time.Duration {
+	return viper.GetDuration(configKeyRetentionContent)
+}
+
+func RetentionMetadata() time.Duration {
+	return viper.GetDuration(configKeyRetentionMetadata)
+}
+
+func RetentionPurgeInterval() time.Duration {
+	return viper.GetDuration(configKeyRetentionPurgeInterval)
+}
+
+func AuditFile() string {
+	return viper.GetString(configKeyAuditFile)
+}
+
+func AuditRecipients() string {
+	return viper.GetString(configKeyAuditRecipients)
+}
+
+// AuditKey is blank if not configured, which is only allowed without audit.file.
+func AuditKey() string {
+	return viper.GetString(configKeyAuditKey)
+}
+
+func MailSmtpHost() string {
+	return viper.GetString(configKeyMailSmtpHost)
+}
+
+func MailSmtpAddress() string {
+	return fmt.Sprintf("%v:%d", viper.GetString(configKeyMailSmtpHost), viper.GetUint(configKeyMailSmtpPort))
+}
+
+func MailSmtpUsername() string {
+	return viper.GetString(configKeyMailSmtpUsername)
+}
+
+func MailSmtpPassword() string {
+	return viper.GetString(configKeyMailSmtpPassword)
+}
+
+func MailSmtpTimeout() time.Duration {
+	return viper.GetDuration(configKeyMailSmtpTimeout)
+}
+
+func MailFrom() string {
+	return viper.GetString(configKeyMailFrom)
+}
+
+func MailBounceAddress() string {
+	return viper.GetString(configKeyMailBounceAddress)
+}
+
+func SchedulerMaxHorizon() time.Duration {
+	return viper.GetDuration(configKeySchedulerMaxHorizon)
+}
+
+func SchedulerPollInterval() time.Duration {
+	return viper.GetDuration(configKeySchedulerPollInterval)
+}
+
+func BouncesMailboxDirectory() string {
+	return viper.GetString(configKeyBouncesMailboxDirectory)
+}
+
+func BouncesMailboxPollInterval() time.Duration {
+	return viper.GetDuration(configKeyBouncesMailboxPollInterval)
+}
+
+func WebhooksMaxAttempts() int {
+	return viper.GetInt(configKeyWebhooksMaxAttempts)
+}
+
+func WebhooksRetryBackoff() time.Duration {
+	return viper.GetDuration(configKeyWebhooksRetryBackoff)
+}
+
+func WebhooksTimeout() time.Duration {
+	return viper.GetDuration(configKeyWebhooksTimeout)
+}
+
+func WebhooksCloudEventsMode() string {
+	return viper.GetString(configKeyWebhooksCloudEventsMode)
+}
+
+func EventsTypePrefix() string {
+	return viper.GetString(configKeyEventsTypePrefix)
+}
+
+func KafkaBrokers() string {
+	return viper.GetString(configKeyKafkaBrokers)
+}
+
+func KafkaGroupId() string {
+	return viper.GetString(configKeyKafkaGroupId)
+}
+
+func KafkaCommandTopic() string {
+	return viper.GetString(configKeyKafkaCommandTopic)
+}
+
+func KafkaErrorTopic() string {
+	return viper.GetString(configKeyKafkaErrorTopic)
+}
+
+// splitList splits a comma separated value, dropping blank entries
+func splitList(value string) []string {
+	result := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// splitPair splits name=value, both trimmed
+func splitPair(entry string) (string, string) {
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) < 2 {
+		return strings.TrimSpace(parts[0]), ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+// parseTimestamp parses an RFC 3339 timestamp, the zero time if it is blank or invalid
+func parseTimestamp(value string) time.Time {
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return timestamp
+}
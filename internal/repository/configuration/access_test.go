@@ -2,7 +2,9 @@ package configuration
 
 import (
 	"github.com/StephanHCB/go-autumn-config"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 )
 
@@ -13,3 +15,32 @@ func TestServerAddress(t *testing.T) {
 	actual := ServerAddress()
 	require.Equal(t, expected, actual)
 }
+
+func TestGrpcAddress(t *testing.T) {
+	auconfig.SetupDefaultsOnly(configItems, failFunction, warnFunction)
+	require.False(t, GrpcEnabled())
+
+	defer viper.Set(configKeyGrpcPort, 0)
+	viper.Set(configKeyGrpcPort, 9090)
+	require.True(t, GrpcEnabled())
+	require.Equal(t, ":9090", GrpcAddress())
+}
+
+func TestSecurityApiKeys_ShouldParseRolesAndExpiry(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeySecurityApiKeys, map[string]interface{}{})
+	viper.Set(configKeySecurityApiKeys, map[string]interface{}{
+		"reporting": map[string]interface{}{"hash": "sha256:" + strings.Repeat("cd", 32)},
+		"crm":       map[string]interface{}{"hash": "sha256:" + strings.Repeat("ab", 32), "roles": "admin, sender", "expires": "2030-01-01T00:00:00Z"},
+	})
+
+	require.Nil(t, checkApiKeys(configKeySecurityApiKeys))
+	keys := SecurityApiKeys()
+	require.Len(t, keys, 2)
+	require.Equal(t, "crm", keys[0].Owner)
+	require.Equal(t, []string{"admin", "sender"}, keys[0].Roles)
+	require.Equal(t, 2030, keys[0].Expires.Year())
+	require.Equal(t, "reporting", keys[1].Owner)
+	require.Empty(t, keys[1].Roles)
+	require.True(t, keys[1].Expires.IsZero())
+}
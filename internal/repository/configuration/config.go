@@ -7,11 +7,74 @@ import (
 
 const configKeyServerAddress = "server.address"
 const configKeyServerPort = "server.port"
+const configKeyServerShutdownGracePeriod = "server.shutdown.grace.period"
+const configKeyServerRequestIdTrustedNetworks = "server.requestid.trusted.networks"
+const configKeyServerTlsCert = "server.tls.cert"
+const configKeyServerTlsKey = "server.tls.key"
+const configKeyServerTlsClientCa = "server.tls.client.ca"
+const configKeyServerTlsClientRequired = "server.tls.client.required"
+const configKeyServerTlsMinVersion = "server.tls.min.version"
+const configKeyServerTlsReloadInterval = "server.tls.reload.interval"
+const configKeyServerRequestMaxBodySize = "server.request.max.body.size"
+const configKeyServerRequestStrictJson = "server.request.strict.json"
+const configKeyServerRequestValidation = "server.request.validation"
+const configKeyServerResponseValidation = "server.response.validation"
+const configKeyGrpcPort = "grpc.port"
+const configKeyGrpcWatchPollInterval = "grpc.watch.poll.interval"
+const configKeyApiV1Deprecation = "api.v1.deprecation"
+const configKeyApiV1Sunset = "api.v1.sunset"
 const configKeyServiceName = "service.name"
+const configKeyLoggingLevel = "logging.level"
+const configKeyLoggingFormat = "logging.format"
+const configKeyLoggingPackages = "logging.packages"
+const configKeyLoggingRedactionMode = "logging.redaction.mode"
+const configKeyLoggingRedactionAllow = "logging.redaction.allow"
+const configKeyLoggingRedactionDeny = "logging.redaction.deny"
+const configKeyLoggingRedactionProfiles = "logging.redaction.profiles"
 const configKeySecuritySecret = "security.secret"
+const configKeySecurityApiKeys = "security.apikeys"
+const configKeyCorsOrigins = "cors.origins"
+const configKeyCorsProfiles = "cors.profiles"
+const configKeyCorsMethods = "cors.methods"
+const configKeyCorsHeaders = "cors.headers"
+const configKeyCorsExpose = "cors.expose"
+const configKeyCorsCredentials = "cors.credentials"
+const configKeyCorsMaxAge = "cors.max.age"
+const configKeyMetricsMode = "metrics.mode"
 const configKeyMetricsEnable = "metrics.push.enable"
 const configKeyMetricsAddress = "metrics.push.address"
 const configKeyMetricsName = "metrics.push.name"
+const configKeyMetricsDomains = "metrics.domains"
+const configKeyTracingExporter = "tracing.exporter"
+const configKeyTracingOtlpEndpoint = "tracing.otlp.endpoint"
+const configKeyTracingOtlpInsecure = "tracing.otlp.insecure"
+const configKeyDatabaseDirectory = "database.directory"
+const configKeyRetentionContent = "retention.content"
+const configKeyRetentionMetadata = "retention.metadata"
+const configKeyRetentionPurgeInterval = "retention.purge.interval"
+const configKeyAuditFile = "audit.file"
+const configKeyAuditRecipients = "audit.recipients"
+const configKeyAuditKey = "audit.key"
+const configKeyMailSmtpHost = "mail.smtp.host"
+const configKeyMailSmtpPort = "mail.smtp.port"
+const configKeyMailSmtpUsername = "mail.smtp.username"
+const configKeyMailSmtpPassword = "mail.smtp.password"
+const configKeyMailSmtpTimeout = "mail.smtp.timeout"
+const configKeyMailFrom = "mail.from"
+const configKeyMailBounceAddress = "mail.bounce.address"
+const configKeySchedulerMaxHorizon = "scheduler.max.horizon"
+const configKeySchedulerPollInterval = "scheduler.poll.interval"
+const configKeyBouncesMailboxDirectory = "bounces.mailbox.directory"
+const configKeyBouncesMailboxPollInterval = "bounces.mailbox.poll.interval"
+const configKeyWebhooksMaxAttempts = "webhooks.max.attempts"
+const configKeyWebhooksRetryBackoff = "webhooks.retry.backoff"
+const configKeyWebhooksTimeout = "webhooks.timeout"
+const configKeyWebhooksCloudEventsMode = "webhooks.cloudevents.mode"
+const configKeyEventsTypePrefix = "events.type.prefix"
+const configKeyKafkaBrokers = "kafka.brokers"
+const configKeyKafkaGroupId = "kafka.group.id"
+const configKeyKafkaCommandTopic = "kafka.topic.commands"
+const configKeyKafkaErrorTopic = "kafka.topic.errors"
 
 var configItems = []auconfigapi.ConfigItem{
 	auconfig.ConfigItemProfile,
@@ -25,6 +88,86 @@ var configItems = []auconfigapi.ConfigItem{
 		Default:     uint(8080),
 		Description: "port to listen on, defaults to 8080 if not set",
 		Validate:    checkValidPortNumber,
+	}, {
+		Key:         configKeyServerShutdownGracePeriod,
+		Default:     "20s",
+		Description: "time to finish in-flight requests and pending deliveries after SIGTERM or SIGINT, as a go duration, keep it below the kubernetes termination grace period",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeyServerRequestIdTrustedNetworks,
+		Default:     "",
+		Description: "comma separated networks in CIDR notation, e.g. 10.0.0.0/8, whose X-Request-Id header is used instead of generating a new request id. Blank trusts nobody",
+		Validate:    checkCidrList,
+	}, {
+		Key:         configKeyServerTlsCert,
+		Default:     "",
+		Description: "pem encoded server certificate chain file, leave blank to serve plain http, e.g. behind a tls terminating load balancer",
+		Validate:    checkTlsFiles,
+	}, {
+		Key:         configKeyServerTlsKey,
+		Default:     "",
+		Description: "pem encoded private key file for server.tls.cert",
+		Validate:    func(key string) error { return checkLength(0, 255, key) },
+	}, {
+		Key:         configKeyServerTlsClientCa,
+		Default:     "",
+		Description: "pem encoded ca certificates that client certificates are verified against, leave blank to not ask for client certificates",
+		Validate:    func(key string) error { return checkLength(0, 255, key) },
+	}, {
+		Key:         configKeyServerTlsClientRequired,
+		Default:     false,
+		Description: "reject connections without a valid client certificate, otherwise callers may also authenticate with a token",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyServerTlsMinVersion,
+		Default:     "1.2",
+		Description: "minimum tls version, 1.2 or 1.3",
+		Validate:    func(key string) error { return checkOneOf(key, "1.2", "1.3") },
+	}, {
+		Key:         configKeyServerTlsReloadInterval,
+		Default:     "1m",
+		Description: "how often the certificate, key and client ca files are checked for changes, as a go duration",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeyServerRequestMaxBodySize,
+		Default:     uint(1048576),
+		Description: "maximum size of a request body in bytes, larger requests are rejected with 413, 0 disables the limit",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyServerRequestStrictJson,
+		Default:     false,
+		Description: "reject request bodies with fields that are not part of the api, otherwise unknown fields are ignored",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyServerRequestValidation,
+		Default:     true,
+		Description: "reject requests that do not match the api spec",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyServerResponseValidation,
+		Default:     false,
+		Description: "also validate responses against the spec and replace those that violate it with a 500, meant for tests as every response is buffered",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyGrpcPort,
+		Default:     uint(0),
+		Description: "port to serve the grpc api on, on the same address as the web server, 0 disables the grpc api, which is the default",
+		Validate:    checkValidOptionalPortNumber,
+	}, {
+		Key:         configKeyGrpcWatchPollInterval,
+		Default:     "5s",
+		Description: "how often a status watch rereads its email, as a go duration, catches changes that publish no event such as cancellation",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeyApiV1Deprecation,
+		Default:     "",
+		Description: "RFC 3339 timestamp since when the v1 endpoints that have a v2 successor are deprecated, sent in the Deprecation header. Blank if they are not deprecated yet",
+		Validate:    checkValidTimestamp,
+	}, {
+		Key:         configKeyApiV1Sunset,
+		Default:     "",
+		Description: "RFC 3339 timestamp at which the deprecated v1 endpoints will be removed, sent in the Sunset header. Blank if not decided yet",
+		Validate:    checkValidTimestamp,
 	}, {
 		Key:         configKeyServiceName,
 		Default:     "unnamed-service",
@@ -37,12 +180,86 @@ var configItems = []auconfigapi.ConfigItem{
 		Default:     "",
 		Description: "secret used for signing jwt tokens",
 		Validate:    func(key string) error { return checkLength(1, 255, key) },
+	}, {
+		Key:         configKeySecurityApiKeys,
+		Default:     map[string]interface{}{},
+		Description: "api keys for callers that cannot obtain a token, by lower case owner name, each with hash (sha256:<hex> of the key), roles (comma separated) and optional expires (RFC 3339)",
+		Validate:    checkApiKeys,
+	},
+	// cors configuration
+	{
+		Key:         configKeyCorsOrigins,
+		Default:     "",
+		Description: "comma separated origins that browsers may call the api from, such as https://admin.example.com, or * for any. Blank turns cors off. Can be overridden per profile in cors.profiles.<profile>.origins",
+		Validate:    checkCorsOrigins,
+	}, {
+		Key:         configKeyCorsMethods,
+		Default:     "GET,POST,PUT,PATCH,DELETE",
+		Description: "comma separated methods allowed in cross origin requests",
+		Validate:    checkHttpTokens,
+	}, {
+		Key:         configKeyCorsHeaders,
+		Default:     "Accept,Authorization,Content-Type,X-API-Key,X-Request-Id",
+		Description: "comma separated request headers allowed in cross origin requests",
+		Validate:    checkHttpTokens,
+	}, {
+		Key:         configKeyCorsExpose,
+		Default:     "Location,X-Request-Id,Deprecation,Sunset,Link",
+		Description: "comma separated response headers that browsers let cross origin callers read",
+		Validate:    checkHttpTokens,
+	}, {
+		Key:         configKeyCorsCredentials,
+		Default:     false,
+		Description: "allow cross origin requests with cookies or the Authorization header, cannot be combined with origin *",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyCorsMaxAge,
+		Default:     "10m",
+		Description: "how long browsers may cache the answer to a preflight request, as a go duration",
+		Validate:    checkValidDuration,
+	},
+	// logging configuration
+	{
+		Key:         configKeyLoggingLevel,
+		Default:     "info",
+		Description: "global log level, one of trace, debug, info, warn, error, fatal, panic",
+		Validate:    checkLogLevel,
+	}, {
+		Key:         configKeyLoggingFormat,
+		Default:     "",
+		Description: "json for ECS compliant json logging, or console for human readable logging. If blank, console if the profile local is active, json otherwise",
+		Validate:    func(key string) error { return checkOneOf(key, "", "json", "console") },
+	}, {
+		Key:         configKeyLoggingPackages,
+		Default:     "",
+		Description: "comma separated log level overrides for individual packages, e.g. emailsrv=debug,requestlogging=warn",
+		Validate:    checkLogLevelOverrides,
+	}, {
+		Key:         configKeyLoggingRedactionMode,
+		Default:     "mask",
+		Description: "how email addresses in log entries are redacted, mask (j***@example.com), hash or none. Can be overridden per profile in logging.redaction.profiles.<profile>.mode",
+		Validate:    checkRedactionMode,
+	}, {
+		Key:         configKeyLoggingRedactionAllow,
+		Default:     "",
+		Description: "comma separated log fields that are never redacted. Can be overridden per profile in logging.redaction.profiles.<profile>.allow",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyLoggingRedactionDeny,
+		Default:     "subject",
+		Description: "comma separated log fields that are always removed, in addition to email bodies. Can be overridden per profile in logging.redaction.profiles.<profile>.deny",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
 	},
 	// prometheus configuration
 	{
+		Key:         configKeyMetricsMode,
+		Default:     "",
+		Description: "push to a prometheus push gateway, pull by offering /metrics for scraping, both, or inmem. If blank, push if metrics.push.enable is set, inmem otherwise",
+		Validate:    func(key string) error { return checkOneOf(key, "", "push", "pull", "both", "inmem") },
+	}, {
 		Key:         configKeyMetricsEnable,
 		Default:     false,
-		Description: "enable push to prometheus server",
+		Description: "enable push to prometheus server, deprecated, use metrics.mode instead",
 		Validate:    auconfigapi.ConfigNeedsNoValidation,
 	}, {
 		Key:         configKeyMetricsAddress,
@@ -54,5 +271,180 @@ var configItems = []auconfigapi.ConfigItem{
 		Default:     "somesink",
 		Description: "push sink name",
 		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyMetricsDomains,
+		Default:     "gmail.com,googlemail.com,outlook.com,hotmail.com,live.com,yahoo.com,icloud.com,gmx.de,web.de,t-online.de",
+		Description: "comma separated recipient domains that get their own metrics label, all others are counted as other to keep cardinality bounded",
+		Validate:    func(key string) error { return checkLength(0, 4096, key) },
+	},
+	// tracing configuration
+	{
+		Key:         configKeyTracingExporter,
+		Default:     "none",
+		Description: "where to export opentelemetry spans, none, stdout or otlp",
+		Validate:    func(key string) error { return checkOneOf(key, "none", "stdout", "otlp") },
+	}, {
+		Key:         configKeyTracingOtlpEndpoint,
+		Default:     "localhost:4318",
+		Description: "otlp/http collector address, format host:port",
+		Validate:    func(key string) error { return checkLength(1, 255, key) },
+	}, {
+		Key:         configKeyTracingOtlpInsecure,
+		Default:     false,
+		Description: "send spans to the otlp collector over plain http instead of https",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	},
+	// database configuration
+	{
+		Key:         configKeyDatabaseDirectory,
+		Default:     "",
+		Description: "directory in which emails are stored, leave blank to use an in-memory database that does not survive restarts",
+		Validate:    func(key string) error { return checkLength(0, 255, key) },
+	},
+	// retention configuration
+	{
+		Key:         configKeyRetentionContent,
+		Default:     "168h",
+		Description: "how long the subject and body of an email are kept after it was submitted, as a go duration. Scheduled emails are kept until they are sent",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeyRetentionMetadata,
+		Default:     "2160h",
+		Description: "how long the remaining status record of an email is kept after it was submitted, as a go duration",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeyRetentionPurgeInterval,
+		Default:     "1h",
+		Description: "how often expired emails are purged, as a go duration",
+		Validate:    checkValidDuration,
+	},
+	// audit log configuration
+	{
+		Key:         configKeyAuditFile,
+		Default:     "",
+		Description: "append-only JSONL file that records every send attempt, leave blank to keep the audit log in memory only",
+		Validate:    func(key string) error { return checkLength(0, 255, key) },
+	}, {
+		Key:         configKeyAuditRecipients,
+		Default:     "hashed",
+		Description: "how recipients are recorded in the audit log, only hashed (hmac-sha256 of the lower case address, keyed with audit.key) is allowed, as clear text addresses would survive retention and erasure",
+		Validate:    checkAuditRecipients,
+	}, {
+		Key:         configKeyAuditKey,
+		Default:     "",
+		Description: "secret key, at least 32 characters, for the hmac of the audit log chain and of hashed recipients. Required if audit.file is set, otherwise a random key is used",
+		Validate:    checkAuditKey,
+	},
+	// mail transport configuration
+	{
+		Key:         configKeyMailSmtpHost,
+		Default:     "",
+		Description: "smtp server hostname, leave blank to only record emails in memory instead of sending them",
+		Validate:    func(key string) error { return checkLength(0, 255, key) },
+	}, {
+		Key:         configKeyMailSmtpPort,
+		Default:     uint(587),
+		Description: "smtp server port, defaults to 587 if not set",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyMailSmtpUsername,
+		Default:     "",
+		Description: "username for smtp authentication, leave blank to disable authentication",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyMailSmtpPassword,
+		Default:     "",
+		Description: "password for smtp authentication",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyMailSmtpTimeout,
+		Default:     "30s",
+		Description: "timeout for sending a single email to the smtp server, including connecting, as a go duration",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeyMailFrom,
+		Default:     "mailer@localhost",
+		Description: "sender address for all emails",
+		Validate:    func(key string) error { return checkLength(3, 255, key) },
+	}, {
+		Key:         configKeyMailBounceAddress,
+		Default:     "",
+		Description: "envelope sender address that receives bounces, the email id is added using VERP (bounces+id@example.com), leave blank to use the sender address",
+		Validate:    func(key string) error { return checkLength(0, 255, key) },
+	},
+	// scheduler configuration
+	{
+		Key:         configKeySchedulerMaxHorizon,
+		Default:     "720h",
+		Description: "how far into the future emails may be scheduled, as a go duration",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeySchedulerPollInterval,
+		Default:     "10s",
+		Description: "how often the scheduler checks for emails that are due, as a go duration",
+		Validate:    checkValidDuration,
+	},
+	// bounce processing configuration
+	{
+		Key:         configKeyBouncesMailboxDirectory,
+		Default:     "",
+		Description: "maildir style directory to read bounces from, new messages are picked up from its new subdirectory, leave blank to disable",
+		Validate:    func(key string) error { return checkLength(0, 255, key) },
+	}, {
+		Key:         configKeyBouncesMailboxPollInterval,
+		Default:     "1m",
+		Description: "how often the bounce mailbox directory is checked for new messages, as a go duration",
+		Validate:    checkValidDuration,
+	},
+	// outgoing webhook configuration
+	{
+		Key:         configKeyWebhooksMaxAttempts,
+		Default:     uint(5),
+		Description: "how often a webhook callback is attempted before giving up",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	}, {
+		Key:         configKeyWebhooksRetryBackoff,
+		Default:     "2s",
+		Description: "wait time before the first retry of a failed webhook callback, doubles with every further retry, as a go duration",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeyWebhooksTimeout,
+		Default:     "10s",
+		Description: "timeout for a single webhook callback, as a go duration",
+		Validate:    checkValidDuration,
+	}, {
+		Key:         configKeyWebhooksCloudEventsMode,
+		Default:     "structured",
+		Description: "cloudevents content mode for webhook callbacks, structured or binary",
+		Validate:    func(key string) error { return checkOneOf(key, "structured", "binary") },
+	},
+	// event configuration
+	{
+		Key:         configKeyEventsTypePrefix,
+		Default:     "com.example.mailer",
+		Description: "reverse dns prefix for cloudevents type attributes, e.g. com.example.mailer gives com.example.mailer.email.sent.v1",
+		Validate:    func(key string) error { return checkLength(1, 255, key) },
+	},
+	// kafka configuration
+	{
+		Key:         configKeyKafkaBrokers,
+		Default:     "",
+		Description: "comma separated list of kafka broker addresses, leave blank to disable the send-email command consumer",
+		Validate:    func(key string) error { return checkLength(0, 1024, key) },
+	}, {
+		Key:         configKeyKafkaGroupId,
+		Default:     "go-mailer-service",
+		Description: "kafka consumer group id for the send-email command consumer",
+		Validate:    func(key string) error { return checkLength(1, 255, key) },
+	}, {
+		Key:         configKeyKafkaCommandTopic,
+		Default:     "mailer.send-email.commands",
+		Description: "kafka topic to read send-email commands from",
+		Validate:    func(key string) error { return checkLength(1, 249, key) },
+	}, {
+		Key:         configKeyKafkaErrorTopic,
+		Default:     "mailer.send-email.errors",
+		Description: "kafka topic to publish failed send-email commands to",
+		Validate:    func(key string) error { return checkLength(1, 249, key) },
 	},
 }
@@ -0,0 +1,45 @@
+package configuration
+
+import "github.com/spf13/viper"
+
+const RedactedValue = "*****"
+
+// values for these keys must never be shown, they are usually provided through the secrets file
+var secretKeys = []string{
+	configKeySecuritySecret,
+	configKeyMailSmtpPassword,
+	configKeySecurityApiKeys,
+	configKeyAuditKey,
+}
+
+type EffectiveValue struct {
+	Key         string
+	Description string
+	// RedactedValue for secrets that are set
+	Value interface{}
+}
+
+// EffectiveValues lists the value of every configuration item, after all sources have been merged.
+func EffectiveValues() []EffectiveValue {
+	result := make([]EffectiveValue, 0, len(configItems))
+	for _, item := range configItems {
+		value := viper.Get(item.Key)
+		if contains(secretKeys, item.Key) && isSet(value) {
+			value = RedactedValue
+		}
+		result = append(result, EffectiveValue{
+			Key:         item.Key,
+			Description: item.Description,
+			Value:       value,
+		})
+	}
+	return result
+}
+
+// the api keys are a map, which viper renders as a blank string
+func isSet(value interface{}) bool {
+	if values, ok := value.(map[string]interface{}); ok {
+		return len(values) > 0
+	}
+	return value != nil && value != ""
+}
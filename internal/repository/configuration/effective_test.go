@@ -0,0 +1,24 @@
+package configuration
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEffectiveValues_ShouldRedactSecretsThatAreSet(t *testing.T) {
+	tstSetup("localhost", 8081)
+	viper.Set(configKeySecuritySecret, "verysecret")
+	viper.Set(configKeyMailSmtpPassword, "")
+
+	values := map[string]interface{}{}
+	for _, value := range EffectiveValues() {
+		values[value.Key] = value.Value
+	}
+
+	require.Len(t, values, len(configItems))
+	require.Equal(t, "localhost", values[configKeyServerAddress])
+	require.Equal(t, uint(8081), values[configKeyServerPort])
+	require.Equal(t, RedactedValue, values[configKeySecuritySecret])
+	require.Equal(t, "", values[configKeyMailSmtpPassword])
+}
@@ -1,15 +1,21 @@
 package configuration
 
 import (
+	"context"
+	"errors"
 	"github.com/StephanHCB/go-autumn-config"
 	auconfigapi "github.com/StephanHCB/go-autumn-config-api"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/health"
 	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
 )
 
 // initialize configuration with full setup - you need to call this
 func Setup() {
 	auconfig.Setup(configItems, fail, warn)
 	auconfig.Load()
+	health.Register("configuration", time.Second, checkValid)
 }
 
 // use this in unit tests
@@ -22,6 +28,20 @@ func SetupForIntegrationTest(failFunc auconfigapi.ConfigFailFunc, warnFunc aucon
 	auconfig.ResetForTesting()
 	auconfig.SetupWithOverriddenConfigPath(configItems, failFunc, warnFunc, configPath, secretsPath)
 	auconfig.Load()
+	health.Register("configuration", time.Second, checkValid)
+}
+
+// checkValid runs all validations again, so readiness also reports configuration problems
+func checkValid(_ context.Context) error {
+	for _, item := range configItems {
+		if item.Validate == nil {
+			continue
+		}
+		if err := item.Validate(item.Key); err != nil {
+			return errors.New(strings.TrimSpace(err.Error()))
+		}
+	}
+	return nil
 }
 
 func fail(err error) {
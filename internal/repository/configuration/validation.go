@@ -2,9 +2,20 @@ package configuration
 
 import (
 	"fmt"
+	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
 )
 
+var apiKeyHashPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// the characters RFC 7230 allows in methods and header names
+var httpTokenPattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
 func checkLength(min int, max int, key string) error {
 	value := viper.GetString(key)
 	if len(value) < min || len(value) > max {
@@ -20,3 +31,151 @@ func checkValidPortNumber(key string) error {
 	}
 	return nil
 }
+
+// 0 is allowed and means off
+func checkValidOptionalPortNumber(key string) error {
+	if viper.GetUint(key) == 0 {
+		return nil
+	}
+	return checkValidPortNumber(key)
+}
+
+func checkValidDuration(key string) error {
+	value, err := time.ParseDuration(viper.GetString(key))
+	if err != nil || value <= 0 {
+		return fmt.Errorf("Fatal error: configuration value for key %s is not a positive duration\n", key)
+	}
+	return nil
+}
+
+// blank is allowed, use checkLength to require a value
+func checkValidTimestamp(key string) error {
+	value := viper.GetString(key)
+	if _, err := time.Parse(time.RFC3339, value); value != "" && err != nil {
+		return fmt.Errorf("Fatal error: configuration value for key %s is not an RFC 3339 timestamp\n", key)
+	}
+	return nil
+}
+
+func checkOneOf(key string, allowed ...string) error {
+	if !contains(allowed, viper.GetString(key)) {
+		return fmt.Errorf("Fatal error: configuration value for key %s must be one of %s\n", key, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+func checkCidrList(key string) error {
+	for _, entry := range splitList(viper.GetString(key)) {
+		if _, _, err := net.ParseCIDR(entry); err != nil {
+			return fmt.Errorf("Fatal error: configuration value for key %s contains %s, which is not a network in CIDR notation\n", key, entry)
+		}
+	}
+	return nil
+}
+
+func checkLogLevel(key string) error {
+	if !isLogLevel(viper.GetString(key)) {
+		return fmt.Errorf("Fatal error: configuration value for key %s is not a log level\n", key)
+	}
+	return nil
+}
+
+func checkLogLevelOverrides(key string) error {
+	for _, entry := range splitList(viper.GetString(key)) {
+		name, level := splitPair(entry)
+		if name == "" || !isLogLevel(level) {
+			return fmt.Errorf("Fatal error: configuration value for key %s contains %s, which is not of the form package=level\n", key, entry)
+		}
+	}
+	return nil
+}
+
+// zerolog accepts the empty string as "no level", which makes no sense in configuration
+func isLogLevel(value string) bool {
+	level, err := zerolog.ParseLevel(value)
+	return err == nil && level != zerolog.NoLevel
+}
+
+func checkRedactionMode(key string) error {
+	if !contains([]string{"mask", "hash", "none"}, redactionSetting("mode")) {
+		return fmt.Errorf("Fatal error: configuration value for key %s or its profile override must be one of mask, hash, none\n", key)
+	}
+	return nil
+}
+
+func checkCorsOrigins(key string) error {
+	for _, origin := range CorsOrigins() {
+		if origin == "*" {
+			if viper.GetBool(configKeyCorsCredentials) {
+				return fmt.Errorf("Fatal error: configuration value for key %s or its profile override must not be * while %s is true\n", key, configKeyCorsCredentials)
+			}
+			continue
+		}
+		// browsers send the origin as scheme://host[:port], anything else never matches
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.User != nil ||
+			parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+			return fmt.Errorf("Fatal error: configuration value for key %s or its profile override must be * or a comma separated list of origins such as https://admin.example.com\n", key)
+		}
+	}
+	return nil
+}
+
+func checkHttpTokens(key string) error {
+	for _, token := range splitList(viper.GetString(key)) {
+		if !httpTokenPattern.MatchString(token) {
+			return fmt.Errorf("Fatal error: configuration value for key %s must be a comma separated list of http methods or header names\n", key)
+		}
+	}
+	return nil
+}
+
+func checkTlsFiles(key string) error {
+	cert := viper.GetString(configKeyServerTlsCert)
+	if (cert == "") != (viper.GetString(configKeyServerTlsKey) == "") {
+		return fmt.Errorf("Fatal error: configuration values for keys %s and %s must either both be set or both be blank\n", key, configKeyServerTlsKey)
+	}
+	if cert == "" && viper.GetString(configKeyServerTlsClientCa) != "" {
+		return fmt.Errorf("Fatal error: configuration value for key %s requires %s to be set\n", configKeyServerTlsClientCa, key)
+	}
+	return checkLength(0, 255, key)
+}
+
+func checkApiKeys(key string) error {
+	for owner := range viper.GetStringMap(key) {
+		prefix := key + "." + owner
+		if !apiKeyHashPattern.MatchString(viper.GetString(prefix + ".hash")) {
+			return fmt.Errorf("Fatal error: configuration value for key %s.hash must be sha256: followed by 64 lower case hex digits\n", prefix)
+		}
+		if expires := viper.GetString(prefix + ".expires"); expires != "" {
+			if _, err := time.Parse(time.RFC3339, expires); err != nil {
+				return fmt.Errorf("Fatal error: configuration value for key %s.expires is not an RFC 3339 timestamp\n", prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// the chain of a file must be verifiable after a restart, so it needs a key that is kept
+func checkAuditKey(key string) error {
+	value := viper.GetString(key)
+	if value == "" {
+		if viper.GetString(configKeyAuditFile) != "" {
+			return fmt.Errorf("Fatal error: configuration value for key %s is required when %s is set\n", key, configKeyAuditFile)
+		}
+		return nil
+	}
+	if len(value) < 32 {
+		return fmt.Errorf("Fatal error: configuration value for key %s must be at least 32 characters long\n", key)
+	}
+	return nil
+}
+
+// retention and erasure always run, but cannot remove addresses from the append-only audit log, so it only
+// gets to keep their hashes
+func checkAuditRecipients(key string) error {
+	if viper.GetString(key) == "clear" {
+		return fmt.Errorf("Fatal error: configuration value for key %s cannot be clear while retention is on, erased addresses would stay in the audit log, use hashed\n", key)
+	}
+	return checkOneOf(key, "hashed")
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/StephanHCB/go-autumn-config"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 )
 
@@ -72,3 +73,290 @@ func TestCheckValidPortNumber_TooHigh(t *testing.T) {
 	require.NotNil(t, err)
 	require.Equal(t, expectedMessage, err.Error())
 }
+
+func TestCheckValidOptionalPortNumber_ZeroOk(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyGrpcPort, 0)
+	viper.Set(configKeyGrpcPort, 0)
+
+	err := checkValidOptionalPortNumber(configKeyGrpcPort)
+	require.Nil(t, err)
+}
+
+func TestCheckValidOptionalPortNumber_TooLow(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyGrpcPort, 0)
+	viper.Set(configKeyGrpcPort, 443)
+
+	err := checkValidOptionalPortNumber(configKeyGrpcPort)
+	expectedMessage := "Fatal error: configuration value for key grpc.port is not in range 1024..65535\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckValidDuration_Ok(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeySchedulerPollInterval, "90s")
+
+	err := checkValidDuration(configKeySchedulerPollInterval)
+	require.Nil(t, err)
+}
+
+func TestCheckValidDuration_Invalid(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeySchedulerPollInterval, "soon")
+
+	err := checkValidDuration(configKeySchedulerPollInterval)
+	expectedMessage := "Fatal error: configuration value for key scheduler.poll.interval is not a positive duration\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckOneOf_Ok(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeyWebhooksCloudEventsMode, "binary")
+
+	err := checkOneOf(configKeyWebhooksCloudEventsMode, "structured", "binary")
+	require.Nil(t, err)
+}
+
+func TestCheckOneOf_Invalid(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeyWebhooksCloudEventsMode, "batched")
+
+	err := checkOneOf(configKeyWebhooksCloudEventsMode, "structured", "binary")
+	expectedMessage := "Fatal error: configuration value for key webhooks.cloudevents.mode must be one of structured, binary\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckCidrList_Ok(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeyServerRequestIdTrustedNetworks, "10.0.0.0/8, ::1/128")
+
+	err := checkCidrList(configKeyServerRequestIdTrustedNetworks)
+	require.Nil(t, err)
+	require.Len(t, ServerRequestIdTrustedNetworks(), 2)
+}
+
+func TestCheckCidrList_Invalid(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeyServerRequestIdTrustedNetworks, "10.0.0.0/8,10.0.0.1")
+
+	err := checkCidrList(configKeyServerRequestIdTrustedNetworks)
+	expectedMessage := "Fatal error: configuration value for key server.requestid.trusted.networks contains 10.0.0.1, which is not a network in CIDR notation\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckLogLevel_Invalid(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeyLoggingLevel, "verbose")
+
+	err := checkLogLevel(configKeyLoggingLevel)
+	expectedMessage := "Fatal error: configuration value for key logging.level is not a log level\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckLogLevelOverrides_Ok(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeyLoggingPackages, "emailsrv=debug, requestlogging = warn")
+
+	err := checkLogLevelOverrides(configKeyLoggingPackages)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"emailsrv": "debug", "requestlogging": "warn"}, LoggingPackageLevels())
+}
+
+func TestCheckLogLevelOverrides_Invalid(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeyLoggingPackages, "emailsrv=debug,webhooksrv")
+
+	err := checkLogLevelOverrides(configKeyLoggingPackages)
+	expectedMessage := "Fatal error: configuration value for key logging.packages contains webhooksrv, which is not of the form package=level\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestRedactionSettings_ShouldUseProfileOverrides(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set("profiles", []string{"local"})
+	defer viper.Set("profiles", []string{})
+	viper.Set(configKeyLoggingRedactionProfiles+".local.mode", "none")
+	viper.Set(configKeyLoggingRedactionProfiles+".staging.deny", "to")
+
+	require.Nil(t, checkRedactionMode(configKeyLoggingRedactionMode))
+	require.Equal(t, "none", LoggingRedactionMode())
+	require.Equal(t, []string{"subject"}, LoggingRedactionDeny())
+}
+
+func TestCheckRedactionMode_InvalidProfileOverride(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set("profiles", []string{"local"})
+	defer viper.Set("profiles", []string{})
+	viper.Set(configKeyLoggingRedactionProfiles+".local.mode", "scramble")
+
+	err := checkRedactionMode(configKeyLoggingRedactionMode)
+	expectedMessage := "Fatal error: configuration value for key logging.redaction.mode or its profile override must be one of mask, hash, none\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCorsOrigins_ShouldUseProfileOverrides(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set("profiles", []string{"staging"})
+	defer viper.Set("profiles", []string{})
+	defer viper.Set(configKeyCorsOrigins, "")
+	viper.Set(configKeyCorsOrigins, "https://admin.example.com")
+	defer viper.Set(configKeyCorsProfiles+".staging.origins", nil)
+	viper.Set(configKeyCorsProfiles+".staging.origins", "https://admin.staging.example.com, http://localhost:3000")
+
+	require.Nil(t, checkCorsOrigins(configKeyCorsOrigins))
+	require.Equal(t, []string{"https://admin.staging.example.com", "http://localhost:3000"}, CorsOrigins())
+}
+
+func TestCheckCorsOrigins_ShouldRejectPaths(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyCorsOrigins, "")
+	viper.Set(configKeyCorsOrigins, "https://admin.example.com/app")
+
+	err := checkCorsOrigins(configKeyCorsOrigins)
+	expectedMessage := "Fatal error: configuration value for key cors.origins or its profile override must be * or a comma separated list of origins such as https://admin.example.com\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckCorsOrigins_ShouldRejectWildcardWithCredentials(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyCorsOrigins, "")
+	viper.Set(configKeyCorsOrigins, "*")
+	defer viper.Set(configKeyCorsCredentials, false)
+	viper.Set(configKeyCorsCredentials, true)
+
+	err := checkCorsOrigins(configKeyCorsOrigins)
+	expectedMessage := "Fatal error: configuration value for key cors.origins or its profile override must not be * while cors.credentials is true\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckHttpTokens_ShouldRejectInvalidHeaderNames(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyCorsHeaders, "Accept,Authorization,Content-Type,X-API-Key,X-Request-Id")
+	viper.Set(configKeyCorsHeaders, "Authorization, X Request Id")
+
+	err := checkHttpTokens(configKeyCorsHeaders)
+	expectedMessage := "Fatal error: configuration value for key cors.headers must be a comma separated list of http methods or header names\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckTlsFiles_ShouldRequireCertAndKeyTogether(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyServerTlsCert, "")
+	viper.Set(configKeyServerTlsCert, "/etc/tls/tls.crt")
+
+	err := checkTlsFiles(configKeyServerTlsCert)
+	expectedMessage := "Fatal error: configuration values for keys server.tls.cert and server.tls.key must either both be set or both be blank\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckTlsFiles_ClientCaShouldRequireCert(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyServerTlsClientCa, "")
+	viper.Set(configKeyServerTlsClientCa, "/etc/tls/ca.crt")
+
+	err := checkTlsFiles(configKeyServerTlsCert)
+	expectedMessage := "Fatal error: configuration value for key server.tls.client.ca requires server.tls.cert to be set\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckAuditKey_ShouldBeRequiredWithFile(t *testing.T) {
+	tstSetup("", 8080)
+	require.Nil(t, checkAuditKey(configKeyAuditKey))
+
+	defer viper.Set(configKeyAuditFile, "")
+	viper.Set(configKeyAuditFile, "/var/lib/mailer-service/audit.jsonl")
+	err := checkAuditKey(configKeyAuditKey)
+	expectedMessage := "Fatal error: configuration value for key audit.key is required when audit.file is set\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckAuditKey_TooShort(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyAuditKey, "")
+	viper.Set(configKeyAuditKey, "short")
+
+	err := checkAuditKey(configKeyAuditKey)
+	expectedMessage := "Fatal error: configuration value for key audit.key must be at least 32 characters long\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckAuditRecipients_ShouldRefuseClear(t *testing.T) {
+	tstSetup("", 8080)
+	require.Nil(t, checkAuditRecipients(configKeyAuditRecipients))
+
+	defer viper.Set(configKeyAuditRecipients, "hashed")
+	viper.Set(configKeyAuditRecipients, "clear")
+	err := checkAuditRecipients(configKeyAuditRecipients)
+	expectedMessage := "Fatal error: configuration value for key audit.recipients cannot be clear while retention is on, erased addresses would stay in the audit log, use hashed\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckApiKeys_InvalidHash(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeySecurityApiKeys, map[string]interface{}{})
+	viper.Set(configKeySecurityApiKeys, map[string]interface{}{
+		"crm": map[string]interface{}{"hash": "the-plain-key", "roles": "admin"},
+	})
+
+	err := checkApiKeys(configKeySecurityApiKeys)
+	expectedMessage := "Fatal error: configuration value for key security.apikeys.crm.hash must be sha256: followed by 64 lower case hex digits\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckApiKeys_InvalidExpiry(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeySecurityApiKeys, map[string]interface{}{})
+	viper.Set(configKeySecurityApiKeys, map[string]interface{}{
+		"crm": map[string]interface{}{"hash": "sha256:" + strings.Repeat("ab", 32), "expires": "next week"},
+	})
+
+	err := checkApiKeys(configKeySecurityApiKeys)
+	expectedMessage := "Fatal error: configuration value for key security.apikeys.crm.expires is not an RFC 3339 timestamp\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
+
+func TestCheckValidTimestamp_Ok(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyApiV1Sunset, "")
+	viper.Set(configKeyApiV1Sunset, "2027-04-01T00:00:00Z")
+
+	err := checkValidTimestamp(configKeyApiV1Sunset)
+	require.Nil(t, err)
+}
+
+func TestCheckValidTimestamp_BlankOk(t *testing.T) {
+	tstSetup("", 8080)
+	viper.Set(configKeyApiV1Sunset, "")
+
+	err := checkValidTimestamp(configKeyApiV1Sunset)
+	require.Nil(t, err)
+}
+
+func TestCheckValidTimestamp_Invalid(t *testing.T) {
+	tstSetup("", 8080)
+	defer viper.Set(configKeyApiV1Sunset, "")
+	viper.Set(configKeyApiV1Sunset, "next spring")
+
+	err := checkValidTimestamp(configKeyApiV1Sunset)
+	expectedMessage := "Fatal error: configuration value for key api.v1.sunset is not an RFC 3339 timestamp\n"
+	require.NotNil(t, err)
+	require.Equal(t, expectedMessage, err.Error())
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/filedb"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/inmemorydb"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/health"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+var (
+	ActiveRepository dbrepo.Repository
+)
+
+func Open() {
+	var r dbrepo.Repository
+	if directory := configuration.DatabaseDirectory(); directory != "" {
+		log.Info().Msgf("Opening file database in directory %s...", directory)
+		r = filedb.Create(directory)
+	} else {
+		log.Info().Msg("Opening in-memory database, stored emails will not survive a restart...")
+		r = inmemorydb.Create()
+	}
+	err := r.Open()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to open database")
+	}
+	ActiveRepository = r
+	health.Register("storage", 2*time.Second, r.Ping)
+}
+
+func Close() {
+	log.Info().Msg("Closing database...")
+	GetRepository().Close()
+	ActiveRepository = nil
+}
+
+func GetRepository() dbrepo.Repository {
+	return ActiveRepository
+}
@@ -0,0 +1,55 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"time"
+)
+
+var ErrNotFound = errors.New("not found in database")
+
+// the delivery log of a webhook subscription only keeps this many of the most recent entries
+const MaxWebhookDeliveriesPerSubscription = 100
+
+type Repository interface {
+	Open() error
+	Close()
+	// Ping verifies the storage is usable, e.g. that it can be written to.
+	Ping(ctx context.Context) error
+
+	// AddEmail stores a new email. Its ID must already be set.
+	AddEmail(ctx context.Context, email *entity.Email) error
+	UpdateEmail(ctx context.Context, email *entity.Email) error
+	// GetEmail returns a copy, changes only take effect after UpdateEmail.
+	GetEmail(ctx context.Context, id string) (*entity.Email, error)
+	// FindDueEmails returns copies of all scheduled emails whose send time is not after due.
+	FindDueEmails(ctx context.Context, due time.Time) ([]*entity.Email, error)
+	// FindEmailsCreatedBefore returns copies of all emails created before the given time, oldest first.
+	FindEmailsCreatedBefore(ctx context.Context, before time.Time) ([]*entity.Email, error)
+	// FindEmailsByAddress returns copies of all emails to an address, compared case insensitively, oldest first.
+	FindEmailsByAddress(ctx context.Context, address string) ([]*entity.Email, error)
+	// ListEmails returns copies of up to limit emails ordered by creation time and id, starting after the email
+	// that was created at afterCreatedAt and has afterId. Zero values start with the oldest email.
+	ListEmails(ctx context.Context, afterCreatedAt time.Time, afterId string, limit int) ([]*entity.Email, error)
+	DeleteEmail(ctx context.Context, id string) error
+
+	// AddSuppression stores a suppressed address, replacing any previous entry for the same address.
+	AddSuppression(ctx context.Context, suppression *entity.Suppression) error
+	// GetSuppression looks up an address case insensitively.
+	GetSuppression(ctx context.Context, address string) (*entity.Suppression, error)
+	DeleteSuppression(ctx context.Context, address string) error
+
+	AddWebhookSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error
+	UpdateWebhookSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error
+	GetWebhookSubscription(ctx context.Context, id string) (*entity.WebhookSubscription, error)
+	// DeleteWebhookSubscription also deletes its delivery log.
+	DeleteWebhookSubscription(ctx context.Context, id string) error
+	// ListWebhookSubscriptions returns copies ordered by creation time.
+	ListWebhookSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error)
+
+	// AddWebhookDelivery appends to the delivery log of a subscription.
+	AddWebhookDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	// ListWebhookDeliveries returns copies of the delivery log of a subscription, oldest first.
+	ListWebhookDeliveries(ctx context.Context, subscriptionId string) ([]*entity.WebhookDelivery, error)
+}
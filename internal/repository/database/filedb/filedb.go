@@ -0,0 +1,307 @@
+package filedb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/inmemorydb"
+	"github.com/rs/zerolog/log"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const emailSubdirectory = "emails"
+const suppressionSubdirectory = "suppressions"
+const webhookSubdirectory = "webhooks"
+const webhookDeliverySubdirectory = "webhook-deliveries"
+
+// FileRepository keeps one json file per record in a directory, so stored data survives restarts.
+//
+// All reads are served from an in-memory copy that is loaded on Open.
+type FileRepository struct {
+	directory string
+	mu        sync.Mutex
+	cache     dbrepo.Repository
+}
+
+func Create(directory string) dbrepo.Repository {
+	return &FileRepository{
+		directory: directory,
+		cache:     inmemorydb.Create(),
+	}
+}
+
+func (r *FileRepository) Open() error {
+	if err := r.cache.Open(); err != nil {
+		return err
+	}
+
+	count, err := r.loadAll(emailSubdirectory, func() interface{} { return &entity.Email{} }, func(record interface{}) error {
+		return r.cache.AddEmail(context.Background(), record.(*entity.Email))
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("loaded %d stored emails from %s", count, r.directory)
+
+	count, err = r.loadAll(suppressionSubdirectory, func() interface{} { return &entity.Suppression{} }, func(record interface{}) error {
+		return r.cache.AddSuppression(context.Background(), record.(*entity.Suppression))
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("loaded %d suppressed addresses from %s", count, r.directory)
+
+	count, err = r.loadAll(webhookSubdirectory, func() interface{} { return &entity.WebhookSubscription{} }, func(record interface{}) error {
+		return r.cache.AddWebhookSubscription(context.Background(), record.(*entity.WebhookSubscription))
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("loaded %d webhook subscriptions from %s", count, r.directory)
+
+	// one file per subscription holding its whole delivery log
+	_, err = r.loadAll(webhookDeliverySubdirectory, func() interface{} { return &[]*entity.WebhookDelivery{} }, func(record interface{}) error {
+		for _, delivery := range *record.(*[]*entity.WebhookDelivery) {
+			if err := r.cache.AddWebhookDelivery(context.Background(), delivery); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *FileRepository) Close() {
+	r.cache.Close()
+}
+
+// Ping writes and removes a probe file, so a full disk or a read-only mount is noticed.
+func (r *FileRepository) Ping(ctx context.Context) error {
+	if err := r.cache.Ping(ctx); err != nil {
+		return err
+	}
+	probe, err := ioutil.TempFile(r.directory, ".ping-")
+	if err != nil {
+		return err
+	}
+	_, err = probe.Write([]byte("ping"))
+	if closeErr := probe.Close(); err == nil {
+		err = closeErr
+	}
+	if removeErr := os.Remove(probe.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+func (r *FileRepository) AddEmail(ctx context.Context, email *entity.Email) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.cache.GetEmail(ctx, email.ID); err == nil {
+		return fmt.Errorf("cannot add email %s - already present", email.ID)
+	}
+	if err := r.writeFile(emailSubdirectory, email.ID, email); err != nil {
+		return err
+	}
+	return r.cache.AddEmail(ctx, email)
+}
+
+func (r *FileRepository) UpdateEmail(ctx context.Context, email *entity.Email) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.cache.GetEmail(ctx, email.ID); err != nil {
+		return fmt.Errorf("cannot update email %s: %w", email.ID, dbrepo.ErrNotFound)
+	}
+	if err := r.writeFile(emailSubdirectory, email.ID, email); err != nil {
+		return err
+	}
+	return r.cache.UpdateEmail(ctx, email)
+}
+
+func (r *FileRepository) GetEmail(ctx context.Context, id string) (*entity.Email, error) {
+	return r.cache.GetEmail(ctx, id)
+}
+
+func (r *FileRepository) FindDueEmails(ctx context.Context, due time.Time) ([]*entity.Email, error) {
+	return r.cache.FindDueEmails(ctx, due)
+}
+
+func (r *FileRepository) FindEmailsCreatedBefore(ctx context.Context, before time.Time) ([]*entity.Email, error) {
+	return r.cache.FindEmailsCreatedBefore(ctx, before)
+}
+
+func (r *FileRepository) FindEmailsByAddress(ctx context.Context, address string) ([]*entity.Email, error) {
+	return r.cache.FindEmailsByAddress(ctx, address)
+}
+
+func (r *FileRepository) ListEmails(ctx context.Context, afterCreatedAt time.Time, afterId string, limit int) ([]*entity.Email, error) {
+	return r.cache.ListEmails(ctx, afterCreatedAt, afterId, limit)
+}
+
+func (r *FileRepository) DeleteEmail(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.cache.DeleteEmail(ctx, id); err != nil {
+		return err
+	}
+	return r.removeFile(emailSubdirectory, id)
+}
+
+func (r *FileRepository) AddSuppression(ctx context.Context, suppression *entity.Suppression) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.writeFile(suppressionSubdirectory, addressFileName(suppression.Address), suppression); err != nil {
+		return err
+	}
+	return r.cache.AddSuppression(ctx, suppression)
+}
+
+func (r *FileRepository) GetSuppression(ctx context.Context, address string) (*entity.Suppression, error) {
+	return r.cache.GetSuppression(ctx, address)
+}
+
+func (r *FileRepository) DeleteSuppression(ctx context.Context, address string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.cache.DeleteSuppression(ctx, address); err != nil {
+		return err
+	}
+	return r.removeFile(suppressionSubdirectory, addressFileName(address))
+}
+
+func (r *FileRepository) AddWebhookSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.cache.GetWebhookSubscription(ctx, subscription.ID); err == nil {
+		return fmt.Errorf("cannot add webhook subscription %s - already present", subscription.ID)
+	}
+	if err := r.writeFile(webhookSubdirectory, subscription.ID, subscription); err != nil {
+		return err
+	}
+	return r.cache.AddWebhookSubscription(ctx, subscription)
+}
+
+func (r *FileRepository) UpdateWebhookSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.cache.GetWebhookSubscription(ctx, subscription.ID); err != nil {
+		return fmt.Errorf("cannot update webhook subscription %s: %w", subscription.ID, dbrepo.ErrNotFound)
+	}
+	if err := r.writeFile(webhookSubdirectory, subscription.ID, subscription); err != nil {
+		return err
+	}
+	return r.cache.UpdateWebhookSubscription(ctx, subscription)
+}
+
+func (r *FileRepository) GetWebhookSubscription(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	return r.cache.GetWebhookSubscription(ctx, id)
+}
+
+func (r *FileRepository) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.cache.DeleteWebhookSubscription(ctx, id); err != nil {
+		return err
+	}
+	if err := r.removeFile(webhookDeliverySubdirectory, id); err != nil {
+		return err
+	}
+	return r.removeFile(webhookSubdirectory, id)
+}
+
+func (r *FileRepository) ListWebhookSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	return r.cache.ListWebhookSubscriptions(ctx)
+}
+
+func (r *FileRepository) AddWebhookDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.cache.AddWebhookDelivery(ctx, delivery); err != nil {
+		return err
+	}
+	// the cache has already cut the log down to size
+	deliveries, err := r.cache.ListWebhookDeliveries(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	return r.writeFile(webhookDeliverySubdirectory, delivery.SubscriptionID, deliveries)
+}
+
+func (r *FileRepository) ListWebhookDeliveries(ctx context.Context, subscriptionId string) ([]*entity.WebhookDelivery, error) {
+	return r.cache.ListWebhookDeliveries(ctx, subscriptionId)
+}
+
+// --- file handling ---
+
+// addresses are not safe to use as file names, and we would rather not have them in directory listings either
+func addressFileName(address string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(address)))
+	return hex.EncodeToString(hash[:])
+}
+
+func (r *FileRepository) fileName(subdirectory string, id string) string {
+	return filepath.Join(r.directory, subdirectory, filepath.Base(id)+".json")
+}
+
+func (r *FileRepository) loadAll(subdirectory string, create func() interface{}, add func(interface{}) error) (int, error) {
+	directory := filepath.Join(r.directory, subdirectory)
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return 0, err
+	}
+
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		fileName := filepath.Join(directory, file.Name())
+		content, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return count, err
+		}
+		record := create()
+		if err := json.Unmarshal(content, record); err != nil {
+			return count, fmt.Errorf("cannot parse stored record %s: %v", fileName, err)
+		}
+		if err := add(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// write to a temporary file first and then rename, so a crash never leaves a half written record behind
+func (r *FileRepository) writeFile(subdirectory string, id string, record interface{}) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	fileName := r.fileName(subdirectory, id)
+	tempFileName := fileName + ".tmp"
+	if err := ioutil.WriteFile(tempFileName, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tempFileName, fileName)
+}
+
+func (r *FileRepository) removeFile(subdirectory string, id string) error {
+	err := os.Remove(r.fileName(subdirectory, id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
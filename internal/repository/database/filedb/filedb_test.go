@@ -0,0 +1,80 @@
+package filedb
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFileRepository_ShouldSurviveReopen(t *testing.T) {
+	directory, err := ioutil.TempDir("", "filedb")
+	require.Nil(t, err)
+	defer os.RemoveAll(directory)
+	ctx := context.Background()
+
+	sendAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	cut := Create(directory)
+	require.Nil(t, cut.Open())
+	require.Nil(t, cut.AddEmail(ctx, &entity.Email{ID: "abc", ToAddress: "someone@example.com", SendAt: sendAt, Status: entity.EmailStatusScheduled}))
+	cut.Close()
+
+	reopened := Create(directory)
+	require.Nil(t, reopened.Open())
+	defer reopened.Close()
+
+	email, err := reopened.GetEmail(ctx, "abc")
+	require.Nil(t, err)
+	require.Equal(t, "someone@example.com", email.ToAddress)
+	require.True(t, sendAt.Equal(email.SendAt))
+
+	due, err := reopened.FindDueEmails(ctx, sendAt)
+	require.Nil(t, err)
+	require.Len(t, due, 1)
+}
+
+func TestFileRepository_PingShouldLeaveNoTrace(t *testing.T) {
+	directory, err := ioutil.TempDir("", "filedb")
+	require.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	cut := Create(directory)
+	require.Nil(t, cut.Open())
+	require.Nil(t, cut.Ping(context.Background()))
+	entries, err := ioutil.ReadDir(directory)
+	require.Nil(t, err)
+	for _, entry := range entries {
+		require.True(t, entry.IsDir(), "unexpected file %s", entry.Name())
+	}
+
+	cut.Close()
+	require.NotNil(t, cut.Ping(context.Background()))
+}
+
+func TestFileRepository_DeletesShouldSurviveReopen(t *testing.T) {
+	directory, err := ioutil.TempDir("", "filedb")
+	require.Nil(t, err)
+	defer os.RemoveAll(directory)
+	ctx := context.Background()
+
+	cut := Create(directory)
+	require.Nil(t, cut.Open())
+	require.Nil(t, cut.AddEmail(ctx, &entity.Email{ID: "abc", ToAddress: "someone@example.com", Status: entity.EmailStatusSent}))
+	require.Nil(t, cut.AddSuppression(ctx, &entity.Suppression{Address: "Someone@Example.com"}))
+	require.Nil(t, cut.DeleteEmail(ctx, "abc"))
+	require.Nil(t, cut.DeleteSuppression(ctx, "someone@example.com"))
+	cut.Close()
+
+	reopened := Create(directory)
+	require.Nil(t, reopened.Open())
+	defer reopened.Close()
+
+	emails, err := reopened.FindEmailsByAddress(ctx, "someone@example.com")
+	require.Nil(t, err)
+	require.Empty(t, emails)
+	_, err = reopened.GetSuppression(ctx, "someone@example.com")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,285 @@
+package inmemorydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"net/mail"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type InMemoryRepository struct {
+	mu           sync.RWMutex
+	emails       map[string]*entity.Email
+	suppressions map[string]*entity.Suppression
+	webhooks     map[string]*entity.WebhookSubscription
+	deliveries   map[string][]*entity.WebhookDelivery
+}
+
+func Create() dbrepo.Repository {
+	return &InMemoryRepository{}
+}
+
+func (r *InMemoryRepository) Open() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emails = make(map[string]*entity.Email)
+	r.suppressions = make(map[string]*entity.Suppression)
+	r.webhooks = make(map[string]*entity.WebhookSubscription)
+	r.deliveries = make(map[string][]*entity.WebhookDelivery)
+	return nil
+}
+
+func (r *InMemoryRepository) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emails = nil
+	r.suppressions = nil
+	r.webhooks = nil
+	r.deliveries = nil
+}
+
+func (r *InMemoryRepository) Ping(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.emails == nil {
+		return errors.New("database is closed")
+	}
+	return nil
+}
+
+func (r *InMemoryRepository) AddEmail(ctx context.Context, email *entity.Email) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.emails[email.ID]; ok {
+		return fmt.Errorf("cannot add email %s - already present", email.ID)
+	}
+	copied := *email
+	r.emails[email.ID] = &copied
+	return nil
+}
+
+func (r *InMemoryRepository) UpdateEmail(ctx context.Context, email *entity.Email) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.emails[email.ID]; !ok {
+		return fmt.Errorf("cannot update email %s: %w", email.ID, dbrepo.ErrNotFound)
+	}
+	copied := *email
+	r.emails[email.ID] = &copied
+	return nil
+}
+
+func (r *InMemoryRepository) GetEmail(ctx context.Context, id string) (*entity.Email, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	email, ok := r.emails[id]
+	if !ok {
+		return nil, fmt.Errorf("cannot get email %s: %w", id, dbrepo.ErrNotFound)
+	}
+	copied := *email
+	return &copied, nil
+}
+
+func (r *InMemoryRepository) FindDueEmails(ctx context.Context, due time.Time) ([]*entity.Email, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*entity.Email, 0)
+	for _, email := range r.emails {
+		if email.Status == entity.EmailStatusScheduled && !email.SendAt.After(due) {
+			copied := *email
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].SendAt.Before(result[j].SendAt) })
+	return result, nil
+}
+
+func (r *InMemoryRepository) FindEmailsCreatedBefore(ctx context.Context, before time.Time) ([]*entity.Email, error) {
+	return r.findEmails(func(email *entity.Email) bool { return email.CreatedAt.Before(before) }), nil
+}
+
+func (r *InMemoryRepository) FindEmailsByAddress(ctx context.Context, address string) ([]*entity.Email, error) {
+	return r.findEmails(func(email *entity.Email) bool { return isAddressedTo(email, address) }), nil
+}
+
+// isAddressedTo also looks into address lists and display name forms such as "Jane <jane@example.com>"
+func isAddressedTo(email *entity.Email, address string) bool {
+	if strings.EqualFold(email.ToAddress, address) {
+		return true
+	}
+	recipients, err := mail.ParseAddressList(email.ToAddress)
+	if err != nil {
+		return false
+	}
+	for _, recipient := range recipients {
+		if strings.EqualFold(recipient.Address, address) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemoryRepository) findEmails(matches func(*entity.Email) bool) []*entity.Email {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*entity.Email, 0)
+	for _, email := range r.emails {
+		if matches(email) {
+			copied := *email
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+func (r *InMemoryRepository) ListEmails(ctx context.Context, afterCreatedAt time.Time, afterId string, limit int) ([]*entity.Email, error) {
+	isAfter := func(email *entity.Email) bool {
+		return email.CreatedAt.After(afterCreatedAt) || (email.CreatedAt.Equal(afterCreatedAt) && email.ID > afterId)
+	}
+	result := r.findEmails(isAfter)
+	// emails created in the same instant must still come in a stable order
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *InMemoryRepository) DeleteEmail(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.emails[id]; !ok {
+		return fmt.Errorf("cannot delete email %s: %w", id, dbrepo.ErrNotFound)
+	}
+	delete(r.emails, id)
+	return nil
+}
+
+func (r *InMemoryRepository) AddSuppression(ctx context.Context, suppression *entity.Suppression) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *suppression
+	copied.Address = strings.ToLower(suppression.Address)
+	r.suppressions[copied.Address] = &copied
+	return nil
+}
+
+func (r *InMemoryRepository) GetSuppression(ctx context.Context, address string) (*entity.Suppression, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	suppression, ok := r.suppressions[strings.ToLower(address)]
+	if !ok {
+		return nil, fmt.Errorf("cannot get suppression for address: %w", dbrepo.ErrNotFound)
+	}
+	copied := *suppression
+	return &copied, nil
+}
+
+func (r *InMemoryRepository) DeleteSuppression(ctx context.Context, address string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.suppressions[strings.ToLower(address)]; !ok {
+		return fmt.Errorf("cannot delete suppression for address: %w", dbrepo.ErrNotFound)
+	}
+	delete(r.suppressions, strings.ToLower(address))
+	return nil
+}
+
+func (r *InMemoryRepository) AddWebhookSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[subscription.ID]; ok {
+		return fmt.Errorf("cannot add webhook subscription %s - already present", subscription.ID)
+	}
+	r.webhooks[subscription.ID] = copyWebhookSubscription(subscription)
+	return nil
+}
+
+func (r *InMemoryRepository) UpdateWebhookSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[subscription.ID]; !ok {
+		return fmt.Errorf("cannot update webhook subscription %s: %w", subscription.ID, dbrepo.ErrNotFound)
+	}
+	r.webhooks[subscription.ID] = copyWebhookSubscription(subscription)
+	return nil
+}
+
+func (r *InMemoryRepository) GetWebhookSubscription(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subscription, ok := r.webhooks[id]
+	if !ok {
+		return nil, fmt.Errorf("cannot get webhook subscription %s: %w", id, dbrepo.ErrNotFound)
+	}
+	return copyWebhookSubscription(subscription), nil
+}
+
+func (r *InMemoryRepository) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[id]; !ok {
+		return fmt.Errorf("cannot delete webhook subscription %s: %w", id, dbrepo.ErrNotFound)
+	}
+	delete(r.webhooks, id)
+	delete(r.deliveries, id)
+	return nil
+}
+
+func (r *InMemoryRepository) ListWebhookSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*entity.WebhookSubscription, 0, len(r.webhooks))
+	for _, subscription := range r.webhooks {
+		result = append(result, copyWebhookSubscription(subscription))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (r *InMemoryRepository) AddWebhookDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[delivery.SubscriptionID]; !ok {
+		return fmt.Errorf("cannot log delivery for webhook subscription %s: %w", delivery.SubscriptionID, dbrepo.ErrNotFound)
+	}
+	copied := *delivery
+	entries := append(r.deliveries[delivery.SubscriptionID], &copied)
+	if len(entries) > dbrepo.MaxWebhookDeliveriesPerSubscription {
+		entries = entries[len(entries)-dbrepo.MaxWebhookDeliveriesPerSubscription:]
+	}
+	r.deliveries[delivery.SubscriptionID] = entries
+	return nil
+}
+
+func (r *InMemoryRepository) ListWebhookDeliveries(ctx context.Context, subscriptionId string) ([]*entity.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.webhooks[subscriptionId]; !ok {
+		return nil, fmt.Errorf("cannot list deliveries for webhook subscription %s: %w", subscriptionId, dbrepo.ErrNotFound)
+	}
+	result := make([]*entity.WebhookDelivery, 0, len(r.deliveries[subscriptionId]))
+	for _, delivery := range r.deliveries[subscriptionId] {
+		copied := *delivery
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func copyWebhookSubscription(subscription *entity.WebhookSubscription) *entity.WebhookSubscription {
+	copied := *subscription
+	copied.EventTypes = append([]entity.EventType{}, subscription.EventTypes...)
+	return &copied
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "UP"
+	StatusDown Status = "DOWN"
+)
+
+// CheckFunc returns nil if the component is usable. It should give up when ctx is done.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	timeout time.Duration
+	run     CheckFunc
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name     string
+	Status   Status
+	Error    string
+	Duration time.Duration
+}
+
+// Report aggregates all results, it is only up if every check is.
+type Report struct {
+	Status  Status
+	Results []Result
+}
+
+var (
+	mu     sync.RWMutex
+	checks = make(map[string]check)
+)
+
+// Register adds a readiness check for a component. Registering the same name again replaces the check.
+func Register(name string, timeout time.Duration, run CheckFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = check{timeout: timeout, run: run}
+}
+
+// use this in tests to start from a clean slate
+func ResetForTesting() {
+	mu.Lock()
+	defer mu.Unlock()
+	checks = make(map[string]check)
+}
+
+// CheckAll runs all registered checks in parallel, each limited by its own timeout.
+//
+// Results are sorted by name.
+func CheckAll(ctx context.Context) *Report {
+	mu.RLock()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	toRun := make([]check, len(names))
+	for i, name := range names {
+		toRun[i] = checks[name]
+	}
+	mu.RUnlock()
+
+	report := &Report{Status: StatusUp, Results: make([]Result, len(names))}
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			report.Results[i] = runCheck(ctx, names[i], toRun[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range report.Results {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, name string, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	// buffered, so a check that ignores its context does not leak the goroutine forever once it returns
+	finished := make(chan error, 1)
+	go func() {
+		finished <- c.run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-finished:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %v", c.timeout)
+	}
+
+	result := Result{Name: name, Status: StatusUp, Duration: time.Since(start)}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
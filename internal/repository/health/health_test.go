@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCheckAll_AllUp(t *testing.T) {
+	ResetForTesting()
+	Register("b", time.Second, func(ctx context.Context) error { return nil })
+	Register("a", time.Second, func(ctx context.Context) error { return nil })
+
+	report := CheckAll(context.Background())
+	require.Equal(t, StatusUp, report.Status)
+	require.Len(t, report.Results, 2)
+	require.Equal(t, "a", report.Results[0].Name)
+	require.Equal(t, "b", report.Results[1].Name)
+}
+
+func TestCheckAll_FailureAndTimeout(t *testing.T) {
+	ResetForTesting()
+	Register("broken", time.Second, func(ctx context.Context) error { return errors.New("connection refused") })
+	Register("hanging", 10*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	Register("ok", time.Second, func(ctx context.Context) error { return nil })
+
+	start := time.Now()
+	report := CheckAll(context.Background())
+	require.True(t, time.Since(start) < 500*time.Millisecond)
+
+	require.Equal(t, StatusDown, report.Status)
+	require.Equal(t, Result{Name: "broken", Status: StatusDown, Error: "connection refused", Duration: report.Results[0].Duration}, report.Results[0])
+	require.Equal(t, StatusDown, report.Results[1].Status)
+	require.Equal(t, "timed out after 10ms", report.Results[1].Error)
+	require.Equal(t, StatusUp, report.Results[2].Status)
+}
+
+func TestRegister_SameNameReplaces(t *testing.T) {
+	ResetForTesting()
+	Register("a", time.Second, func(ctx context.Context) error { return errors.New("down") })
+	Register("a", time.Second, func(ctx context.Context) error { return nil })
+
+	report := CheckAll(context.Background())
+	require.Equal(t, StatusUp, report.Status)
+	require.Len(t, report.Results, 1)
+}
@@ -0,0 +1,36 @@
+package identity
+
+import "context"
+
+// role required for administrative operations
+const RoleAdmin = "admin"
+
+// Caller is whoever triggered the current operation, as far as the service layer needs to know.
+type Caller struct {
+	// the subject of the jwt token, or a technical name for callers that are not users (e.g. kafka:<topic>)
+	Subject string
+	Roles   []string
+}
+
+func (c Caller) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type callerKeyType struct{}
+
+var callerKey = callerKeyType{}
+
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey, caller)
+}
+
+// CallerOf returns the caller of the current operation, or the zero Caller if it is anonymous (e.g. the scheduler).
+func CallerOf(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey).(Caller)
+	return caller
+}
@@ -0,0 +1,182 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RootLoggerName is the name under which the global log level is exposed.
+const RootLoggerName = "root"
+
+var ErrUnknownLogger = errors.New("unknown logger")
+
+// LoggerLevel is the level of the root logger or of a package with its own level.
+type LoggerLevel struct {
+	Name  string
+	Level string
+}
+
+var (
+	levelsMu  sync.RWMutex
+	rootLevel = zerolog.InfoLevel
+	// package name or path suffix, e.g. emailsrv or service/emailsrv, to level
+	packageLevels = map[string]zerolog.Level{}
+)
+
+// configureLevels sets the root and package levels from configuration, which has already validated them.
+func configureLevels(root string, packages map[string]string) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	rootLevel = parseLevelOrInfo(root)
+	packageLevels = map[string]zerolog.Level{}
+	for name, level := range packages {
+		packageLevels[name] = parseLevelOrInfo(level)
+	}
+	applyLevels()
+}
+
+func parseLevelOrInfo(level string) zerolog.Level {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil || parsed == zerolog.NoLevel {
+		return zerolog.InfoLevel
+	}
+	return parsed
+}
+
+// applyLevels lowers the zerolog global level far enough for every package, the hook filters the rest.
+//
+// Must be called with levelsMu held.
+func applyLevels() {
+	minimum := rootLevel
+	for _, level := range packageLevels {
+		if level < minimum {
+			minimum = level
+		}
+	}
+	zerolog.SetGlobalLevel(minimum)
+}
+
+// Level returns the current root log level, such as info.
+func Level() string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	return rootLevel.String()
+}
+
+// SetLevel changes the root log level at runtime. Returns an error for unknown level names.
+func SetLevel(level string) error {
+	return SetLoggerLevel(RootLoggerName, level)
+}
+
+// Loggers lists the root logger followed by all packages with their own level, sorted by name.
+func Loggers() []LoggerLevel {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	result := []LoggerLevel{}
+	for name, level := range packageLevels {
+		result = append(result, LoggerLevel{Name: name, Level: level.String()})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return append([]LoggerLevel{{Name: RootLoggerName, Level: rootLevel.String()}}, result...)
+}
+
+// SetLoggerLevel changes the level of the root logger or of a package that was configured with its own level.
+//
+// Returns ErrUnknownLogger for other names, or an error for unknown level names.
+func SetLoggerLevel(name string, level string) error {
+	previous, ok := currentLevel(name)
+	if !ok {
+		return ErrUnknownLogger
+	}
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil || parsed == zerolog.NoLevel {
+		return fmt.Errorf("unknown log level '%s'", level)
+	}
+	// log before the change, so this is not swallowed when raising the level
+	log.Warn().Msgf("changing log level of %s from %s to %s", name, previous.String(), parsed.String())
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	if name == RootLoggerName {
+		rootLevel = parsed
+	} else {
+		packageLevels[name] = parsed
+	}
+	applyLevels()
+	return nil
+}
+
+func currentLevel(name string) (zerolog.Level, bool) {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	if name == RootLoggerName {
+		return rootLevel, true
+	}
+	level, ok := packageLevels[name]
+	return level, ok
+}
+
+// AvailableLevels lists all valid arguments for SetLevel.
+func AvailableLevels() []string {
+	return []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}
+}
+
+// packageLevelHook discards events below the level of the package that logs them.
+type packageLevelHook struct{}
+
+func (h packageLevelHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level < effectiveLevel(callerPackage) {
+		e.Discard()
+	}
+}
+
+// effectiveLevel returns the level of the most specific matching package, or the root level.
+//
+// The package is only determined if there are package levels, because walking the stack is not free.
+func effectiveLevel(packageOf func() string) zerolog.Level {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	if len(packageLevels) == 0 {
+		return rootLevel
+	}
+	pkg := packageOf()
+	result, matched := rootLevel, ""
+	for name, level := range packageLevels {
+		if (pkg == name || strings.HasSuffix(pkg, "/"+name)) && len(name) > len(matched) {
+			result, matched = level, name
+		}
+	}
+	return result
+}
+
+// callerPackage returns the import path of the package that logged the event, skipping zerolog itself.
+func callerPackage() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/rs/zerolog") && !strings.HasSuffix(frame.Function, "logging.packageLevelHook.Run") {
+			return packageOfFunction(frame.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// packageOfFunction turns github.com/org/repo/pkg.(*Type).Method into github.com/org/repo/pkg
+func packageOfFunction(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[lastSlash+1:], ".")
+	if dot < 0 {
+		return function
+	}
+	return function[:lastSlash+1+dot]
+}
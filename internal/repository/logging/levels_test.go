@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func tstSetupLevels(root string, packages map[string]string) {
+	SetupForTesting()
+	RecordedLogForTesting.Reset()
+	configureLevels(root, packages)
+	log.Logger = log.Logger.Hook(packageLevelHook{})
+}
+
+func TestPackageLevels_ShouldOverrideRootLevel(t *testing.T) {
+	tstSetupLevels("warn", map[string]string{"logging": "debug"})
+
+	log.Debug().Msg("debug from this package")
+	require.Contains(t, RecordedLogForTesting.String(), "debug from this package")
+
+	tstSetupLevels("info", map[string]string{"emailsrv": "debug", "repository/logging": "error"})
+
+	log.Warn().Msg("warning from this package")
+	require.NotContains(t, RecordedLogForTesting.String(), "warning from this package")
+	log.Error().Msg("error from this package")
+	require.Contains(t, RecordedLogForTesting.String(), "error from this package")
+}
+
+func TestPackageLevels_ShouldFallBackToRootLevel(t *testing.T) {
+	tstSetupLevels("info", map[string]string{"emailsrv": "debug"})
+
+	log.Debug().Msg("debug from this package")
+	log.Info().Msg("info from this package")
+	require.NotContains(t, RecordedLogForTesting.String(), "debug from this package")
+	require.Contains(t, RecordedLogForTesting.String(), "info from this package")
+}
+
+func TestSetLoggerLevel_ShouldChangeConfiguredLoggersOnly(t *testing.T) {
+	tstSetupLevels("info", map[string]string{"emailsrv": "debug"})
+
+	require.Nil(t, SetLoggerLevel("emailsrv", "error"))
+	require.Nil(t, SetLevel("warn"))
+	require.Equal(t, ErrUnknownLogger, SetLoggerLevel("webhooksrv", "debug"))
+	require.NotNil(t, SetLoggerLevel("emailsrv", "verbose"))
+
+	require.Equal(t, []LoggerLevel{{Name: "root", Level: "warn"}, {Name: "emailsrv", Level: "error"}}, Loggers())
+	require.Equal(t, "warn", Level())
+}
+
+func TestPackageOfFunction(t *testing.T) {
+	require.Equal(t, "github.com/org/repo/pkg", packageOfFunction("github.com/org/repo/pkg.(*Type).Method"))
+	require.Equal(t, "github.com/org/repo/pkg", packageOfFunction("github.com/org/repo/pkg.func1"))
+	require.Equal(t, "main", packageOfFunction("main.main"))
+}
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"bytes"
+	"io"
 	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -9,32 +10,68 @@ import (
 )
 
 
+const (
+	FormatJson    = "json"
+	FormatConsole = "console"
+
+	// ECS version the json log format follows
+	EcsVersion = "1.6.0"
+
+	// ECS fields added to every log entry made during a request
+	FieldRequestId = "http.request.id"
+	FieldTraceId   = "trace.id"
+)
+
+// where log entries go, replaced by SetupForTesting
+var output io.Writer = os.Stdout
+
 func Setup() {
-	// configure to implement a small subset of ECS as an example
-	// see https://www.elastic.co/guide/en/ecs/1.4
+	// configure to implement ECS
+	// see https://www.elastic.co/guide/en/ecs/1.6
 
 	zerolog.TimestampFieldName = "@timestamp"
 	zerolog.LevelFieldName = "log.level"
 	zerolog.MessageFieldName = "message" // correct by default
+	zerolog.ErrorFieldName = "error.message"
+	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.000Z07:00"
 
 	// assume JSON logging at first, until configuration is loaded
-	log.Logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	log.Logger = zerolog.New(output).With().Timestamp().Logger()
 }
 
 func PostConfigSetup() {
-	if configuration.IsProfileActive("local") {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout})
-		log.Info().Msg("switching to developer friendly console log because profile 'local' is active")
+	redactor := NewRedactor(configuration.LoggingRedactionMode(), configuration.LoggingRedactionAllow(), configuration.LoggingRedactionDeny())
+	if Format() == FormatConsole {
+		// redact before formatting, the console writer parses the json entries
+		log.Logger = log.Output(&redactingWriter{redactor: redactor, next: zerolog.ConsoleWriter{Out: output}})
+		log.Info().Msg("switching to developer friendly console log")
 	} else {
-		// stay with JSON logging and add ECS service.id field
-		log.Logger = log.With().Str("service.id", configuration.ServiceName()).Logger()
+		log.Logger = log.Output(&redactingWriter{redactor: redactor, next: output})
+		// stay with JSON logging and add ECS service fields
+		log.Logger = log.With().
+			Str("service.name", configuration.ServiceName()).
+			Str("ecs.version", EcsVersion).
+			Logger()
+	}
+	configureLevels(configuration.LoggingLevel(), configuration.LoggingPackageLevels())
+	log.Logger = log.Logger.Hook(packageLevelHook{})
+}
+
+// Format returns the configured log format, console is the default for the local profile.
+func Format() string {
+	if format := configuration.LoggingFormat(); format != "" {
+		return format
+	}
+	if configuration.IsProfileActive("local") {
+		return FormatConsole
 	}
+	return FormatJson
 }
 
 var RecordedLogForTesting = new(bytes.Buffer)
 
 // alternative Setup function for testing that records log entries instead of writing them to console
 func SetupForTesting() {
+	output = RecordedLogForTesting
 	Setup()
-	log.Logger = zerolog.New(RecordedLogForTesting).With().Timestamp().Logger()
 }
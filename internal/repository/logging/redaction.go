@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	RedactionMask = "mask"
+	RedactionHash = "hash"
+	RedactionNone = "none"
+
+	// replaces the value of denied fields
+	Redacted = "[REDACTED]"
+)
+
+// email bodies never reach the logs, no matter how redaction is configured
+var alwaysDenied = []string{"body", "http.request.body.content", "http.response.body.content"}
+
+var emailAddressPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+
+// Redactor removes personal data from log entries.
+//
+// Denied fields are replaced by [REDACTED]. Email addresses in all other string values, including the
+// message, are masked or hashed, unless the field is allowed. A field name matches its own field and all
+// nested fields with that name, so subject matches both subject and email.subject.
+type Redactor struct {
+	mode  string
+	allow []string
+	deny  []string
+}
+
+func NewRedactor(mode string, allow []string, deny []string) *Redactor {
+	return &Redactor{
+		mode:  mode,
+		allow: allow,
+		deny:  append(append([]string{}, alwaysDenied...), deny...),
+	}
+}
+
+// RedactAddress masks or hashes a single email address according to the redaction mode.
+func (r *Redactor) RedactAddress(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 || r.mode == RedactionNone {
+		return address
+	}
+	local, domain := address[:at], address[at+1:]
+	if r.mode == RedactionHash {
+		sum := sha256.Sum256([]byte(strings.ToLower(address)))
+		return hex.EncodeToString(sum[:6]) + "@" + domain
+	}
+	if len(local) == 0 {
+		return "***@" + domain
+	}
+	return local[:1] + "***@" + domain
+}
+
+// RedactText redacts all email addresses contained in arbitrary text, such as an error message.
+func (r *Redactor) RedactText(text string) string {
+	if r.mode == RedactionNone {
+		return text
+	}
+	return emailAddressPattern.ReplaceAllStringFunc(text, r.RedactAddress)
+}
+
+func (r *Redactor) redactValue(path string, value interface{}) interface{} {
+	if matchesField(path, r.deny) {
+		return Redacted
+	}
+	switch typed := value.(type) {
+	case string:
+		if matchesField(path, r.allow) {
+			return typed
+		}
+		return r.RedactText(typed)
+	case map[string]interface{}:
+		for key, nested := range typed {
+			typed[key] = r.redactValue(joinPath(path, key), nested)
+		}
+		return typed
+	case []interface{}:
+		for i, nested := range typed {
+			typed[i] = r.redactValue(path, nested)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// RedactEntry redacts a single json log entry. Entries that are not valid json are redacted as text.
+func (r *Redactor) RedactEntry(entry []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(entry))
+	decoder.UseNumber()
+	fields := map[string]interface{}{}
+	if err := decoder.Decode(&fields); err != nil {
+		return []byte(r.RedactText(string(entry)))
+	}
+	for key, value := range fields {
+		fields[key] = r.redactValue(key, value)
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fields); err != nil {
+		return []byte(r.RedactText(string(entry)))
+	}
+	return buf.Bytes()
+}
+
+func matchesField(path string, names []string) bool {
+	for _, name := range names {
+		if path == name || strings.HasSuffix(path, "."+name) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// redactingWriter redacts each log entry before passing it on, zerolog writes one entry per call.
+type redactingWriter struct {
+	redactor *Redactor
+	next     io.Writer
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := w.next.Write(w.redactor.RedactEntry(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
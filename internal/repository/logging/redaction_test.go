@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func tstSetupRedaction(mode string, allow []string, deny []string) {
+	SetupForTesting()
+	RecordedLogForTesting.Reset()
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(&redactingWriter{redactor: NewRedactor(mode, allow, deny), next: RecordedLogForTesting})
+}
+
+func TestRedaction_ShouldMaskAddressesInMessagesAndFields(t *testing.T) {
+	tstSetupRedaction(RedactionMask, nil, nil)
+
+	log.Warn().Str("to", "john.doe@example.com").Msgf("validation failed for %s", "jane@example.org")
+
+	recorded := RecordedLogForTesting.String()
+	require.Contains(t, recorded, `"to":"j***@example.com"`)
+	require.Contains(t, recorded, `"message":"validation failed for j***@example.org"`)
+	require.NotContains(t, recorded, "john.doe")
+	require.NotContains(t, recorded, "jane@")
+}
+
+func TestRedaction_ShouldHashAddresses(t *testing.T) {
+	tstSetupRedaction(RedactionHash, nil, nil)
+
+	log.Info().Str("to", "John.Doe@example.com").Msg("sent")
+	log.Info().Str("to", "john.doe@example.com").Msg("sent")
+
+	recorded := RecordedLogForTesting.String()
+	require.NotContains(t, recorded, "john.doe")
+	require.Contains(t, recorded, "@example.com")
+	// the hash is case insensitive, so entries for the same recipient can be correlated
+	lines := strings.Split(strings.TrimSpace(recorded), "\n")
+	require.Len(t, lines, 2)
+	first, second := map[string]interface{}{}, map[string]interface{}{}
+	require.Nil(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.Nil(t, json.Unmarshal([]byte(lines[1]), &second))
+	require.Equal(t, first["to"], second["to"])
+}
+
+func TestRedaction_ShouldApplyAllowAndDenyLists(t *testing.T) {
+	tstSetupRedaction(RedactionMask, []string{"from"}, []string{"subject"})
+
+	log.Info().
+		Str("from", "mailer@example.com").
+		Dict("email", zerolog.Dict().Str("subject", "Your diagnosis").Str("to", "patient@example.com")).
+		Msg("sending")
+
+	recorded := RecordedLogForTesting.String()
+	require.Contains(t, recorded, `"from":"mailer@example.com"`)
+	require.Contains(t, recorded, `"subject":"[REDACTED]"`)
+	require.Contains(t, recorded, `"to":"p***@example.com"`)
+	require.NotContains(t, recorded, "diagnosis")
+}
+
+func TestRedaction_ShouldNeverLogBodies(t *testing.T) {
+	tstSetupRedaction(RedactionNone, []string{"body", "email.body"}, nil)
+
+	email := entity.Email{ID: "abc", ToAddress: "someone@example.com", Subject: "Secret subject", Body: "Secret body"}
+	log.Info().Str("body", email.Body).Dict("email", zerolog.Dict().Str("body", email.Body)).Msgf("email %v", email)
+
+	recorded := RecordedLogForTesting.String()
+	require.NotContains(t, recorded, "Secret")
+	require.Contains(t, recorded, `"body":"[REDACTED]"`)
+	require.Contains(t, recorded, "someone@example.com")
+}
+
+func TestRedaction_ShouldBeActiveAfterPostConfigSetup(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	SetupForTesting()
+	PostConfigSetup()
+	RecordedLogForTesting.Reset()
+
+	log.Warn().Msg(fmt.Sprintf("business validation for email failed - rejected: %s is suppressed", "someone@example.com"))
+
+	require.Contains(t, RecordedLogForTesting.String(), "s***@example.com is suppressed")
+}
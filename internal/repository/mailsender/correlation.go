@@ -0,0 +1,56 @@
+package mailsender
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MessageId builds the Message-ID header value for an email, using the domain of the sender address.
+func MessageId(emailId string, from string) string {
+	return fmt.Sprintf("<%s@%s>", emailId, domainOf(from, "localhost"))
+}
+
+// EmailIdFromMessageId reverses MessageId. Returns "" if the message id does not have the expected form.
+func EmailIdFromMessageId(messageId string) string {
+	messageId = strings.TrimSpace(messageId)
+	if !strings.HasPrefix(messageId, "<") || !strings.HasSuffix(messageId, ">") {
+		return ""
+	}
+	at := strings.LastIndex(messageId, "@")
+	if at < 0 {
+		return ""
+	}
+	return messageId[1:at]
+}
+
+// VerpAddress encodes the email id into the bounce address, turning bounces@example.com into
+// bounces+<emailId>@example.com.
+func VerpAddress(bounceAddress string, emailId string) string {
+	at := strings.LastIndex(bounceAddress, "@")
+	if at < 0 {
+		return bounceAddress + "+" + emailId
+	}
+	return bounceAddress[:at] + "+" + emailId + bounceAddress[at:]
+}
+
+// EmailIdFromVerpAddress reverses VerpAddress. Returns "" if the address carries no email id.
+func EmailIdFromVerpAddress(address string) string {
+	address = strings.Trim(strings.TrimSpace(address), "<>")
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		at = len(address)
+	}
+	local := address[:at]
+	plus := strings.Index(local, "+")
+	if plus < 0 {
+		return ""
+	}
+	return local[plus+1:]
+}
+
+func domainOf(address string, fallback string) string {
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return strings.Trim(address[at+1:], "<> ")
+	}
+	return fallback
+}
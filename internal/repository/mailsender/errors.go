@@ -0,0 +1,23 @@
+package mailsender
+
+import (
+	"errors"
+	"net/textproto"
+)
+
+// ErrRejected can be returned by senders for permanent failures that carry no smtp reply code.
+var ErrRejected = errors.New("email rejected by mail server")
+
+// IsPermanent tells whether retrying a failed Send is pointless.
+//
+// Only 5xx smtp replies are permanent. Everything else, such as 4xx replies or network errors, may go away.
+func IsPermanent(err error) bool {
+	if errors.Is(err, ErrRejected) {
+		return true
+	}
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return reply.Code >= 500 && reply.Code < 600
+	}
+	return false
+}
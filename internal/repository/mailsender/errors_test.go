@@ -0,0 +1,16 @@
+package mailsender
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/textproto"
+	"testing"
+)
+
+func TestIsPermanent(t *testing.T) {
+	require.True(t, IsPermanent(fmt.Errorf("wrapped: %w", &textproto.Error{Code: 550, Msg: "no such user"})))
+	require.True(t, IsPermanent(ErrRejected))
+	require.False(t, IsPermanent(fmt.Errorf("wrapped: %w", &textproto.Error{Code: 451, Msg: "try again later"})))
+	require.False(t, IsPermanent(errors.New("dial tcp: connection refused")))
+}
@@ -0,0 +1,48 @@
+package mailsender
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/rs/zerolog/log"
+	"sync"
+)
+
+// InMemorySender just records emails instead of sending them. Useful for tests and local development.
+type InMemorySender struct {
+	mu       sync.Mutex
+	sent     []entity.Email
+	failures []error
+}
+
+func CreateInMemorySender() *InMemorySender {
+	return &InMemorySender{}
+}
+
+func (s *InMemorySender) Send(ctx context.Context, email *entity.Email) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.failures) > 0 {
+		err := s.failures[0]
+		s.failures = s.failures[1:]
+		return err
+	}
+	s.sent = append(s.sent, *email)
+	log.Ctx(ctx).Info().Msgf("recorded email %s in memory instead of sending it", email.ID)
+	return nil
+}
+
+// Sent returns copies of all emails recorded so far, in order.
+func (s *InMemorySender) Sent() []entity.Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]entity.Email, len(s.sent))
+	copy(result, s.sent)
+	return result
+}
+
+// FailWith makes the next calls to Send return these errors, in order, without recording the email.
+func (s *InMemorySender) FailWith(errs ...error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, errs...)
+}
@@ -0,0 +1,35 @@
+package mailsender
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/health"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+type MailSender interface {
+	Send(ctx context.Context, email *entity.Email) error
+}
+
+var (
+	ActiveMailSender MailSender
+)
+
+func Setup() {
+	if host := configuration.MailSmtpHost(); host != "" {
+		address := configuration.MailSmtpAddress()
+		log.Info().Msgf("setting up smtp mail sender for %s", address)
+		sender := CreateSmtpSender(address, configuration.MailSmtpUsername(), configuration.MailSmtpPassword(), configuration.MailFrom(), configuration.MailBounceAddress(), configuration.MailSmtpTimeout())
+		health.Register("mail", 5*time.Second, sender.Ping)
+		ActiveMailSender = sender
+	} else {
+		log.Warn().Msg("no smtp host configured, emails will only be recorded in memory and NOT be sent")
+		ActiveMailSender = CreateInMemorySender()
+	}
+}
+
+func Get() MailSender {
+	return ActiveMailSender
+}
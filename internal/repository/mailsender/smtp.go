@@ -0,0 +1,186 @@
+package mailsender
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SmtpSender struct {
+	address       string
+	username      string
+	password      string
+	from          string
+	bounceAddress string
+	timeout       time.Duration
+}
+
+// CreateSmtpSender sets up sending via an smtp relay.
+//
+// If bounceAddress is set, each email gets a VERP envelope sender derived from it, so bounces can be
+// correlated to the email even if the bouncing server drops the original headers.
+//
+// Sending an email gives up after timeout, so a hung relay cannot block the scheduler.
+func CreateSmtpSender(address string, username string, password string, from string, bounceAddress string, timeout time.Duration) *SmtpSender {
+	return &SmtpSender{
+		address:       address,
+		username:      username,
+		password:      password,
+		from:          from,
+		bounceAddress: bounceAddress,
+		timeout:       timeout,
+	}
+}
+
+// Ping connects to the relay and waits for its greeting, without sending anything.
+func (s *SmtpSender) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	host, _, _ := net.SplitHostPort(s.address)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	return client.Quit()
+}
+
+func (s *SmtpSender) Send(ctx context.Context, email *entity.Email) error {
+	message, err := s.buildMessage(email)
+	if err != nil {
+		return err
+	}
+
+	envelopeFrom := s.from
+	if s.bounceAddress != "" {
+		envelopeFrom = VerpAddress(s.bounceAddress, email.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	err = s.sendMail(ctx, envelopeFrom, email.ToAddress, message)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return fmt.Errorf("failed to send email %s via smtp: %w", email.ID, err)
+	}
+	return nil
+}
+
+// sendMail does what smtp.SendMail does, except that it gives up as soon as ctx is done.
+func (s *SmtpSender) sendMail(ctx context.Context, from string, to string, message []byte) error {
+	host, _, err := net.SplitHostPort(s.address)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	// the deadline does not cover cancellation, e.g. on shutdown, so this unblocks whatever is waiting on the relay
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (s *SmtpSender) buildMessage(email *entity.Email) ([]byte, error) {
+	for _, value := range []string{s.from, email.ToAddress} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("email %s contains a line break in an address", email.ID)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	writeHeader(buf, "From", s.from)
+	writeHeader(buf, "To", email.ToAddress)
+	writeHeader(buf, "Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	writeHeader(buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(buf, "Message-ID", MessageId(email.ID, s.from))
+	if email.RequestID != "" && !strings.ContainsAny(email.RequestID, "\r\n") {
+		writeHeader(buf, "X-Request-Id", email.RequestID)
+	}
+	if s.bounceAddress != "" {
+		writeHeader(buf, "Return-Path", "<"+VerpAddress(s.bounceAddress, email.ID)+">")
+	}
+	writeHeader(buf, "MIME-Version", "1.0")
+	writeHeader(buf, "Content-Type", "text/plain; charset=utf-8")
+	writeHeader(buf, "Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(email.Body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, name string, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
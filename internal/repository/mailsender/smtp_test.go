@@ -0,0 +1,107 @@
+package mailsender
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tstRelay accepts a single smtp session and passes on the message it receives. If hang is set, it never even greets.
+func tstRelay(t *testing.T, hang bool) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if hang {
+			_, _ = io.Copy(ioutil.Discard, conn)
+			return
+		}
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "DATA"):
+				_ = tp.PrintfLine("354 go ahead")
+				lines, _ := tp.ReadDotLines()
+				received <- strings.Join(lines, "\n")
+				_ = tp.PrintfLine("250 queued")
+			case strings.HasPrefix(line, "QUIT"):
+				_ = tp.PrintfLine("221 bye")
+				return
+			default:
+				_ = tp.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func tstSmtpEmail() *entity.Email {
+	return &entity.Email{ID: "abc", ToAddress: "someone@example.com", Subject: "Hello", Body: "World"}
+}
+
+func TestBuildMessage_ShouldCarryRequestId(t *testing.T) {
+	cut := CreateSmtpSender("localhost:25", "", "", "mailer@example.com", "", time.Minute)
+	email := &entity.Email{ID: "abc", ToAddress: "someone@example.com", Subject: "Hello", Body: "World", RequestID: "caller-id-42"}
+
+	message, err := cut.buildMessage(email)
+	require.Nil(t, err)
+	require.Contains(t, string(message), "\r\nX-Request-Id: caller-id-42\r\n")
+}
+
+func TestBuildMessage_ShouldOmitMissingRequestId(t *testing.T) {
+	cut := CreateSmtpSender("localhost:25", "", "", "mailer@example.com", "", time.Minute)
+	email := &entity.Email{ID: "abc", ToAddress: "someone@example.com", Subject: "Hello", Body: "World"}
+
+	message, err := cut.buildMessage(email)
+	require.Nil(t, err)
+	require.NotContains(t, string(message), "X-Request-Id")
+}
+
+func TestSend_ShouldDeliverToRelay(t *testing.T) {
+	address, received := tstRelay(t, false)
+	cut := CreateSmtpSender(address, "", "", "mailer@example.com", "", time.Minute)
+
+	require.Nil(t, cut.Send(context.Background(), tstSmtpEmail()))
+	require.Contains(t, <-received, "Subject: Hello")
+}
+
+func TestSend_ShouldGiveUpOnHungRelay(t *testing.T) {
+	address, _ := tstRelay(t, true)
+	cut := CreateSmtpSender(address, "", "", "mailer@example.com", "", 100*time.Millisecond)
+
+	start := time.Now()
+	err := cut.Send(context.Background(), tstSmtpEmail())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestSend_ShouldStopWhenCancelled(t *testing.T) {
+	address, _ := tstRelay(t, true)
+	cut := CreateSmtpSender(address, "", "", "mailer@example.com", "", time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := cut.Send(ctx, tstSmtpEmail())
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
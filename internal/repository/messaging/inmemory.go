@@ -0,0 +1,87 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryBroker stands in for kafka in tests.
+//
+// Messages sent to the command topic are handed out by Fetch in order, everything else is recorded.
+type InMemoryBroker struct {
+	commandTopic string
+	commands     chan *Message
+
+	mu        sync.Mutex
+	offset    int64
+	published map[string][]*Message
+	committed []*Message
+}
+
+func CreateInMemoryBroker(commandTopic string) *InMemoryBroker {
+	return &InMemoryBroker{
+		commandTopic: commandTopic,
+		commands:     make(chan *Message, 100),
+		published:    make(map[string][]*Message),
+	}
+}
+
+func (b *InMemoryBroker) Fetch(ctx context.Context) (*Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case message := <-b.commands:
+		return message, nil
+	}
+}
+
+func (b *InMemoryBroker) Commit(ctx context.Context, message *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.committed = append(b.committed, message)
+	return nil
+}
+
+func (b *InMemoryBroker) Publish(ctx context.Context, topic string, message *Message) error {
+	b.mu.Lock()
+	copied := *message
+	copied.Topic = topic
+	copied.Offset = b.offset
+	b.offset++
+	b.mu.Unlock()
+
+	if topic == b.commandTopic {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case b.commands <- &copied:
+		}
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published[topic] = append(b.published[topic], &copied)
+	return nil
+}
+
+func (b *InMemoryBroker) Close() error {
+	return nil
+}
+
+// Published returns the messages published to a topic other than the command topic.
+func (b *InMemoryBroker) Published(topic string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*Message{}, b.published[topic]...)
+}
+
+// Committed returns all messages that were committed so far.
+func (b *InMemoryBroker) Committed() []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*Message{}, b.committed...)
+}
@@ -0,0 +1,105 @@
+package messaging
+
+import (
+	"context"
+	"github.com/segmentio/kafka-go"
+	"sync"
+)
+
+type KafkaBroker struct {
+	brokers []string
+	reader  *kafka.Reader
+
+	mu      sync.Mutex
+	writers map[string]*kafka.Writer
+}
+
+func CreateKafkaBroker(brokers []string, groupId string, commandTopic string) *KafkaBroker {
+	return &KafkaBroker{
+		brokers: brokers,
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: brokers,
+			GroupID: groupId,
+			Topic:   commandTopic,
+		}),
+		writers: make(map[string]*kafka.Writer),
+	}
+}
+
+// Ping succeeds if at least one of the brokers accepts a connection.
+func (b *KafkaBroker) Ping(ctx context.Context) error {
+	var err error
+	for _, broker := range b.brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	return err
+}
+
+func (b *KafkaBroker) Fetch(ctx context.Context) (*Message, error) {
+	raw, err := b.reader.FetchMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	message := &Message{
+		Topic:     raw.Topic,
+		Partition: raw.Partition,
+		Offset:    raw.Offset,
+		Key:       raw.Key,
+		Value:     raw.Value,
+		Headers:   make(map[string]string),
+	}
+	for _, header := range raw.Headers {
+		message.Headers[header.Key] = string(header.Value)
+	}
+	return message, nil
+}
+
+func (b *KafkaBroker) Commit(ctx context.Context, message *Message) error {
+	return b.reader.CommitMessages(ctx, kafka.Message{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+	})
+}
+
+func (b *KafkaBroker) Publish(ctx context.Context, topic string, message *Message) error {
+	raw := kafka.Message{
+		Key:   message.Key,
+		Value: message.Value,
+	}
+	for key, value := range message.Headers {
+		raw.Headers = append(raw.Headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+	return b.writer(topic).WriteMessages(ctx, raw)
+}
+
+// writers are bound to a single topic, so we create them as needed
+func (b *KafkaBroker) writer(topic string) *kafka.Writer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	writer, ok := b.writers[topic]
+	if !ok {
+		writer = kafka.NewWriter(kafka.WriterConfig{
+			Brokers: b.brokers,
+			Topic:   topic,
+		})
+		b.writers[topic] = writer
+	}
+	return writer
+}
+
+func (b *KafkaBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.reader.Close()
+	for _, writer := range b.writers {
+		if writerErr := writer.Close(); err == nil {
+			err = writerErr
+		}
+	}
+	return err
+}
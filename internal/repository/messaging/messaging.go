@@ -0,0 +1,62 @@
+package messaging
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/health"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// Message is a message read from or written to a topic.
+type Message struct {
+	// set when reading, ignored when writing
+	Topic     string
+	Partition int
+	Offset    int64
+
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Broker consumes from the command topic and publishes to arbitrary topics.
+type Broker interface {
+	// Fetch blocks until the next message on the command topic arrives, or the context is done.
+	Fetch(ctx context.Context) (*Message, error)
+	// Commit marks a fetched message as processed, so it is not delivered again to the consumer group.
+	Commit(ctx context.Context, message *Message) error
+	Publish(ctx context.Context, topic string, message *Message) error
+	Close() error
+}
+
+var (
+	// nil if no message broker is configured
+	ActiveBroker Broker
+)
+
+func Setup() {
+	if brokers := configuration.KafkaBrokers(); brokers != "" {
+		log.Info().Msgf("setting up kafka consumer for topic %s on %s", configuration.KafkaCommandTopic(), brokers)
+		broker := CreateKafkaBroker(strings.Split(brokers, ","), configuration.KafkaGroupId(), configuration.KafkaCommandTopic())
+		health.Register("broker", 5*time.Second, broker.Ping)
+		ActiveBroker = broker
+	} else {
+		log.Info().Msg("no kafka brokers configured, send-email commands will only be accepted via REST")
+		ActiveBroker = nil
+	}
+}
+
+func Get() Broker {
+	return ActiveBroker
+}
+
+func Close() {
+	if ActiveBroker != nil {
+		if err := ActiveBroker.Close(); err != nil {
+			log.Warn().Err(err).Msgf("error closing message broker: %v", err)
+		}
+		ActiveBroker = nil
+	}
+}
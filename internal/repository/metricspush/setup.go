@@ -4,11 +4,67 @@ import (
 	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
 	"github.com/armon/go-metrics"
 	"github.com/armon/go-metrics/prometheus"
+	prometheusclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
 	"github.com/rs/zerolog/log"
+	"net/http"
 	"time"
 )
 
+const (
+	ModePush  = "push"
+	ModePull  = "pull"
+	ModeBoth  = "both"
+	ModeInmem = "inmem"
+)
+
+var pushSink *prometheus.PrometheusPushSink
+
+// nil unless metrics are scraped
+var scrapeHandler http.Handler
+
 func SetupPrometheusPushSink() error {
+	sink, err := createPushSink()
+	if err != nil {
+		return err
+	}
+	return setupGlobal(sink, true)
+}
+
+// SetupPrometheusPullSink records metrics in the default prometheus registry, to be scraped from /metrics.
+func SetupPrometheusPullSink() error {
+	log.Info().Msg("setting up prometheus metrics sink for scraping on /metrics")
+	// never expire, so counters only ever go up between restarts
+	sink, err := prometheus.NewPrometheusSinkFrom(prometheus.PrometheusOpts{Expiration: 0})
+	if err != nil {
+		return err
+	}
+	scrapeHandler = promhttp.Handler()
+	return setupGlobal(sink, false)
+}
+
+// SetupPrometheusPushAndPullSink pushes metrics and also makes the same values available for scraping.
+func SetupPrometheusPushAndPullSink() error {
+	sink, err := createPushSink()
+	if err != nil {
+		return err
+	}
+	log.Info().Msg("also offering pushed metrics for scraping on /metrics")
+	if err := prometheusclient.Register(sink); err != nil {
+		return err
+	}
+	scrapeHandler = promhttp.Handler()
+	return setupGlobal(sink, false)
+}
+
+func SetupInMemorySink() error {
+	log.Info().Msg("setting up in memory metrics push sink")
+	sink := metrics.NewInmemSink(10*time.Millisecond, 50*time.Millisecond)
+	return setupGlobal(sink, true)
+}
+
+func createPushSink() (*prometheus.PrometheusPushSink, error) {
 	pushInterval := 10 * time.Second
 	address := configuration.MetricsPushAddress()
 	name := configuration.MetricsPushSinkName()
@@ -16,34 +72,56 @@ func SetupPrometheusPushSink() error {
 
 	sink, err := prometheus.NewPrometheusPushSink(address, pushInterval, name)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	metricsConf := metrics.DefaultConfig(configuration.ServiceName())
-	// metricsConf.HostName = "localhost"
-	// metricsConf.EnableHostnameLabel = true
-	_, err = metrics.NewGlobal(metricsConf, sink)
-	return err
+	pushSink = sink
+	return sink, nil
 }
 
-func SetupInMemorySink() error {
-	log.Info().Msg("setting up in memory metrics push sink")
-	sink := metrics.NewInmemSink(10*time.Millisecond, 50*time.Millisecond)
+func setupGlobal(sink metrics.MetricSink, enableHostname bool) error {
 	metricsConf := metrics.DefaultConfig(configuration.ServiceName())
+	// when scraped, prometheus adds the instance label itself, a hostname in the metric name would just break queries
+	metricsConf.EnableHostname = enableHostname
+	// metricsConf.EnableHostnameLabel = true
 	_, err := metrics.NewGlobal(metricsConf, sink)
 	return err
 }
 
 func Setup() {
 	var err error
-	var sinktype string
-	if configuration.EnableMetricsPush() {
+	mode := configuration.MetricsMode()
+	switch mode {
+	case ModePush:
 		err = SetupPrometheusPushSink()
-		sinktype = "prometheus"
-	} else {
+	case ModePull:
+		err = SetupPrometheusPullSink()
+	case ModeBoth:
+		err = SetupPrometheusPushAndPullSink()
+	default:
 		err = SetupInMemorySink()
-		sinktype = "inmemory"
 	}
 	if err != nil {
-		log.Fatal().Err(err).Msg("setting up metrics push sink failed for type " + sinktype)
+		log.Fatal().Err(err).Msg("setting up metrics sink failed for mode " + mode)
+	}
+}
+
+// ScrapeHandler serves the metrics in prometheus text format, or is nil if the mode does not include pull.
+func ScrapeHandler() http.Handler {
+	return scrapeHandler
+}
+
+// Shutdown stops the periodic push and pushes the current values one last time, so the
+// metrics recorded since the last push are not lost.
+func Shutdown() {
+	if pushSink == nil {
+		return
+	}
+	pushSink.Shutdown()
+	err := push.New(configuration.MetricsPushAddress(), configuration.MetricsPushSinkName()).Collector(pushSink).Push()
+	if err != nil {
+		log.Warn().Err(err).Msgf("final metrics push failed: %v", err)
+	} else {
+		log.Info().Msg("pushed metrics one last time")
 	}
+	pushSink = nil
 }
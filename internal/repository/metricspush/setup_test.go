@@ -0,0 +1,28 @@
+package metricspush
+
+import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/armon/go-metrics"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetupPrometheusPullSink_ShouldServeMetrics(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	require.Nil(t, SetupPrometheusPullSink())
+	require.NotNil(t, ScrapeHandler())
+
+	metrics.MeasureSince([]string{"SendEmail"}, time.Now().Add(-time.Second))
+
+	response := httptest.NewRecorder()
+	ScrapeHandler().ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, response.Code)
+	body, _ := ioutil.ReadAll(response.Body)
+	require.Contains(t, string(body), "unnamed_service_SendEmail_count 1")
+	// the default registry also has the standard go runtime metrics
+	require.Contains(t, string(body), "go_goroutines")
+}
@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOtlp   = "otlp"
+
+	instrumentationName = "github.com/StephanHCB/go-mailer-service"
+)
+
+var provider *sdktrace.TracerProvider
+
+// Setup installs the global tracer provider and the W3C trace context propagator.
+//
+// Spans are always created, so trace ids show up in the logs and are passed on to callers,
+// they are only exported if tracing.exporter is stdout or otlp.
+func Setup() {
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(serviceResource())}
+	switch exporter := configuration.TracingExporter(); exporter {
+	case ExporterStdout:
+		log.Info().Msg("exporting spans to stdout")
+		exp, err := stdouttrace.New()
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to set up stdout span exporter")
+		}
+		options = append(options, sdktrace.WithBatcher(exp))
+	case ExporterOtlp:
+		endpoint := configuration.TracingOtlpEndpoint()
+		log.Info().Msgf("exporting spans via otlp/http to %s", endpoint)
+		exporterOptions := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+		if configuration.TracingOtlpInsecure() {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+		}
+		// does not connect yet, so an unavailable collector does not prevent startup
+		exp, err := otlptracehttp.New(context.Background(), exporterOptions...)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to set up otlp span exporter")
+		}
+		options = append(options, sdktrace.WithBatcher(exp))
+	default:
+		log.Info().Msg("span export is disabled")
+	}
+	install(sdktrace.NewTracerProvider(options...))
+}
+
+// SetupForTesting records all ended spans in memory, use this in tests.
+func SetupForTesting() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	install(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+// Shutdown exports any spans that are still buffered.
+func Shutdown(ctx context.Context) {
+	if provider == nil {
+		return
+	}
+	if err := provider.Shutdown(ctx); err != nil {
+		log.Warn().Err(err).Msgf("failed to export remaining spans: %v", err)
+	}
+	provider = nil
+}
+
+func install(p *sdktrace.TracerProvider) {
+	provider = p
+	otel.SetTracerProvider(p)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+func serviceResource() *resource.Resource {
+	return resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(configuration.ServiceName()))
+}
+
+// Tracer returns the tracer for all spans of this service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// TraceId returns the id of the trace the context belongs to, or "" if there is none.
+func TraceId(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// Inject adds the trace context to message headers, so consumers can continue the trace.
+func Inject(ctx context.Context, headers map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, headerMap(headers))
+}
+
+// Extract continues a trace from message headers, if they carry a trace context.
+func Extract(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerMap(headers))
+}
+
+type headerMap map[string]string
+
+func (h headerMap) Get(key string) string {
+	return h[key]
+}
+
+func (h headerMap) Set(key string, value string) {
+	h[key] = value
+}
+
+func (h headerMap) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInjectExtract_ShouldPropagateTraceThroughHeaders(t *testing.T) {
+	recorder := SetupForTesting()
+	ctx, span := Tracer().Start(context.Background(), "producer")
+	headers := map[string]string{"origin": "test"}
+
+	Inject(ctx, headers)
+	span.End()
+
+	require.Contains(t, headers, "traceparent")
+	require.Equal(t, "test", headers["origin"])
+	continued := Extract(context.Background(), headers)
+	require.Equal(t, TraceId(ctx), TraceId(continued))
+	require.Equal(t, recorder.Ended()[0].SpanContext().TraceID().String(), TraceId(continued))
+}
+
+func TestTraceId_ShouldBeEmptyOutsideOfTraces(t *testing.T) {
+	require.Equal(t, "", TraceId(context.Background()))
+}
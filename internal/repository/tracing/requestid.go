@@ -0,0 +1,17 @@
+package tracing
+
+import "context"
+
+type requestIdKeyType struct{}
+
+var requestIdKey = requestIdKeyType{}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+// RequestId returns the id of the request being processed, or "" outside of requests (e.g. the scheduler).
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey).(string)
+	return requestId
+}
@@ -0,0 +1,98 @@
+package bouncesrv
+
+import (
+	"context"
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/StephanHCB/go-mailer-service/internal/service/eventsrv"
+	"github.com/armon/go-metrics"
+	"github.com/rs/zerolog/log"
+	"io"
+	"strings"
+	"time"
+)
+
+type BounceServiceImpl struct {
+	repository dbrepo.Repository
+}
+
+func Create() BounceService {
+	service := &BounceServiceImpl{
+		repository: database.GetRepository(),
+	}
+	return service
+}
+
+func (s *BounceServiceImpl) ProcessReport(ctx context.Context, message io.Reader) (*Report, error) {
+	report, err := ParseReport(message)
+	if err != nil {
+		return nil, err
+	}
+	if report.EmailID == "" {
+		return report, ErrNotCorrelated
+	}
+
+	email, err := s.repository.GetEmail(ctx, report.EmailID)
+	if err != nil {
+		if errors.Is(err, dbrepo.ErrNotFound) {
+			return report, ErrNotCorrelated
+		}
+		return report, err
+	}
+
+	switch report.Kind {
+	case KindHardBounce:
+		email.Status = entity.EmailStatusBounced
+		email.StatusDetail = strings.TrimSpace(report.Status + " " + report.Diagnostic)
+		err = s.suppress(ctx, email, report)
+	case KindComplaint:
+		email.Status = entity.EmailStatusComplained
+		email.StatusDetail = report.Diagnostic
+	case KindSoftBounce:
+		// the remote server keeps retrying, just keep a note of it
+		email.StatusDetail = strings.TrimSpace(report.Status + " " + report.Diagnostic)
+	default:
+		log.Ctx(ctx).Info().Msgf("report for email %s requires no action", email.ID)
+		return report, nil
+	}
+	if err != nil {
+		return report, err
+	}
+
+	err = s.repository.UpdateEmail(ctx, email)
+	if err != nil {
+		return report, err
+	}
+	recordReport(report.Kind)
+	if report.Kind == KindHardBounce {
+		eventsrv.Publish(ctx, entity.EventTypeBounced, email)
+	}
+	log.Ctx(ctx).Info().Msgf("processed %s report for email %s, status now %s", report.Kind, email.ID, email.Status)
+	return report, nil
+}
+
+// complaints are counted separately, so they do not show up in bounce rates
+func recordReport(kind Kind) {
+	if kind == KindComplaint {
+		metrics.IncrCounter([]string{"email", "complained"}, 1)
+		return
+	}
+	metrics.IncrCounterWithLabels([]string{"email", "bounced"}, 1, []metrics.Label{{Name: "kind", Value: string(kind)}})
+}
+
+func (s *BounceServiceImpl) suppress(ctx context.Context, email *entity.Email, report *Report) error {
+	// prefer the address we sent to, the bouncing server may have rewritten it
+	address := email.ToAddress
+	if address == "" {
+		address = report.Recipient
+	}
+	suppression := &entity.Suppression{
+		Address:   address,
+		Reason:    "hard bounce " + report.Status,
+		EmailID:   email.ID,
+		CreatedAt: time.Now(),
+	}
+	return s.repository.AddSuppression(ctx, suppression)
+}
@@ -0,0 +1,40 @@
+package bouncesrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/inmemorydb"
+	"github.com/armon/go-metrics"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessReport_ShouldCountComplaintsApartFromBounces(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	conf := metrics.DefaultConfig("test")
+	conf.EnableHostname = false
+	conf.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(conf, sink)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	repository := inmemorydb.Create()
+	require.Nil(t, repository.Open())
+	for _, id := range []string{"MESSAGE-ID", "VERP-ID", "ARF-ID"} {
+		require.Nil(t, repository.AddEmail(ctx, &entity.Email{ID: id, ToAddress: "someone@example.com", Status: entity.EmailStatusSent}))
+	}
+	cut := &BounceServiceImpl{repository: repository}
+
+	for _, message := range []string{tstHardBounceDsn, tstDelayedDsn, tstAbuseArf} {
+		_, err := cut.ProcessReport(ctx, strings.NewReader(message))
+		require.Nil(t, err)
+	}
+
+	counters := sink.Data()[0].Counters
+	require.Equal(t, 1, counters["test.email.bounced;kind=hard"].Count)
+	require.Equal(t, 1, counters["test.email.bounced;kind=soft"].Count)
+	require.Equal(t, 1, counters["test.email.complained"].Count)
+	require.NotContains(t, counters, "test.email.bounced;kind=complaint")
+}
@@ -0,0 +1,8 @@
+package bouncesrv
+
+import "errors"
+
+var (
+	ErrNotAReport    = errors.New("message is not a delivery status notification or feedback report")
+	ErrNotCorrelated = errors.New("report could not be correlated to a sent email")
+)
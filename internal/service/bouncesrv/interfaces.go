@@ -0,0 +1,12 @@
+package bouncesrv
+
+import (
+	"context"
+	"io"
+)
+
+type BounceService interface {
+	// ProcessReport parses a delivery status notification (RFC 3464) or feedback report (RFC 5965),
+	// correlates it to a sent email, and updates the email status and the suppressed addresses.
+	ProcessReport(ctx context.Context, message io.Reader) (*Report, error)
+}
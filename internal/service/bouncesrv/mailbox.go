@@ -0,0 +1,113 @@
+package bouncesrv
+
+import (
+	"context"
+	"errors"
+	"github.com/rs/zerolog/log"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MailboxPoller periodically processes bounces delivered into a maildir style directory.
+//
+// New messages are read from the new subdirectory. Successfully processed messages are moved to cur,
+// messages that are not reports or cannot be correlated are moved to failed for manual inspection.
+type MailboxPoller struct {
+	service   BounceService
+	directory string
+	interval  time.Duration
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func StartMailboxPoller(service BounceService, directory string, interval time.Duration) (*MailboxPoller, error) {
+	for _, sub := range []string{"new", "cur", "failed"} {
+		if err := os.MkdirAll(filepath.Join(directory, sub), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &MailboxPoller{
+		service:   service,
+		directory: directory,
+		interval:  interval,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	log.Info().Msgf("starting bounce mailbox poller for %s with poll interval %v", directory, interval)
+	go p.run(ctx)
+	return p, nil
+}
+
+// Stop signals the poller to stop and waits for the current run to finish.
+func (p *MailboxPoller) Stop() {
+	p.cancel()
+	<-p.done
+	log.Info().Msg("bounce mailbox poller stopped")
+}
+
+func (p *MailboxPoller) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.ProcessNewMessages(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.ProcessNewMessages(ctx)
+		}
+	}
+}
+
+// ProcessNewMessages handles all messages currently in the new subdirectory.
+func (p *MailboxPoller) ProcessNewMessages(ctx context.Context) {
+	sublogger := log.Logger.With().Str("component", "bounce-mailbox").Logger()
+	ctx = sublogger.WithContext(ctx)
+
+	files, err := ioutil.ReadDir(filepath.Join(p.directory, "new"))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list bounce mailbox: %v", err)
+		return
+	}
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return
+		}
+		if file.IsDir() {
+			continue
+		}
+		p.processFile(ctx, file.Name())
+	}
+}
+
+func (p *MailboxPoller) processFile(ctx context.Context, name string) {
+	fileName := filepath.Join(p.directory, "new", name)
+	file, err := os.Open(fileName)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to open bounce message %s: %v", name, err)
+		return
+	}
+	_, err = p.service.ProcessReport(ctx, file)
+	_ = file.Close()
+
+	target := "cur"
+	if err != nil {
+		if !errors.Is(err, ErrNotAReport) && !errors.Is(err, ErrNotCorrelated) {
+			// probably a temporary problem, leave it for the next run
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to process bounce message %s, will retry: %v", name, err)
+			return
+		}
+		log.Ctx(ctx).Warn().Err(err).Msgf("moving unusable bounce message %s to failed: %v", name, err)
+		target = "failed"
+	}
+	if err := os.Rename(fileName, filepath.Join(p.directory, target, name)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to move bounce message %s: %v", name, err)
+	}
+}
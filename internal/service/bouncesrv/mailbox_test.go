@@ -0,0 +1,47 @@
+package bouncesrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/inmemorydb"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMailboxPoller_ShouldProcessAndSortMessages(t *testing.T) {
+	directory, err := ioutil.TempDir("", "bounces")
+	require.Nil(t, err)
+	defer os.RemoveAll(directory)
+	ctx := context.Background()
+
+	repository := inmemorydb.Create()
+	require.Nil(t, repository.Open())
+	require.Nil(t, repository.AddEmail(ctx, &entity.Email{ID: "MESSAGE-ID", ToAddress: "someone@example.com", Status: entity.EmailStatusSent}))
+	service := &BounceServiceImpl{repository: repository}
+
+	for _, sub := range []string{"new", "cur", "failed"} {
+		require.Nil(t, os.MkdirAll(filepath.Join(directory, sub), 0700))
+	}
+	require.Nil(t, ioutil.WriteFile(filepath.Join(directory, "new", "1.bounce"), []byte(tstHardBounceDsn), 0600))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(directory, "new", "2.garbage"), []byte("not a message"), 0600))
+
+	poller := &MailboxPoller{service: service, directory: directory}
+	poller.ProcessNewMessages(ctx)
+
+	_, err = os.Stat(filepath.Join(directory, "cur", "1.bounce"))
+	require.Nil(t, err)
+	_, err = os.Stat(filepath.Join(directory, "failed", "2.garbage"))
+	require.Nil(t, err)
+
+	email, err := repository.GetEmail(ctx, "MESSAGE-ID")
+	require.Nil(t, err)
+	require.Equal(t, entity.EmailStatusBounced, email.Status)
+	require.Equal(t, "5.1.1 550 5.1.1 user unknown", email.StatusDetail)
+
+	suppression, err := repository.GetSuppression(ctx, "SOMEONE@example.com")
+	require.Nil(t, err)
+	require.Equal(t, "MESSAGE-ID", suppression.EmailID)
+}
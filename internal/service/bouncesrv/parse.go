@@ -0,0 +1,215 @@
+package bouncesrv
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/mailsender"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+type Kind string
+
+const (
+	// permanent failure, the address is dead
+	KindHardBounce Kind = "hard"
+	// temporary failure or delay, no action needed
+	KindSoftBounce Kind = "soft"
+	// the recipient reported the email as spam or abuse
+	KindComplaint Kind = "complaint"
+	// successful delivery notifications and feedback we do not act upon
+	KindNone Kind = "none"
+)
+
+// Report is what we extracted from a delivery status notification or feedback report.
+type Report struct {
+	Kind Kind
+	// empty if the report could not be correlated
+	EmailID string
+	// the address that failed or complained
+	Recipient string
+	// RFC 3463 status code such as 5.1.1, empty for feedback reports
+	Status     string
+	Diagnostic string
+	// RFC 5965 feedback type such as abuse, empty for delivery status notifications
+	FeedbackType string
+}
+
+// ParseReport reads a complete rfc822 message with content type multipart/report.
+func ParseReport(message io.Reader) (*Report, error) {
+	msg, err := mail.ReadMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAReport, err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || params["boundary"] == "" {
+		return nil, ErrNotAReport
+	}
+	reportType := strings.ToLower(params["report-type"])
+	if reportType != "delivery-status" && reportType != "feedback-report" {
+		return nil, fmt.Errorf("%w: unsupported report-type '%s'", ErrNotAReport, reportType)
+	}
+
+	report := &Report{Kind: KindNone}
+	var originalHeaders textproto.MIMEHeader
+	originalMailFrom := ""
+	foundReportPart := false
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotAReport, err)
+		}
+		content, err := readPart(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotAReport, err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			foundReportPart = true
+			parseDeliveryStatus(content, report)
+		case "message/feedback-report":
+			foundReportPart = true
+			originalMailFrom = parseFeedbackReport(content, report)
+		case "message/rfc822", "message/global", "text/rfc822-headers", "message/rfc822-headers", "message/global-headers":
+			originalHeaders = readHeaderBlock(content)
+		}
+	}
+	if !foundReportPart {
+		return nil, fmt.Errorf("%w: no %s part found", ErrNotAReport, reportType)
+	}
+
+	report.EmailID = correlate(msg.Header, originalHeaders, originalMailFrom)
+	return report, nil
+}
+
+func readPart(part *multipart.Part) ([]byte, error) {
+	// note that multipart already transparently decodes quoted-printable
+	var reader io.Reader = part
+	if strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "base64") {
+		reader = base64.NewDecoder(base64.StdEncoding, part)
+	}
+	return ioutil.ReadAll(reader)
+}
+
+// readHeaderBlock parses a header block, tolerating a missing terminating blank line.
+func readHeaderBlock(content []byte) textproto.MIMEHeader {
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(string(content))))
+	header, _ := reader.ReadMIMEHeader()
+	return header
+}
+
+// a delivery status consists of one per-message block followed by one block per recipient
+func parseDeliveryStatus(content []byte, report *Report) {
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(string(content))))
+	// skip per-message fields
+	if _, err := reader.ReadMIMEHeader(); err != nil {
+		return
+	}
+	for {
+		recipient, err := reader.ReadMIMEHeader()
+		if len(recipient) > 0 {
+			applyRecipientStatus(recipient, report)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func applyRecipientStatus(recipient textproto.MIMEHeader, report *Report) {
+	action := strings.ToLower(strings.TrimSpace(recipient.Get("Action")))
+	status := strings.TrimSpace(recipient.Get("Status"))
+
+	kind := KindNone
+	if action == "failed" && strings.HasPrefix(status, "5") {
+		kind = KindHardBounce
+	} else if action == "failed" || action == "delayed" {
+		kind = KindSoftBounce
+	}
+
+	// we only ever send to a single recipient, but if there are several, keep the first most severe one
+	if report.Recipient != "" && severity(kind) <= severity(report.Kind) {
+		return
+	}
+	report.Kind = kind
+	report.Status = status
+	report.Diagnostic = stripAddressType(recipient.Get("Diagnostic-Code"))
+	report.Recipient = stripAddressType(recipient.Get("Original-Recipient"))
+	if report.Recipient == "" {
+		report.Recipient = stripAddressType(recipient.Get("Final-Recipient"))
+	}
+}
+
+func severity(kind Kind) int {
+	switch kind {
+	case KindHardBounce:
+		return 2
+	case KindSoftBounce:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// returns the original envelope sender, which is our VERP address
+func parseFeedbackReport(content []byte, report *Report) string {
+	fields := readHeaderBlock(content)
+	report.FeedbackType = strings.ToLower(strings.TrimSpace(fields.Get("Feedback-Type")))
+	if report.FeedbackType != "not-spam" {
+		report.Kind = KindComplaint
+	}
+	report.Recipient = strings.Trim(strings.TrimSpace(fields.Get("Original-Rcpt-To")), "<>")
+	report.Diagnostic = "feedback-type " + report.FeedbackType
+	return fields.Get("Original-Mail-From")
+}
+
+// "rfc822; someone@example.com" -> "someone@example.com"
+func stripAddressType(value string) string {
+	if semicolon := strings.Index(value, ";"); semicolon >= 0 {
+		value = value[semicolon+1:]
+	}
+	return strings.TrimSpace(value)
+}
+
+// correlate tries the Message-ID of the original email first, and falls back to the VERP addresses
+// the email was sent from, or the report was delivered to.
+func correlate(reportHeaders mail.Header, originalHeaders textproto.MIMEHeader, originalMailFrom string) string {
+	if originalHeaders != nil {
+		if id := mailsender.EmailIdFromMessageId(originalHeaders.Get("Message-ID")); id != "" {
+			return id
+		}
+		if id := emailIdFromVerpHeader(originalHeaders.Get("Return-Path")); id != "" {
+			return id
+		}
+	}
+	if id := emailIdFromVerpHeader(originalMailFrom); id != "" {
+		return id
+	}
+	for _, key := range []string{"X-Original-To", "Delivered-To", "To"} {
+		if id := emailIdFromVerpHeader(reportHeaders.Get(key)); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+func emailIdFromVerpHeader(value string) string {
+	if address, err := mail.ParseAddress(value); err == nil {
+		value = address.Address
+	}
+	return mailsender.EmailIdFromVerpAddress(value)
+}
@@ -0,0 +1,106 @@
+package bouncesrv
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const tstHardBounceDsn = "From: MAILER-DAEMON@mx.example.com\r\n" +
+	"To: bounces+VERP-ID@example.com\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"BOUNDARY\"\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Your message could not be delivered.\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.com\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; someone@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"From: mailer@example.com\r\n" +
+	"To: someone@example.com\r\n" +
+	"Message-ID: <MESSAGE-ID@example.com>\r\n" +
+	"--BOUNDARY--\r\n"
+
+const tstDelayedDsn = "From: MAILER-DAEMON@mx.example.com\r\n" +
+	"To: bounces+VERP-ID@example.com\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"BOUNDARY\"\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.com\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; someone@example.com\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.4.1\r\n" +
+	"--BOUNDARY--\r\n"
+
+const tstAbuseArf = "From: feedback@isp.example.net\r\n" +
+	"To: abuse@example.com\r\n" +
+	"Content-Type: multipart/report; report-type=feedback-report; boundary=\"BOUNDARY\"\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"This is an email abuse report.\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: message/feedback-report\r\n" +
+	"\r\n" +
+	"Feedback-Type: abuse\r\n" +
+	"User-Agent: SomeGenerator/1.0\r\n" +
+	"Version: 1\r\n" +
+	"Original-Mail-From: <bounces+ARF-ID@example.com>\r\n" +
+	"Original-Rcpt-To: <someone@isp.example.net>\r\n" +
+	"--BOUNDARY--\r\n"
+
+func TestParseReport_HardBounce_ShouldCorrelateViaMessageId(t *testing.T) {
+	report, err := ParseReport(strings.NewReader(tstHardBounceDsn))
+	require.Nil(t, err)
+	require.Equal(t, KindHardBounce, report.Kind)
+	require.Equal(t, "MESSAGE-ID", report.EmailID)
+	require.Equal(t, "someone@example.com", report.Recipient)
+	require.Equal(t, "5.1.1", report.Status)
+	require.Equal(t, "550 5.1.1 user unknown", report.Diagnostic)
+}
+
+func TestParseReport_HardBounceWithoutOriginalHeaders_ShouldCorrelateViaVerp(t *testing.T) {
+	message := strings.Replace(tstHardBounceDsn, "Message-ID: <MESSAGE-ID@example.com>\r\n", "", 1)
+	report, err := ParseReport(strings.NewReader(message))
+	require.Nil(t, err)
+	require.Equal(t, "VERP-ID", report.EmailID)
+}
+
+func TestParseReport_Delayed_ShouldBeSoft(t *testing.T) {
+	report, err := ParseReport(strings.NewReader(tstDelayedDsn))
+	require.Nil(t, err)
+	require.Equal(t, KindSoftBounce, report.Kind)
+	require.Equal(t, "4.4.1", report.Status)
+	require.Equal(t, "VERP-ID", report.EmailID)
+}
+
+func TestParseReport_AbuseFeedback_ShouldBeComplaint(t *testing.T) {
+	report, err := ParseReport(strings.NewReader(tstAbuseArf))
+	require.Nil(t, err)
+	require.Equal(t, KindComplaint, report.Kind)
+	require.Equal(t, "abuse", report.FeedbackType)
+	require.Equal(t, "someone@isp.example.net", report.Recipient)
+	require.Equal(t, "ARF-ID", report.EmailID)
+}
+
+func TestParseReport_PlainEmail_ShouldFail(t *testing.T) {
+	_, err := ParseReport(strings.NewReader("From: someone@example.com\r\nSubject: hi\r\n\r\nhello\r\n"))
+	require.NotNil(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), ErrNotAReport.Error()))
+}
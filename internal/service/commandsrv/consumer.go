@@ -0,0 +1,187 @@
+package commandsrv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/commands"
+	"github.com/StephanHCB/go-mailer-service/api/v1/email"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/messaging"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// how long to wait before fetching again after the broker returned an error
+const fetchErrorBackoff = 5 * time.Second
+
+// how long to wait for the broker to accept a commit
+const commitTimeout = 10 * time.Second
+
+// message header with the request id, same name as the http header
+const RequestIdHeader = "X-Request-Id"
+
+// Consumer reads send-email commands from the broker and passes them through the email service.
+//
+// Failed commands are published to the error topic. Every command is committed once it has been handled,
+// whether it succeeded or not, so a broken command cannot block the topic.
+type Consumer struct {
+	broker     messaging.Broker
+	service    emailsrv.EmailService
+	errorTopic string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func StartConsumer(broker messaging.Broker, service emailsrv.EmailService, errorTopic string) *Consumer {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Consumer{
+		broker:     broker,
+		service:    service,
+		errorTopic: errorTopic,
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	log.Info().Msg("starting send-email command consumer")
+	go c.run()
+	return c
+}
+
+// Stop waits for the command currently being processed, if any, and then stops fetching.
+func (c *Consumer) Stop() {
+	c.cancel()
+	<-c.done
+	log.Info().Msg("send-email command consumer stopped")
+}
+
+func (c *Consumer) run() {
+	defer close(c.done)
+	sublogger := log.Logger.With().Str("component", "commands").Logger()
+	ctx := sublogger.WithContext(c.ctx)
+
+	for {
+		message, err := c.broker.Fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to fetch send-email command: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(fetchErrorBackoff):
+			}
+			continue
+		}
+
+		// neither processing nor committing may be interrupted by a shutdown, or we would commit half-done work,
+		// or get the command delivered again and send the email twice
+		c.Process(sublogger.WithContext(context.Background()), message)
+		c.commit(sublogger.WithContext(context.Background()), message)
+	}
+}
+
+func (c *Consumer) commit(ctx context.Context, message *messaging.Message) {
+	ctx, cancel := context.WithTimeout(ctx, commitTimeout)
+	defer cancel()
+	if err := c.broker.Commit(ctx, message); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to commit send-email command at offset %d: %v", message.Offset, err)
+	}
+}
+
+// Process handles a single command, publishing it to the error topic if it fails.
+func (c *Consumer) Process(ctx context.Context, message *messaging.Message) {
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, message.Headers), message.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.String("messaging.system", "kafka"), attribute.Int64("messaging.kafka.offset", message.Offset)))
+	defer span.End()
+
+	loggerContext := log.Ctx(ctx).With().Int64("offset", message.Offset).Str(logging.FieldTraceId, tracing.TraceId(ctx))
+	// producers on the broker are trusted to pass on the id of the request that caused the command
+	if requestId := message.Headers[RequestIdHeader]; requestId != "" {
+		ctx = tracing.WithRequestId(ctx, requestId)
+		loggerContext = loggerContext.Str(logging.FieldRequestId, requestId)
+	}
+	logger := loggerContext.Logger()
+	ctx = logger.WithContext(ctx)
+	// commands carry no token, so the audit log can only name the topic as the caller
+	ctx = identity.WithCaller(ctx, identity.Caller{Subject: "kafka:" + message.Topic})
+
+	errorDto := c.process(ctx, message.Value)
+	if errorDto == nil {
+		return
+	}
+	log.Ctx(ctx).Warn().Msgf("send-email command failed with %s %v", errorDto.Message, errorDto.Details)
+	span.SetStatus(codes.Error, errorDto.Message)
+
+	errorDto.Timestamp = time.Now().Format(time.RFC3339)
+	if json.Valid(message.Value) {
+		errorDto.Command = message.Value
+	} else {
+		errorDto.Command, _ = json.Marshal(string(message.Value))
+	}
+	value, err := json.Marshal(errorDto)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to render command error: %v", err)
+		return
+	}
+	err = c.publishError(ctx, &messaging.Message{Key: message.Key, Value: value, Headers: message.Headers})
+	if err != nil {
+		// the command is lost, but the log entry above has the details
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to publish to error topic %s: %v", c.errorTopic, err)
+	}
+}
+
+// publishError keeps the original headers, but replaces the trace context with our own
+func (c *Consumer) publishError(ctx context.Context, message *messaging.Message) error {
+	ctx, span := tracing.Tracer().Start(ctx, c.errorTopic+" send",
+		trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(attribute.String("messaging.system", "kafka")))
+	defer span.End()
+
+	headers := make(map[string]string, len(message.Headers))
+	for key, value := range message.Headers {
+		headers[key] = value
+	}
+	tracing.Inject(ctx, headers)
+	message.Headers = headers
+
+	err := c.broker.Publish(ctx, c.errorTopic, message)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func (c *Consumer) process(ctx context.Context, value []byte) *commands.CommandErrorDto {
+	dto := &email.EmailDto{}
+	if err := json.Unmarshal(value, dto); err != nil {
+		return &commands.CommandErrorDto{Message: apierrors.EmailParse.Code, Details: []string{err.Error()}}
+	}
+
+	mail := c.service.NewInstance(ctx)
+	if err := dto.MapToEmail(mail); err != nil {
+		return &commands.CommandErrorDto{Message: apierrors.EmailParse.Code, Details: []string{err.Error()}}
+	}
+
+	err := c.service.SendEmail(ctx, mail)
+	if err != nil {
+		var validationErr *emailsrv.ValidationError
+		if errors.As(err, &validationErr) {
+			return &commands.CommandErrorDto{Message: apierrors.EmailValidation.Code, Details: []string{validationErr.Reason}}
+		}
+		return &commands.CommandErrorDto{Message: apierrors.EmailSend.Code, Details: []string{err.Error()}}
+	}
+	log.Ctx(ctx).Info().Msgf("accepted send-email command as email %s", mail.ID)
+	return nil
+}
@@ -0,0 +1,55 @@
+package commandsrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/messaging"
+	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const tstCommandTopic = "commands"
+const tstErrorTopic = "errors"
+
+// tstSlowService holds SendEmail until it is released, so tests can stop the consumer in the middle of a command
+type tstSlowService struct {
+	emailsrv.EmailService
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *tstSlowService) NewInstance(_ context.Context) *entity.Email {
+	return &entity.Email{}
+}
+
+func (s *tstSlowService) SendEmail(_ context.Context, email *entity.Email) error {
+	close(s.started)
+	<-s.release
+	email.ID = "mail1"
+	return nil
+}
+
+func TestConsumer_StopShouldCommitCommandInProgress(t *testing.T) {
+	broker := messaging.CreateInMemoryBroker(tstCommandTopic)
+	service := &tstSlowService{started: make(chan struct{}), release: make(chan struct{})}
+	cut := StartConsumer(broker, service, tstErrorTopic)
+
+	command := `{"to_address":"someone@example.com","subject":"Hello","body":"World"}`
+	require.Nil(t, broker.Publish(context.Background(), tstCommandTopic, &messaging.Message{Value: []byte(command)}))
+	<-service.started
+
+	stopped := make(chan struct{})
+	go func() {
+		cut.Stop()
+		close(stopped)
+	}()
+	// let Stop cancel the consumer before the command is done
+	time.Sleep(50 * time.Millisecond)
+	close(service.release)
+	<-stopped
+
+	require.Len(t, broker.Committed(), 1)
+	require.Empty(t, broker.Published(tstErrorTopic))
+}
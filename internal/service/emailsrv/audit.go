@@ -0,0 +1,56 @@
+package emailsrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/mailsender"
+	"github.com/rs/zerolog/log"
+	"net/textproto"
+)
+
+// recordAudit adds a send attempt to the audit log, if there is one.
+//
+// Failing to write the audit log does not fail the send attempt, but is logged as an error.
+func (e *EmailServiceImpl) recordAudit(ctx context.Context, email *entity.Email, outcome audit.Outcome, detail string) {
+	if e.auditLog == nil {
+		return
+	}
+	_, err := e.auditLog.Append(ctx, audit.Entry{
+		EmailId:    email.ID,
+		Recipients: []string{email.ToAddress},
+		Outcome:    outcome,
+		Detail:     detail,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to write audit log entry %s for email %s: %v", outcome, email.ID, err)
+	}
+}
+
+// the audit log is kept longer than the emails and cannot be changed, so details never repeat an error as it is,
+// smtp replies and database errors may contain the recipient address
+
+func rejectedDetail(err error) string {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Reason
+	}
+	return "validation could not be completed"
+}
+
+func failedDetail(err error) string {
+	kind := "temporary failure"
+	if mailsender.IsPermanent(err) {
+		kind = "permanent failure"
+	}
+	var reply *textproto.Error
+	switch {
+	case errors.As(err, &reply):
+		return fmt.Sprintf("%s, smtp reply %d", kind, reply.Code)
+	case errors.Is(err, context.DeadlineExceeded):
+		return kind + ", timed out"
+	}
+	return kind
+}
@@ -2,19 +2,44 @@ package emailsrv
 
 import (
 	"context"
+	"errors"
 	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/mailsender"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/StephanHCB/go-mailer-service/internal/service/eventsrv"
 	"github.com/armon/go-metrics"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
 	"time"
 )
 
 type EmailServiceImpl struct {
-	// TODO mail sending repository
-	// TODO messaging repository
+	repository dbrepo.Repository
+	sender     mailsender.MailSender
+	// nil disables auditing
+	auditLog *audit.Log
+
+	// ids of emails currently being delivered by this instance, so cancelling and
+	// dispatching cannot race each other
+	mu       sync.Mutex
+	inFlight map[string]struct{}
 }
 
 func Create() EmailService {
-	service := &EmailServiceImpl{}
+	service := &EmailServiceImpl{
+		repository: database.GetRepository(),
+		sender:     mailsender.Get(),
+		auditLog:   audit.Get(),
+		inFlight:   make(map[string]struct{}),
+	}
 	return service
 }
 
@@ -22,18 +47,213 @@ func (e *EmailServiceImpl) NewInstance(ctx context.Context) *entity.Email {
 	return &entity.Email{}
 }
 
-func (e *EmailServiceImpl) SendEmail(ctx context.Context, email *entity.Email) error {
-	err := validate(email)
+func (e *EmailServiceImpl) SendEmail(ctx context.Context, email *entity.Email) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "EmailService.SendEmail")
+	defer func() { endSpan(span, err) }()
+
+	now := time.Now()
+	err = validate(email, now)
+	if err == nil {
+		err = validateNotSuppressed(ctx, e.repository, email)
+	}
 	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			recordRejected(validationErr)
+		}
 		log.Ctx(ctx).Warn().Msgf("business validation for email failed - rejected: %v", err.Error())
+		e.recordAudit(ctx, email, audit.OutcomeRejected, rejectedDetail(err))
 		return err
 	}
 
 	defer metrics.MeasureSince([]string{"SendEmail"}, time.Now())
 
-	// TODO implement call to send email
+	email.ID = uuid.New().String()
+	email.CreatedAt = now
+	email.RequestID = tracing.RequestId(ctx)
+	email.SubmittedBy = identity.CallerOf(ctx).Subject
+	span.SetAttributes(attribute.String("email.id", email.ID))
+
+	// immediate emails are also stored as scheduled first, so the scheduler picks them up
+	// should we crash before delivery completes
+	email.Status = entity.EmailStatusScheduled
+	immediate := !email.SendAt.After(now)
+	if immediate {
+		e.claim(email.ID)
+		defer e.release(email.ID)
+	}
+	err = e.repository.AddEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	eventsrv.Publish(ctx, entity.EventTypeAccepted, email)
+	recordAccepted(email, !immediate)
+
+	if !immediate {
+		e.recordAudit(ctx, email, audit.OutcomeAccepted, "scheduled for "+email.SendAt.Format(time.RFC3339))
+		log.Ctx(ctx).Info().Msgf("email %s scheduled for %s", email.ID, email.SendAt.Format(time.RFC3339))
+		return nil
+	}
+
+	e.recordAudit(ctx, email, audit.OutcomeAccepted, "")
+
+	return e.deliver(ctx, email)
+}
 
-	// TODO implement messaging call
+func (e *EmailServiceImpl) CancelEmail(ctx context.Context, caller identity.Caller, id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
+	email, err := e.getEmailFor(ctx, caller, id)
+	if err != nil {
+		return err
+	}
+	if _, busy := e.inFlight[id]; busy {
+		return ErrNotPending
+	}
+	if email.Status != entity.EmailStatusScheduled {
+		return ErrNotPending
+	}
+
+	email.Status = entity.EmailStatusCancelled
+	err = e.repository.UpdateEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	log.Ctx(ctx).Info().Msgf("scheduled email %s cancelled", id)
 	return nil
 }
+
+func (e *EmailServiceImpl) DispatchDueEmails(ctx context.Context) error {
+	due, err := e.repository.FindDueEmails(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	recordQueueDepth(len(due))
+
+	for _, candidate := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err = e.dispatchIfStillPending(ctx, candidate.ID)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msgf("failed to dispatch scheduled email %s: %v", candidate.ID, err)
+		}
+	}
+	return nil
+}
+
+func (e *EmailServiceImpl) dispatchIfStillPending(ctx context.Context, id string) error {
+	email, err := e.claimIfPending(ctx, id)
+	if err != nil || email == nil {
+		return err
+	}
+	defer e.release(id)
+
+	return e.deliver(ctx, email)
+}
+
+// deliver sends the email and records the outcome.
+func (e *EmailServiceImpl) deliver(ctx context.Context, email *entity.Email) error {
+	email.Attempts++
+	sendErr := e.send(ctx, email)
+	if sendErr != nil {
+		email.Status = entity.EmailStatusFailed
+		email.StatusDetail = sendErr.Error()
+		recordFailed(email, mailsender.IsPermanent(sendErr))
+	} else {
+		email.Status = entity.EmailStatusSent
+		email.SentAt = time.Now()
+		recordDelivered(email)
+	}
+
+	err := e.repository.UpdateEmail(ctx, email)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to record status %s for email %s: %v", email.Status, email.ID, err)
+	}
+
+	if sendErr != nil {
+		e.recordAudit(ctx, email, audit.OutcomeFailed, failedDetail(sendErr))
+		eventsrv.Publish(ctx, entity.EventTypeFailed, email)
+	} else {
+		e.recordAudit(ctx, email, audit.OutcomeSent, "")
+		eventsrv.Publish(ctx, entity.EventTypeSent, email)
+	}
+	return sendErr
+}
+
+func (e *EmailServiceImpl) send(ctx context.Context, email *entity.Email) error {
+	ctx, span := tracing.Tracer().Start(ctx, "MailSender.Send", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("email.id", email.ID), attribute.Int("email.attempt", email.Attempts)))
+	err := e.sender.Send(ctx, email)
+	endSpan(span, err)
+	return err
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (e *EmailServiceImpl) getEmail(ctx context.Context, id string) (*entity.Email, error) {
+	email, err := e.repository.GetEmail(ctx, id)
+	if errors.Is(err, dbrepo.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return email, err
+}
+
+// getEmailFor is getEmail for callers that may only access the emails they submitted, unless they are admin.
+//
+// Emails that were submitted anonymously are admin only.
+func (e *EmailServiceImpl) getEmailFor(ctx context.Context, caller identity.Caller, id string) (*entity.Email, error) {
+	email, err := e.getEmail(ctx, id)
+	if caller.HasRole(identity.RoleAdmin) {
+		return email, err
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if email == nil || email.SubmittedBy == "" || email.SubmittedBy != caller.Subject {
+		return nil, ErrForbidden
+	}
+	return email, nil
+}
+
+// --- in flight tracking ---
+
+func (e *EmailServiceImpl) claim(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.inFlight[id] = struct{}{}
+}
+
+// claimIfPending re-reads the email and claims it, unless it was cancelled, sent, or is claimed already.
+//
+// Returns nil if there is nothing to do.
+func (e *EmailServiceImpl) claimIfPending(ctx context.Context, id string) (*entity.Email, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, busy := e.inFlight[id]; busy {
+		return nil, nil
+	}
+	email, err := e.getEmail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if email.Status != entity.EmailStatusScheduled {
+		return nil, nil
+	}
+	e.inFlight[id] = struct{}{}
+	return email, nil
+}
+
+func (e *EmailServiceImpl) release(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.inFlight, id)
+}
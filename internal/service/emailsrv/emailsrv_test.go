@@ -0,0 +1,209 @@
+package emailsrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/inmemorydb"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/mailsender"
+	"github.com/stretchr/testify/require"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+var tstAdmin = identity.Caller{Subject: "admin", Roles: []string{identity.RoleAdmin}}
+
+func tstCreateService(t *testing.T) (*EmailServiceImpl, *mailsender.InMemorySender) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	repository := inmemorydb.Create()
+	require.Nil(t, repository.Open())
+	sender := mailsender.CreateInMemorySender()
+	return &EmailServiceImpl{
+		repository: repository,
+		sender:     sender,
+		inFlight:   make(map[string]struct{}),
+	}, sender
+}
+
+func tstEmail(sendAt time.Time) *entity.Email {
+	return &entity.Email{
+		ToAddress: "someone@example.com",
+		Subject:   "Hello",
+		Body:      "World",
+		SendAt:    sendAt,
+	}
+}
+
+func TestDispatchDueEmails_ShouldOnlySendDueEmails(t *testing.T) {
+	cut, sender := tstCreateService(t)
+	ctx := context.Background()
+
+	soon := tstEmail(time.Now().Add(50 * time.Millisecond))
+	require.Nil(t, cut.SendEmail(ctx, soon))
+	later := tstEmail(time.Now().Add(time.Hour))
+	require.Nil(t, cut.SendEmail(ctx, later))
+
+	require.Nil(t, cut.DispatchDueEmails(ctx))
+	require.Empty(t, sender.Sent())
+
+	time.Sleep(100 * time.Millisecond)
+	require.Nil(t, cut.DispatchDueEmails(ctx))
+	require.Len(t, sender.Sent(), 1)
+	require.Equal(t, soon.ID, sender.Sent()[0].ID)
+
+	stored, err := cut.repository.GetEmail(ctx, soon.ID)
+	require.Nil(t, err)
+	require.Equal(t, entity.EmailStatusSent, stored.Status)
+
+	// sending is not repeated
+	require.Nil(t, cut.DispatchDueEmails(ctx))
+	require.Len(t, sender.Sent(), 1)
+}
+
+func TestDispatchDueEmails_ShouldSkipCancelledEmails(t *testing.T) {
+	cut, sender := tstCreateService(t)
+	ctx := context.Background()
+
+	email := tstEmail(time.Now().Add(10 * time.Millisecond))
+	require.Nil(t, cut.SendEmail(ctx, email))
+	require.Nil(t, cut.CancelEmail(ctx, tstAdmin, email.ID))
+
+	time.Sleep(20 * time.Millisecond)
+	require.Nil(t, cut.DispatchDueEmails(ctx))
+	require.Empty(t, sender.Sent())
+	require.Equal(t, ErrNotPending, cut.CancelEmail(ctx, tstAdmin, email.ID))
+}
+
+func TestCancelEmail_ShouldRejectSentEmails(t *testing.T) {
+	cut, _ := tstCreateService(t)
+	ctx := context.Background()
+
+	email := tstEmail(time.Time{})
+	require.Nil(t, cut.SendEmail(ctx, email))
+	require.Equal(t, entity.EmailStatusSent, email.Status)
+
+	require.Equal(t, ErrNotPending, cut.CancelEmail(ctx, tstAdmin, email.ID))
+	require.Equal(t, ErrNotFound, cut.CancelEmail(ctx, tstAdmin, "unknown"))
+}
+
+func TestCancelEmail_ShouldOnlyAllowSubmitterAndAdmin(t *testing.T) {
+	cut, _ := tstCreateService(t)
+	submitter := identity.Caller{Subject: "1234567890"}
+	other := identity.Caller{Subject: "2345678901"}
+	ctx := identity.WithCaller(context.Background(), submitter)
+
+	email := tstEmail(time.Now().Add(time.Hour))
+	require.Nil(t, cut.SendEmail(ctx, email))
+	anonymous := tstEmail(time.Now().Add(time.Hour))
+	require.Nil(t, cut.SendEmail(context.Background(), anonymous))
+
+	require.Equal(t, ErrForbidden, cut.CancelEmail(ctx, other, email.ID))
+	// other callers cannot tell unknown ids from emails they may not see
+	require.Equal(t, ErrForbidden, cut.CancelEmail(ctx, other, "unknown"))
+	require.Equal(t, ErrForbidden, cut.CancelEmail(ctx, submitter, anonymous.ID))
+	require.Nil(t, cut.CancelEmail(ctx, submitter, email.ID))
+	require.Nil(t, cut.CancelEmail(ctx, tstAdmin, anonymous.ID))
+}
+
+func TestSendEmail_TemporaryFailureShouldFail(t *testing.T) {
+	cut, sender := tstCreateService(t)
+	sender.FailWith(&textproto.Error{Code: 451, Msg: "try again later"})
+
+	email := tstEmail(time.Time{})
+	require.NotNil(t, cut.SendEmail(context.Background(), email))
+	require.Equal(t, entity.EmailStatusFailed, email.Status)
+	require.Equal(t, 1, email.Attempts)
+}
+
+func TestSendEmail_AuditDetailShouldNotRepeatTheError(t *testing.T) {
+	cut, sender := tstCreateService(t)
+	auditLog, err := audit.Create(audit.CreateInMemorySink(), []byte("0123456789abcdef0123456789abcdef"), true)
+	require.Nil(t, err)
+	cut.auditLog = auditLog
+	sender.FailWith(&textproto.Error{Code: 550, Msg: "no such user someone@example.com"})
+
+	email := tstEmail(time.Time{})
+	require.NotNil(t, cut.SendEmail(context.Background(), email))
+
+	entries, err := auditLog.Query(audit.Filter{Outcome: audit.OutcomeFailed})
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "permanent failure, smtp reply 550", entries[0].Detail)
+}
+
+func TestSendEmail_ShouldRejectBeyondHorizon(t *testing.T) {
+	cut, _ := tstCreateService(t)
+
+	email := tstEmail(time.Now().Add(configuration.SchedulerMaxHorizon() + time.Minute))
+	err := cut.SendEmail(context.Background(), email)
+	require.NotNil(t, err)
+	require.IsType(t, &ValidationError{}, err)
+}
+
+func TestSendEmail_ShouldRejectMissingFields(t *testing.T) {
+	cut, sender := tstCreateService(t)
+
+	for _, email := range []*entity.Email{
+		{},
+		{ToAddress: "not an address", Subject: "Hello", Body: "World"},
+		{ToAddress: "someone@example.com", Body: "World"},
+		{ToAddress: "someone@example.com", Subject: "Hello"},
+	} {
+		err := cut.SendEmail(context.Background(), email)
+		require.NotNil(t, err)
+		require.IsType(t, &ValidationError{}, err)
+	}
+	require.Empty(t, sender.Sent())
+}
+
+func TestSendEmail_ShouldRejectSuppressedAddress(t *testing.T) {
+	cut, sender := tstCreateService(t)
+	ctx := context.Background()
+	require.Nil(t, cut.repository.AddSuppression(ctx, &entity.Suppression{Address: "Someone@Example.com"}))
+
+	err := cut.SendEmail(ctx, tstEmail(time.Time{}))
+	require.NotNil(t, err)
+	require.IsType(t, &ValidationError{}, err)
+	require.Empty(t, sender.Sent())
+}
+
+func TestListEmails_ShouldPageWithCursor(t *testing.T) {
+	cut, _ := tstCreateService(t)
+	ctx := context.Background()
+
+	ids := []string{}
+	for i := 0; i < 5; i++ {
+		email := tstEmail(time.Now().Add(time.Hour))
+		require.Nil(t, cut.SendEmail(ctx, email))
+		ids = append(ids, email.ID)
+	}
+
+	listed := []string{}
+	cursor := ""
+	for page := 0; page < 3; page++ {
+		emails, next, err := cut.ListEmails(ctx, cursor, 2)
+		require.Nil(t, err)
+		for _, email := range emails {
+			listed = append(listed, email.ID)
+		}
+		if page < 2 {
+			require.Len(t, emails, 2)
+			require.NotEmpty(t, next)
+		} else {
+			require.Len(t, emails, 1)
+			require.Empty(t, next)
+		}
+		cursor = next
+	}
+	require.ElementsMatch(t, ids, listed)
+}
+
+func TestListEmails_ShouldRejectInvalidCursor(t *testing.T) {
+	cut, _ := tstCreateService(t)
+
+	_, _, err := cut.ListEmails(context.Background(), "not a cursor", 10)
+	require.Equal(t, ErrInvalidCursor, err)
+}
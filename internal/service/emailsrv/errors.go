@@ -0,0 +1,23 @@
+package emailsrv
+
+import "errors"
+
+var (
+	ErrNotFound   = errors.New("email not found")
+	ErrNotPending = errors.New("email is no longer pending")
+	// the caller neither submitted the email nor is admin, or the email does not exist
+	ErrForbidden = errors.New("email was not submitted by the caller")
+	// the cursor was not returned by ListEmails
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// ValidationError is returned by SendEmail when business validation fails.
+type ValidationError struct {
+	Reason string
+	// short machine readable reason with a bounded set of values, used as a metrics label
+	Code string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
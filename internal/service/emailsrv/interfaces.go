@@ -3,10 +3,32 @@ package emailsrv
 import (
 	"context"
 	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
 )
 
 type EmailService interface {
 	NewInstance(ctx context.Context) *entity.Email
 
+	// SendEmail sends the email right away, or stores it for later if SendAt is in the future.
 	SendEmail(ctx context.Context, email *entity.Email) error
+
+	// GetEmail returns the email with the given id.
+	//
+	// Only the caller that submitted the email, or an admin, may read it. Anyone else gets ErrForbidden,
+	// also for ids that do not exist. Admins get ErrNotFound for those.
+	GetEmail(ctx context.Context, caller identity.Caller, id string) (*entity.Email, error)
+
+	// ListEmails returns up to limit emails, oldest first, continuing after cursor. Blank cursor starts at the oldest.
+	//
+	// Also returns the cursor for the next page, which is blank if there are no more emails.
+	ListEmails(ctx context.Context, cursor string, limit int) ([]*entity.Email, string, error)
+
+	// CancelEmail cancels a scheduled email, which is only possible while it is still pending.
+	//
+	// Only the caller that submitted the email, or an admin, may cancel it. Like for GetEmail, anyone else
+	// gets ErrForbidden, also for ids that do not exist, so they cannot find out which ids exist.
+	CancelEmail(ctx context.Context, caller identity.Caller, id string) error
+
+	// DispatchDueEmails sends all scheduled emails that are due. Called periodically by the Scheduler.
+	DispatchDueEmails(ctx context.Context) error
 }
@@ -0,0 +1,84 @@
+package emailsrv
+
+import (
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/armon/go-metrics"
+	"net/mail"
+	"strings"
+)
+
+const otherDomainLabel = "other"
+
+// domainLabel returns the recipient domain if it is one of the configured domains, other otherwise,
+// so the number of label values stays bounded no matter who we send to.
+func domainLabel(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return otherDomainLabel
+	}
+	domain := strings.ToLower(strings.TrimRight(address[at+1:], "> "))
+	for _, known := range configuration.MetricsDomains() {
+		if domain == known {
+			return domain
+		}
+	}
+	return otherDomainLabel
+}
+
+func recipientCount(toAddress string) int {
+	addresses, err := mail.ParseAddressList(toAddress)
+	if err != nil || len(addresses) == 0 {
+		return 1
+	}
+	return len(addresses)
+}
+
+func recordAccepted(email *entity.Email, scheduled bool) {
+	labels := []metrics.Label{
+		{Name: "domain", Value: domainLabel(email.ToAddress)},
+		{Name: "scheduled", Value: boolLabel(scheduled)},
+	}
+	metrics.IncrCounterWithLabels([]string{"email", "accepted"}, 1, labels)
+	metrics.AddSample([]string{"email", "size", "bytes"}, float32(len(email.Subject)+len(email.Body)))
+	metrics.AddSample([]string{"email", "recipients"}, float32(recipientCount(email.ToAddress)))
+}
+
+func recordRejected(err *ValidationError) {
+	metrics.IncrCounterWithLabels([]string{"email", "rejected"}, 1, []metrics.Label{{Name: "reason", Value: err.Code}})
+}
+
+// latency is measured from accepting the email, or from the time it was scheduled for, whichever is later
+func recordDelivered(email *entity.Email) {
+	labels := []metrics.Label{{Name: "domain", Value: domainLabel(email.ToAddress)}}
+	metrics.IncrCounterWithLabels([]string{"email", "delivered"}, 1, labels)
+	start := email.CreatedAt
+	if email.SendAt.After(start) {
+		start = email.SendAt
+	}
+	metrics.MeasureSinceWithLabels([]string{"email", "delivery", "latency"}, start, labels)
+}
+
+func recordFailed(email *entity.Email, permanent bool) {
+	kind := "transient"
+	if permanent {
+		kind = "permanent"
+	}
+	labels := []metrics.Label{
+		{Name: "domain", Value: domainLabel(email.ToAddress)},
+		{Name: "kind", Value: kind},
+	}
+	metrics.IncrCounterWithLabels([]string{"email", "failed"}, 1, labels)
+}
+
+// the number of emails that are due, but not yet dispatched, at the start of a dispatch run
+func recordQueueDepth(due int) {
+	metrics.SetGauge([]string{"email", "queue", "depth"}, float32(due))
+}
+
+func boolLabel(value bool) string {
+	if value {
+		return "true"
+	}
+	return "false"
+}
@@ -0,0 +1,79 @@
+package emailsrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/armon/go-metrics"
+	"github.com/stretchr/testify/require"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+func tstInmemSink(t *testing.T) *metrics.InmemSink {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	conf := metrics.DefaultConfig("test")
+	conf.EnableHostname = false
+	conf.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(conf, sink)
+	require.Nil(t, err)
+	return sink
+}
+
+func tstCounter(sink *metrics.InmemSink, key string) int {
+	return sink.Data()[0].Counters[key].Count
+}
+
+func TestDomainLabel_ShouldBoundCardinality(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+
+	require.Equal(t, "gmail.com", domainLabel("someone@GMail.com"))
+	require.Equal(t, "web.de", domainLabel("Someone <someone@web.de>"))
+	require.Equal(t, "other", domainLabel("someone@example.com"))
+	require.Equal(t, "other", domainLabel("not an address"))
+}
+
+func TestMetrics_ShouldCountAcceptedAndDelivered(t *testing.T) {
+	sink := tstInmemSink(t)
+	cut, _ := tstCreateService(t)
+	ctx := context.Background()
+
+	email := tstEmail(time.Time{})
+	email.ToAddress = "one@gmail.com, two@example.com"
+	require.Nil(t, cut.SendEmail(ctx, email))
+
+	require.Equal(t, 1, tstCounter(sink, "test.email.accepted;domain=other;scheduled=false"))
+	require.Equal(t, 1, tstCounter(sink, "test.email.delivered;domain=other"))
+	data := sink.Data()[0]
+	require.Equal(t, float64(10), data.Samples["test.email.size.bytes"].Sum)
+	require.Equal(t, float64(2), data.Samples["test.email.recipients"].Sum)
+	require.Equal(t, 1, data.Samples["test.email.delivery.latency;domain=other"].Count)
+}
+
+func TestMetrics_ShouldCountRejectionsByReason(t *testing.T) {
+	sink := tstInmemSink(t)
+	cut, _ := tstCreateService(t)
+	ctx := context.Background()
+	require.Nil(t, cut.repository.AddSuppression(ctx, &entity.Suppression{Address: "someone@example.com"}))
+
+	require.NotNil(t, cut.SendEmail(ctx, tstEmail(time.Time{})))
+	require.NotNil(t, cut.SendEmail(ctx, tstEmail(time.Now().Add(configuration.SchedulerMaxHorizon()+time.Minute))))
+
+	require.Equal(t, 1, tstCounter(sink, "test.email.rejected;reason=suppressed"))
+	require.Equal(t, 1, tstCounter(sink, "test.email.rejected;reason=horizon"))
+}
+
+func TestMetrics_ShouldCountFailuresByKind(t *testing.T) {
+	sink := tstInmemSink(t)
+	cut, sender := tstCreateService(t)
+	ctx := context.Background()
+	sender.FailWith(&textproto.Error{Code: 550, Msg: "no such user"}, &textproto.Error{Code: 451, Msg: "try again later"})
+
+	require.NotNil(t, cut.SendEmail(ctx, tstEmail(time.Time{})))
+	require.NotNil(t, cut.SendEmail(ctx, tstEmail(time.Time{})))
+
+	require.Equal(t, 1, tstCounter(sink, "test.email.failed;domain=other;kind=permanent"))
+	require.Equal(t, 1, tstCounter(sink, "test.email.failed;domain=other;kind=transient"))
+	require.Empty(t, sender.Sent())
+}
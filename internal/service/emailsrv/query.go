@@ -0,0 +1,58 @@
+package emailsrv
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"strings"
+	"time"
+)
+
+func (e *EmailServiceImpl) GetEmail(ctx context.Context, caller identity.Caller, id string) (*entity.Email, error) {
+	return e.getEmailFor(ctx, caller, id)
+}
+
+func (e *EmailServiceImpl) ListEmails(ctx context.Context, cursor string, limit int) ([]*entity.Email, string, error) {
+	afterCreatedAt, afterId, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	// one more than asked for tells us whether there is a next page
+	emails, err := e.repository.ListEmails(ctx, afterCreatedAt, afterId, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(emails) <= limit {
+		return emails, "", nil
+	}
+	emails = emails[:limit]
+	last := emails[limit-1]
+	return emails, encodeCursor(last.CreatedAt, last.ID), nil
+}
+
+// the cursor is opaque to clients, it points at the last email of the previous page, so emails added or removed
+// in the meantime do not shift the pages
+
+func encodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + " " + id))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+	if cursor == "" {
+		return time.Time{}, "", nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(decoded), " ", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return createdAt, parts[1], nil
+}
@@ -0,0 +1,61 @@
+package emailsrv
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// Scheduler periodically dispatches scheduled emails that have become due.
+type Scheduler struct {
+	service  EmailService
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func StartScheduler(service EmailService, interval time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		service:  service,
+		interval: interval,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	log.Info().Msgf("starting email scheduler with poll interval %v", interval)
+	go s.run(ctx)
+	return s
+}
+
+// Stop signals the scheduler to stop and waits for the current dispatch run to finish.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.done
+	log.Info().Msg("email scheduler stopped")
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	// also dispatch right away, there may be emails that became due while we were down
+	s.dispatch(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatch(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) dispatch(ctx context.Context) {
+	sublogger := log.Logger.With().Str("component", "scheduler").Logger()
+	err := s.service.DispatchDueEmails(sublogger.WithContext(ctx))
+	if err != nil && ctx.Err() == nil {
+		log.Error().Err(err).Msgf("scheduled email dispatch failed: %v", err)
+	}
+}
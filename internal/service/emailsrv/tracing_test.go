@@ -0,0 +1,44 @@
+package emailsrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+func TestSendEmail_ShouldCreateSpansForServiceAndTransport(t *testing.T) {
+	recorder := tracing.SetupForTesting()
+	cut, _ := tstCreateService(t)
+
+	email := tstEmail(time.Time{})
+	require.Nil(t, cut.SendEmail(context.Background(), email))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	send, service := spans[0], spans[1]
+	require.Equal(t, "MailSender.Send", send.Name())
+	require.Equal(t, trace.SpanKindClient, send.SpanKind())
+	require.Equal(t, "EmailService.SendEmail", service.Name())
+	require.Equal(t, service.SpanContext().SpanID(), send.Parent().SpanID())
+	require.Equal(t, service.SpanContext().TraceID(), send.SpanContext().TraceID())
+}
+
+func TestSendEmail_ShouldRecordTransportErrorsOnSpan(t *testing.T) {
+	recorder := tracing.SetupForTesting()
+	cut, sender := tstCreateService(t)
+	sender.FailWith(&textproto.Error{Code: 550, Msg: "no such user"})
+
+	require.NotNil(t, cut.SendEmail(context.Background(), tstEmail(time.Time{})))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		require.Equal(t, codes.Error, span.Status().Code)
+		require.Len(t, span.Events(), 1)
+	}
+}
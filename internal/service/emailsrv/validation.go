@@ -1,10 +1,49 @@
 package emailsrv
 
-import "github.com/StephanHCB/go-mailer-service/internal/entity"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"net/mail"
+	"time"
+)
+
+func validate(email *entity.Email, now time.Time) error {
+	// the api spec covers these for REST, but not when request validation is switched off,
+	// and not for kafka commands or grpc requests
+	if _, err := mail.ParseAddressList(email.ToAddress); err != nil {
+		return &ValidationError{Reason: "to_address must be an email address", Code: "to_address"}
+	}
+	if email.Subject == "" {
+		return &ValidationError{Reason: "subject is required", Code: "subject"}
+	}
+	if email.Body == "" {
+		return &ValidationError{Reason: "body is required", Code: "body"}
+	}
 
-func validate(email *entity.Email) error {
 	// some business validation
 
 	// example: email address must not be @mailinator.com
+
+	if !email.SendAt.IsZero() {
+		horizon := configuration.SchedulerMaxHorizon()
+		if email.SendAt.After(now.Add(horizon)) {
+			return &ValidationError{Reason: fmt.Sprintf("send_at must not be more than %v in the future", horizon), Code: "horizon"}
+		}
+	}
 	return nil
 }
+
+func validateNotSuppressed(ctx context.Context, repository dbrepo.Repository, email *entity.Email) error {
+	_, err := repository.GetSuppression(ctx, email.ToAddress)
+	if err == nil {
+		return &ValidationError{Reason: "recipient address is suppressed because of earlier hard bounces", Code: "suppressed"}
+	}
+	if errors.Is(err, dbrepo.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package eventsrv
+
+import (
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/api/v1/events"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"time"
+)
+
+const SpecVersion = "1.0"
+
+const StructuredContentType = "application/cloudevents+json"
+const DataContentType = "application/json"
+
+// header prefixes for binary mode, as defined by the http and kafka protocol bindings
+const HttpHeaderPrefix = "ce-"
+const KafkaHeaderPrefix = "ce_"
+
+type Mode string
+
+const (
+	// the whole event including its attributes is the message body
+	ModeStructured Mode = "structured"
+	// the attributes are headers, the message body is just the data
+	ModeBinary Mode = "binary"
+)
+
+// Encoded is a CloudEvent ready to be sent over some transport.
+type Encoded struct {
+	// includes Content-Type
+	Headers map[string]string
+	Body    []byte
+}
+
+// CloudEventType returns the versioned type attribute, e.g. com.example.mailer.email.sent.v1.
+func CloudEventType(eventType entity.EventType) string {
+	return configuration.EventsTypePrefix() + ".email." + string(eventType) + ".v1"
+}
+
+// Encode renders the event as a CloudEvent. headerPrefix is only used in binary mode.
+func Encode(event *entity.Event, mode Mode, headerPrefix string) (*Encoded, error) {
+	data, err := json.Marshal(mapEventToData(event))
+	if err != nil {
+		return nil, err
+	}
+	envelope := mapEventToEnvelope(event, data)
+
+	if mode == ModeBinary {
+		headers := map[string]string{
+			"Content-Type":               DataContentType,
+			headerPrefix + "specversion": envelope.SpecVersion,
+			headerPrefix + "id":          envelope.Id,
+			headerPrefix + "source":      envelope.Source,
+			headerPrefix + "type":        envelope.Type,
+			headerPrefix + "subject":     envelope.Subject,
+			headerPrefix + "time":        envelope.Time,
+		}
+		if envelope.RequestId != "" {
+			headers[headerPrefix+"requestid"] = envelope.RequestId
+		}
+		return &Encoded{Headers: headers, Body: data}, nil
+	}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoded{Headers: map[string]string{"Content-Type": StructuredContentType}, Body: body}, nil
+}
+
+func mapEventToEnvelope(event *entity.Event, data []byte) *events.CloudEventDto {
+	return &events.CloudEventDto{
+		SpecVersion:     SpecVersion,
+		Id:              event.ID,
+		Source:          configuration.ServiceName(),
+		Type:            CloudEventType(event.Type),
+		Subject:         event.EmailID,
+		Time:            event.Timestamp.Format(time.RFC3339),
+		DataContentType: DataContentType,
+		RequestId:       event.RequestID,
+		Data:            data,
+	}
+}
+
+func mapEventToData(event *entity.Event) interface{} {
+	switch event.Type {
+	case entity.EventTypeAccepted:
+		return &events.EmailAcceptedDataDto{
+			EmailId: event.EmailID,
+			SendAt:  formatOptionalTime(event.SendAt),
+		}
+	case entity.EventTypeSent:
+		return &events.EmailSentDataDto{
+			EmailId: event.EmailID,
+			SentAt:  formatOptionalTime(event.SentAt),
+		}
+	case entity.EventTypeFailed:
+		return &events.EmailFailedDataDto{
+			EmailId: event.EmailID,
+			Reason:  event.Detail,
+		}
+	default:
+		return &events.EmailBouncedDataDto{
+			EmailId: event.EmailID,
+			Reason:  event.Detail,
+		}
+	}
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
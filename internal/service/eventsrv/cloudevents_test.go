@@ -0,0 +1,104 @@
+package eventsrv
+
+import (
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/api/v1/events"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func tstEvent() *entity.Event {
+	return &entity.Event{
+		ID:        "ev1",
+		Type:      entity.EventTypeSent,
+		EmailID:   "mail1",
+		Status:    entity.EmailStatusSent,
+		SentAt:    time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+		Timestamp: time.Date(2020, 5, 1, 12, 0, 1, 0, time.UTC),
+		RequestID: "req1",
+	}
+}
+
+func TestEncode_Structured(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+
+	encoded, err := Encode(tstEvent(), ModeStructured, HttpHeaderPrefix)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"Content-Type": StructuredContentType}, encoded.Headers)
+
+	envelope := events.CloudEventDto{}
+	require.Nil(t, json.Unmarshal(encoded.Body, &envelope))
+	require.Equal(t, "1.0", envelope.SpecVersion)
+	require.Equal(t, "ev1", envelope.Id)
+	require.Equal(t, configuration.ServiceName(), envelope.Source)
+	require.Equal(t, "com.example.mailer.email.sent.v1", envelope.Type)
+	require.Equal(t, "mail1", envelope.Subject)
+	require.Equal(t, "2020-05-01T12:00:01Z", envelope.Time)
+	require.Equal(t, "req1", envelope.RequestId)
+
+	data := events.EmailSentDataDto{}
+	require.Nil(t, json.Unmarshal(envelope.Data, &data))
+	require.Equal(t, events.EmailSentDataDto{EmailId: "mail1", SentAt: "2020-05-01T12:00:00Z"}, data)
+}
+
+func TestEncode_Binary(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	event := tstEvent()
+	event.RequestID = ""
+
+	encoded, err := Encode(event, ModeBinary, KafkaHeaderPrefix)
+	require.Nil(t, err)
+	require.Equal(t, DataContentType, encoded.Headers["Content-Type"])
+	require.Equal(t, "1.0", encoded.Headers["ce_specversion"])
+	require.Equal(t, "ev1", encoded.Headers["ce_id"])
+	require.Equal(t, "com.example.mailer.email.sent.v1", encoded.Headers["ce_type"])
+	require.Equal(t, "2020-05-01T12:00:01Z", encoded.Headers["ce_time"])
+	_, hasRequestId := encoded.Headers["ce_requestid"]
+	require.False(t, hasRequestId)
+	require.JSONEq(t, `{"email_id":"mail1","sent_at":"2020-05-01T12:00:00Z"}`, string(encoded.Body))
+}
+
+func TestEncode_AcceptedImmediately(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	event := &entity.Event{ID: "ev2", Type: entity.EventTypeAccepted, EmailID: "mail2", Timestamp: time.Now()}
+
+	encoded, err := Encode(event, ModeBinary, HttpHeaderPrefix)
+	require.Nil(t, err)
+	require.Equal(t, "com.example.mailer.email.accepted.v1", encoded.Headers["ce-type"])
+	require.JSONEq(t, `{"email_id":"mail2"}`, string(encoded.Body))
+}
+
+// keeps the published json schemas in sync with the data we actually send
+func TestEncode_MatchesSchemas(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	for _, eventType := range entity.AllEventTypes {
+		event := tstEvent()
+		event.Type = eventType
+		event.SendAt = event.SentAt
+		event.Detail = "some detail"
+
+		schemaJson, err := ioutil.ReadFile("../../../docs/schemas/email-" + string(eventType) + "-v1.json")
+		require.Nil(t, err)
+		schema := struct {
+			Properties map[string]interface{} `json:"properties"`
+			Required   []string               `json:"required"`
+		}{}
+		require.Nil(t, json.Unmarshal(schemaJson, &schema))
+
+		encoded, err := Encode(event, ModeBinary, HttpHeaderPrefix)
+		require.Nil(t, err)
+		data := map[string]interface{}{}
+		require.Nil(t, json.Unmarshal(encoded.Body, &data))
+
+		for _, field := range schema.Required {
+			require.Contains(t, data, field, "event type %s", eventType)
+		}
+		for field := range data {
+			require.Contains(t, schema.Properties, field, "event type %s", eventType)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package eventsrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// Listener receives every event published by the services. Publish must not block for long,
+// listeners are expected to hand off slow work such as network calls.
+type Listener interface {
+	Publish(ctx context.Context, event *entity.Event)
+}
+
+var (
+	mu        sync.RWMutex
+	listeners []Listener
+)
+
+func Register(listener Listener) {
+	mu.Lock()
+	defer mu.Unlock()
+	listeners = append(listeners, listener)
+}
+
+// use this in tests to start from a clean slate
+func ResetForTesting() {
+	mu.Lock()
+	defer mu.Unlock()
+	listeners = nil
+}
+
+// Publish creates an event for the email and passes it to all registered listeners.
+//
+// Outside of requests, e.g. for scheduled emails, the event carries the id of the request that submitted the email.
+func Publish(ctx context.Context, eventType entity.EventType, email *entity.Email) {
+	requestId := tracing.RequestId(ctx)
+	if requestId == "" {
+		requestId = email.RequestID
+	}
+	event := &entity.Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		EmailID:   email.ID,
+		Status:    email.Status,
+		Detail:    email.StatusDetail,
+		SendAt:    email.SendAt,
+		SentAt:    email.SentAt,
+		Timestamp: time.Now(),
+		RequestID: requestId,
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, listener := range listeners {
+		listener.Publish(ctx, event)
+	}
+}
@@ -0,0 +1,7 @@
+package retentionsrv
+
+import "errors"
+
+var (
+	ErrInvalidAddress = errors.New("not a valid email address")
+)
@@ -0,0 +1,28 @@
+package retentionsrv
+
+import "context"
+
+type RetentionService interface {
+	// PurgeExpired removes the subject and body of emails past the content retention period, and deletes
+	// emails past the metadata retention period. Scheduled emails are left alone. Called periodically by the Purger.
+	PurgeExpired(ctx context.Context) (*PurgeResult, error)
+
+	// EraseSubject deletes every stored record for a recipient address, including scheduled emails.
+	EraseSubject(ctx context.Context, address string) (*ErasureReport, error)
+}
+
+type PurgeResult struct {
+	ContentPurged int
+	Deleted       int
+}
+
+// ErasureReport lists what EraseSubject removed, and what it had to keep.
+type ErasureReport struct {
+	// ids of the deleted emails
+	EmailIDs           []string
+	SuppressionDeleted bool
+	// the audit log is append-only, so its entries for the address are kept
+	AuditEntriesRetained int
+	// whether the retained audit entries only contain a hash of the address
+	AuditRecipientsHashed bool
+}
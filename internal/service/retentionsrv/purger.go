@@ -0,0 +1,61 @@
+package retentionsrv
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// Purger periodically enforces the retention periods.
+type Purger struct {
+	service  RetentionService
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func StartPurger(service RetentionService, interval time.Duration) *Purger {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Purger{
+		service:  service,
+		interval: interval,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	log.Info().Msgf("starting retention purger with interval %v", interval)
+	go p.run(ctx)
+	return p
+}
+
+// Stop signals the purger to stop and waits for the current run to finish.
+func (p *Purger) Stop() {
+	p.cancel()
+	<-p.done
+	log.Info().Msg("retention purger stopped")
+}
+
+func (p *Purger) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	// also purge right away, the service may have been down for a while
+	p.purge(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.purge(ctx)
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	sublogger := log.Logger.With().Str("component", "purger").Logger()
+	_, err := p.service.PurgeExpired(sublogger.WithContext(ctx))
+	if err != nil && ctx.Err() == nil {
+		log.Error().Err(err).Msgf("purging expired emails failed: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package retentionsrv
+
+import (
+	"context"
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/armon/go-metrics"
+	"github.com/rs/zerolog/log"
+	"net/mail"
+	"time"
+)
+
+type RetentionServiceImpl struct {
+	repository dbrepo.Repository
+	// nil if there is no audit log
+	auditLog *audit.Log
+}
+
+func Create() RetentionService {
+	service := &RetentionServiceImpl{
+		repository: database.GetRepository(),
+		auditLog:   audit.Get(),
+	}
+	return service
+}
+
+func (s *RetentionServiceImpl) PurgeExpired(ctx context.Context) (*PurgeResult, error) {
+	now := time.Now()
+	contentCutoff := now.Add(-configuration.RetentionContent())
+	metadataCutoff := now.Add(-configuration.RetentionMetadata())
+
+	candidates, err := s.repository.FindEmailsCreatedBefore(ctx, contentCutoff)
+	if err != nil {
+		return nil, err
+	}
+	result := &PurgeResult{}
+	for _, email := range candidates {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if email.Status == entity.EmailStatusScheduled {
+			continue
+		}
+		if email.CreatedAt.Before(metadataCutoff) {
+			err = s.repository.DeleteEmail(ctx, email.ID)
+			if err == nil {
+				result.Deleted++
+			}
+		} else if email.ContentPurgedAt.IsZero() {
+			email.Subject = ""
+			email.Body = ""
+			email.ContentPurgedAt = now
+			err = s.repository.UpdateEmail(ctx, email)
+			if err == nil {
+				result.ContentPurged++
+			}
+		}
+		// the email may have been erased in the meantime
+		if err != nil && !errors.Is(err, dbrepo.ErrNotFound) {
+			return result, err
+		}
+	}
+
+	metrics.IncrCounterWithLabels([]string{"retention", "purged"}, float32(result.ContentPurged), []metrics.Label{{Name: "kind", Value: "content"}})
+	metrics.IncrCounterWithLabels([]string{"retention", "purged"}, float32(result.Deleted), []metrics.Label{{Name: "kind", Value: "email"}})
+	if result.ContentPurged > 0 || result.Deleted > 0 {
+		log.Ctx(ctx).Info().Msgf("purged the content of %d emails and deleted %d emails", result.ContentPurged, result.Deleted)
+	}
+	return result, nil
+}
+
+func (s *RetentionServiceImpl) EraseSubject(ctx context.Context, address string) (*ErasureReport, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return nil, ErrInvalidAddress
+	}
+
+	report := &ErasureReport{EmailIDs: []string{}}
+	emails, err := s.repository.FindEmailsByAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	for _, email := range emails {
+		err = s.repository.DeleteEmail(ctx, email.ID)
+		if err != nil && !errors.Is(err, dbrepo.ErrNotFound) {
+			return nil, err
+		}
+		report.EmailIDs = append(report.EmailIDs, email.ID)
+	}
+
+	err = s.repository.DeleteSuppression(ctx, address)
+	if err == nil {
+		report.SuppressionDeleted = true
+	} else if !errors.Is(err, dbrepo.ErrNotFound) {
+		return nil, err
+	}
+
+	if s.auditLog != nil {
+		entries, err := s.auditLog.Query(audit.Filter{Recipient: address})
+		if err != nil {
+			return nil, err
+		}
+		report.AuditEntriesRetained = len(entries)
+		report.AuditRecipientsHashed = configuration.AuditRecipients() == audit.RecipientsHashed
+	}
+
+	log.Ctx(ctx).Info().Msgf("erased subject: deleted %d emails, suppression deleted: %v, %d audit entries retained",
+		len(report.EmailIDs), report.SuppressionDeleted, report.AuditEntriesRetained)
+	return report, nil
+}
@@ -0,0 +1,96 @@
+package retentionsrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/inmemorydb"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func tstCreateService(t *testing.T) *RetentionServiceImpl {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	repository := inmemorydb.Create()
+	require.Nil(t, repository.Open())
+	auditLog, err := audit.Create(audit.CreateInMemorySink(), []byte("0123456789abcdef0123456789abcdef"), true)
+	require.Nil(t, err)
+	return &RetentionServiceImpl{repository: repository, auditLog: auditLog}
+}
+
+func tstAddEmail(t *testing.T, s *RetentionServiceImpl, id string, to string, status entity.EmailStatus, age time.Duration) {
+	require.Nil(t, s.repository.AddEmail(context.Background(), &entity.Email{
+		ID:        id,
+		ToAddress: to,
+		Subject:   "Hello",
+		Body:      "World",
+		Status:    status,
+		CreatedAt: time.Now().Add(-age),
+	}))
+}
+
+func TestPurgeExpired_ShouldEnforceRetentionPeriods(t *testing.T) {
+	s := tstCreateService(t)
+	ctx := context.Background()
+	tstAddEmail(t, s, "fresh", "someone@example.com", entity.EmailStatusSent, time.Hour)
+	tstAddEmail(t, s, "old", "someone@example.com", entity.EmailStatusSent, 10*24*time.Hour)
+	tstAddEmail(t, s, "ancient", "someone@example.com", entity.EmailStatusFailed, 100*24*time.Hour)
+	tstAddEmail(t, s, "pending", "someone@example.com", entity.EmailStatusScheduled, 100*24*time.Hour)
+
+	result, err := s.PurgeExpired(ctx)
+	require.Nil(t, err)
+	require.Equal(t, &PurgeResult{ContentPurged: 1, Deleted: 1}, result)
+
+	fresh, err := s.repository.GetEmail(ctx, "fresh")
+	require.Nil(t, err)
+	require.Equal(t, "World", fresh.Body)
+	old, err := s.repository.GetEmail(ctx, "old")
+	require.Nil(t, err)
+	require.Empty(t, old.Subject)
+	require.Empty(t, old.Body)
+	require.False(t, old.ContentPurgedAt.IsZero())
+	_, err = s.repository.GetEmail(ctx, "ancient")
+	require.ErrorIs(t, err, dbrepo.ErrNotFound)
+	pending, err := s.repository.GetEmail(ctx, "pending")
+	require.Nil(t, err)
+	require.Equal(t, "World", pending.Body)
+
+	result, err = s.PurgeExpired(ctx)
+	require.Nil(t, err)
+	require.Equal(t, &PurgeResult{}, result)
+}
+
+func TestEraseSubject_ShouldDeleteAllRecordsForAddress(t *testing.T) {
+	s := tstCreateService(t)
+	ctx := context.Background()
+	tstAddEmail(t, s, "sent", "Someone@Example.com", entity.EmailStatusSent, time.Hour)
+	tstAddEmail(t, s, "pending", "Jane <someone@example.com>", entity.EmailStatusScheduled, time.Hour)
+	tstAddEmail(t, s, "other", "other@example.com", entity.EmailStatusSent, time.Hour)
+	require.Nil(t, s.repository.AddSuppression(ctx, &entity.Suppression{Address: "someone@example.com", Reason: "hard bounce"}))
+	_, err := s.auditLog.Append(ctx, audit.Entry{EmailId: "sent", Recipients: []string{"someone@example.com"}, Outcome: audit.OutcomeSent})
+	require.Nil(t, err)
+
+	report, err := s.EraseSubject(ctx, "someone@example.com")
+	require.Nil(t, err)
+	require.Equal(t, []string{"sent", "pending"}, report.EmailIDs)
+	require.True(t, report.SuppressionDeleted)
+	require.Equal(t, 1, report.AuditEntriesRetained)
+	require.True(t, report.AuditRecipientsHashed)
+
+	remaining, err := s.repository.FindEmailsCreatedBefore(ctx, time.Now())
+	require.Nil(t, err)
+	require.Len(t, remaining, 1)
+	require.Equal(t, "other", remaining[0].ID)
+	_, err = s.repository.GetSuppression(ctx, "someone@example.com")
+	require.ErrorIs(t, err, dbrepo.ErrNotFound)
+}
+
+func TestEraseSubject_ShouldRejectInvalidAddress(t *testing.T) {
+	s := tstCreateService(t)
+
+	_, err := s.EraseSubject(context.Background(), "Jane <someone@example.com>")
+	require.Equal(t, ErrInvalidAddress, err)
+}
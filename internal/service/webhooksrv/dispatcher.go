@@ -0,0 +1,252 @@
+package webhooksrv
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/StephanHCB/go-mailer-service/internal/service/eventsrv"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const SignatureHeader = "X-Mailer-Signature"
+const TimestampHeader = "X-Mailer-Timestamp"
+
+const queueSize = 1000
+const workerCount = 4
+
+type job struct {
+	subscription *entity.WebhookSubscription
+	event        *entity.Event
+	// rendered on the first attempt
+	encoded *eventsrv.Encoded
+	attempt int
+	// before the next retry
+	wait time.Duration
+}
+
+// Dispatcher delivers events as CloudEvents to all interested webhook subscriptions, retrying with exponential backoff.
+//
+// Retries wait on a timer and are then queued again, so a failing subscription does not hold up the workers.
+//
+// Register it with eventsrv to receive events.
+type Dispatcher struct {
+	repository  dbrepo.Repository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	mode        eventsrv.Mode
+
+	queue chan *job
+	// guards closing draining against adding to pending, so no job is added once Shutdown waits for them
+	drainMu  sync.Mutex
+	draining chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	// jobs that are queued, being delivered or waiting for a retry
+	pending sync.WaitGroup
+}
+
+func StartDispatcher(repository dbrepo.Repository, maxAttempts int, backoff time.Duration, timeout time.Duration, mode eventsrv.Mode) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		repository:  repository,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		mode:        mode,
+		queue:       make(chan *job, queueSize),
+		draining:    make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	log.Info().Msgf("starting webhook dispatcher with %d workers", workerCount)
+	for i := 0; i < workerCount; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Stop signals all workers to stop and waits for them. Pending retries are abandoned.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+	log.Info().Msg("webhook dispatcher stopped")
+}
+
+// Shutdown stops accepting events and waits until all queued deliveries, including their retries, are done.
+//
+// If ctx is done first, the remaining deliveries are abandoned as with Stop, and the context error is returned.
+// Must only be called once.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.drainMu.Lock()
+	close(d.draining)
+	d.drainMu.Unlock()
+	finished := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		d.cancel()
+		d.wg.Wait()
+		log.Info().Msg("webhook dispatcher drained and stopped")
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		d.wg.Wait()
+		log.Warn().Msgf("webhook dispatcher stopped, abandoned %d queued deliveries", len(d.queue))
+		return ctx.Err()
+	}
+}
+
+// Publish implements eventsrv.Listener.
+func (d *Dispatcher) Publish(ctx context.Context, event *entity.Event) {
+	subscriptions, err := d.repository.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to list webhook subscriptions, dropping event %s: %v", event.ID, err)
+		return
+	}
+
+	d.drainMu.Lock()
+	defer d.drainMu.Unlock()
+	select {
+	case <-d.draining:
+		log.Ctx(ctx).Warn().Msgf("webhook dispatcher is shutting down, dropping event %s", event.ID)
+		return
+	default:
+	}
+	for _, subscription := range subscriptions {
+		if !subscription.WantsEvent(event.Type) {
+			continue
+		}
+		d.pending.Add(1)
+		select {
+		case d.queue <- &job{subscription: subscription, event: event, attempt: 1, wait: d.backoff}:
+		default:
+			d.pending.Done()
+			log.Ctx(ctx).Error().Msgf("webhook queue full, dropping event %s for subscription %s", event.ID, subscription.ID)
+		}
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case j := <-d.queue:
+			if d.deliver(j) {
+				d.retryLater(j)
+			} else {
+				d.pending.Done()
+			}
+		}
+	}
+}
+
+// deliver makes one attempt to deliver the job and tells whether it should be retried.
+func (d *Dispatcher) deliver(j *job) bool {
+	sublogger := log.Logger.With().Str("component", "webhooks").Logger()
+	ctx := sublogger.WithContext(d.ctx)
+
+	if j.encoded == nil {
+		encoded, err := eventsrv.Encode(j.event, d.mode, eventsrv.HttpHeaderPrefix)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to render event %s: %v", j.event.ID, err)
+			return false
+		}
+		j.encoded = encoded
+	}
+
+	delivery := d.attempt(ctx, j)
+	if err := d.repository.AddWebhookDelivery(ctx, delivery); err != nil {
+		// most likely the subscription was deleted in the meantime
+		log.Ctx(ctx).Warn().Err(err).Msgf("failed to log webhook delivery, giving up on event %s for subscription %s: %v", j.event.ID, j.subscription.ID, err)
+		return false
+	}
+	if delivery.Success {
+		return false
+	}
+	if j.attempt >= d.maxAttempts {
+		log.Ctx(ctx).Warn().Msgf("giving up on event %s for subscription %s after %d attempts", j.event.ID, j.subscription.ID, d.maxAttempts)
+		return false
+	}
+	return true
+}
+
+// retryLater queues the job again once its backoff has passed, unless the dispatcher has been stopped by then.
+func (d *Dispatcher) retryLater(j *job) {
+	wait := j.wait
+	j.attempt++
+	j.wait *= 2
+	time.AfterFunc(wait, func() {
+		select {
+		case d.queue <- j:
+		case <-d.ctx.Done():
+			d.pending.Done()
+		}
+	})
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, j *job) *entity.WebhookDelivery {
+	delivery := &entity.WebhookDelivery{
+		SubscriptionID: j.subscription.ID,
+		EventID:        j.event.ID,
+		EventType:      j.event.Type,
+		Attempt:        j.attempt,
+		Timestamp:      time.Now(),
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, j.subscription.URL, bytes.NewReader(j.encoded.Body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	timestamp := strconv.FormatInt(delivery.Timestamp.Unix(), 10)
+	for name, value := range j.encoded.Headers {
+		request.Header.Set(name, value)
+	}
+	request.Header.Set(TimestampHeader, timestamp)
+	request.Header.Set(SignatureHeader, Sign(j.subscription.Secret, timestamp, j.encoded.Body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		delivery.Error = err.Error()
+		log.Ctx(ctx).Info().Msgf("webhook attempt %d for subscription %s failed: %v", j.attempt, j.subscription.ID, err)
+		return delivery
+	}
+	_ = response.Body.Close()
+
+	delivery.StatusCode = response.StatusCode
+	delivery.Success = response.StatusCode >= 200 && response.StatusCode < 300
+	if !delivery.Success {
+		delivery.Error = fmt.Sprintf("unexpected response status %d", response.StatusCode)
+		log.Ctx(ctx).Info().Msgf("webhook attempt %d for subscription %s failed with status %d", j.attempt, j.subscription.ID, response.StatusCode)
+	}
+	return delivery
+}
+
+// Sign computes the signature header value receivers should compare against, in constant time.
+//
+// The timestamp is included so receivers can reject replays of old callbacks.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,232 @@
+package webhooksrv
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/api/v1/events"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/inmemorydb"
+	"github.com/StephanHCB/go-mailer-service/internal/service/eventsrv"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const tstSecret = "0123456789abcdef-secret"
+
+type tstReceiver struct {
+	mu             sync.Mutex
+	failuresToSend int
+	received       []events.CloudEventDto
+	contentTypes   []string
+	signaturesOk   bool
+}
+
+func (r *tstReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := ioutil.ReadAll(req.Body)
+	r.signaturesOk = Sign(tstSecret, req.Header.Get(TimestampHeader), body) == req.Header.Get(SignatureHeader)
+	if r.failuresToSend > 0 {
+		r.failuresToSend--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	dto := events.CloudEventDto{}
+	_ = json.Unmarshal(body, &dto)
+	r.received = append(r.received, dto)
+	r.contentTypes = append(r.contentTypes, req.Header.Get("Content-Type"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *tstReceiver) receivedCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.received)
+}
+
+func tstSetupDispatcher(t *testing.T, receiverUrl string, eventTypes []entity.EventType) (*Dispatcher, dbrepo.Repository) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	repository := inmemorydb.Create()
+	require.Nil(t, repository.Open())
+	require.Nil(t, repository.AddWebhookSubscription(context.Background(), &entity.WebhookSubscription{
+		ID:         "sub1",
+		URL:        receiverUrl,
+		Secret:     tstSecret,
+		EventTypes: eventTypes,
+	}))
+	return StartDispatcher(repository, 3, 10*time.Millisecond, time.Second, eventsrv.ModeStructured), repository
+}
+
+func tstWaitFor(condition func() bool) {
+	for i := 0; i < 100 && !condition(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDispatcher_ShouldRetryAndSign(t *testing.T) {
+	receiver := &tstReceiver{failuresToSend: 1}
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+	cut, repository := tstSetupDispatcher(t, ts.URL, nil)
+	defer cut.Stop()
+
+	cut.Publish(context.Background(), &entity.Event{ID: "ev1", Type: entity.EventTypeSent, EmailID: "mail1", Status: entity.EmailStatusSent, Timestamp: time.Now()})
+	tstWaitFor(func() bool { return receiver.receivedCount() == 1 })
+
+	require.Equal(t, 1, receiver.receivedCount())
+	require.True(t, receiver.signaturesOk)
+	require.Equal(t, "ev1", receiver.received[0].Id)
+	require.Equal(t, "com.example.mailer.email.sent.v1", receiver.received[0].Type)
+	require.Equal(t, "mail1", receiver.received[0].Subject)
+	require.Equal(t, eventsrv.StructuredContentType, receiver.contentTypes[0])
+
+	var deliveries []*entity.WebhookDelivery
+	tstWaitFor(func() bool {
+		deliveries, _ = repository.ListWebhookDeliveries(context.Background(), "sub1")
+		return len(deliveries) == 2
+	})
+	require.Len(t, deliveries, 2)
+	require.False(t, deliveries[0].Success)
+	require.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+	require.True(t, deliveries[1].Success)
+	require.Equal(t, 2, deliveries[1].Attempt)
+}
+
+func TestDispatcher_ShouldGiveUpAfterMaxAttempts(t *testing.T) {
+	receiver := &tstReceiver{failuresToSend: 10}
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+	cut, repository := tstSetupDispatcher(t, ts.URL, nil)
+	defer cut.Stop()
+
+	cut.Publish(context.Background(), &entity.Event{ID: "ev1", Type: entity.EventTypeFailed, Timestamp: time.Now()})
+
+	var deliveries []*entity.WebhookDelivery
+	tstWaitFor(func() bool {
+		deliveries, _ = repository.ListWebhookDeliveries(context.Background(), "sub1")
+		return len(deliveries) == 3
+	})
+	time.Sleep(50 * time.Millisecond)
+	deliveries, _ = repository.ListWebhookDeliveries(context.Background(), "sub1")
+	require.Len(t, deliveries, 3)
+	require.Equal(t, 0, receiver.receivedCount())
+}
+
+func TestDispatcher_ShouldFilterEventTypes(t *testing.T) {
+	receiver := &tstReceiver{}
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+	cut, _ := tstSetupDispatcher(t, ts.URL, []entity.EventType{entity.EventTypeBounced})
+	defer cut.Stop()
+
+	cut.Publish(context.Background(), &entity.Event{ID: "ev1", Type: entity.EventTypeSent, Timestamp: time.Now()})
+	cut.Publish(context.Background(), &entity.Event{ID: "ev2", Type: entity.EventTypeBounced, Timestamp: time.Now()})
+	tstWaitFor(func() bool { return receiver.receivedCount() == 1 })
+	time.Sleep(50 * time.Millisecond)
+
+	require.Equal(t, 1, receiver.receivedCount())
+	require.Equal(t, "ev2", receiver.received[0].Id)
+}
+
+func TestDispatcher_ShutdownShouldDrainQueue(t *testing.T) {
+	receiver := &tstReceiver{failuresToSend: 1}
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+	cut, _ := tstSetupDispatcher(t, ts.URL, nil)
+
+	cut.Publish(context.Background(), &entity.Event{ID: "ev1", Type: entity.EventTypeSent, Timestamp: time.Now()})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.Nil(t, cut.Shutdown(ctx))
+	require.Equal(t, 1, receiver.receivedCount())
+
+	cut.Publish(context.Background(), &entity.Event{ID: "ev2", Type: entity.EventTypeSent, Timestamp: time.Now()})
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 1, receiver.receivedCount())
+}
+
+func TestDispatcher_ShutdownShouldGiveUpAfterGracePeriod(t *testing.T) {
+	receiver := &tstReceiver{failuresToSend: 10}
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+	cut, repository := tstSetupDispatcher(t, ts.URL, nil)
+
+	cut.Publish(context.Background(), &entity.Event{ID: "ev1", Type: entity.EventTypeSent, Timestamp: time.Now()})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	require.Equal(t, context.DeadlineExceeded, cut.Shutdown(ctx))
+	deliveries, _ := repository.ListWebhookDeliveries(context.Background(), "sub1")
+	require.True(t, len(deliveries) < 3)
+}
+
+func TestDispatcher_FailingSubscriptionShouldNotDelayOthers(t *testing.T) {
+	failing := &tstReceiver{failuresToSend: 100}
+	failingTs := httptest.NewServer(failing)
+	defer failingTs.Close()
+	healthy := &tstReceiver{}
+	healthyTs := httptest.NewServer(healthy)
+	defer healthyTs.Close()
+
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	repository := inmemorydb.Create()
+	require.Nil(t, repository.Open())
+	require.Nil(t, repository.AddWebhookSubscription(context.Background(), &entity.WebhookSubscription{
+		ID: "failing", URL: failingTs.URL, Secret: tstSecret,
+	}))
+	require.Nil(t, repository.AddWebhookSubscription(context.Background(), &entity.WebhookSubscription{
+		ID: "healthy", URL: healthyTs.URL, Secret: tstSecret, EventTypes: []entity.EventType{entity.EventTypeBounced},
+	}))
+	cut := StartDispatcher(repository, 3, time.Minute, time.Second, eventsrv.ModeStructured)
+	defer cut.Stop()
+
+	// enough failures to keep every worker busy if retries waited on them
+	for i := 0; i < 2*workerCount; i++ {
+		cut.Publish(context.Background(), &entity.Event{ID: "failed" + strconv.Itoa(i), Type: entity.EventTypeFailed, Timestamp: time.Now()})
+	}
+	tstWaitFor(func() bool {
+		deliveries, _ := repository.ListWebhookDeliveries(context.Background(), "failing")
+		return len(deliveries) == 2*workerCount
+	})
+	cut.Publish(context.Background(), &entity.Event{ID: "bounced", Type: entity.EventTypeBounced, Timestamp: time.Now()})
+	tstWaitFor(func() bool { return healthy.receivedCount() == 1 })
+
+	require.Equal(t, 1, healthy.receivedCount())
+	require.Equal(t, "bounced", healthy.received[0].Id)
+}
+
+func TestDispatcher_PublishDuringShutdownShouldNotLoseEvents(t *testing.T) {
+	receiver := &tstReceiver{}
+	ts := httptest.NewServer(receiver)
+	defer ts.Close()
+	cut, _ := tstSetupDispatcher(t, ts.URL, nil)
+
+	var publishers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		publishers.Add(1)
+		go func(i int) {
+			defer publishers.Done()
+			for n := 0; n < 200; n++ {
+				cut.Publish(context.Background(), &entity.Event{ID: "ev" + strconv.Itoa(i) + "-" + strconv.Itoa(n), Type: entity.EventTypeSent, Timestamp: time.Now()})
+			}
+		}(i)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.Nil(t, cut.Shutdown(ctx))
+	publishers.Wait()
+
+	// a job queued after the drain finished would never be delivered
+	require.Empty(t, cut.queue)
+}
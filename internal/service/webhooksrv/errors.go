@@ -0,0 +1,14 @@
+package webhooksrv
+
+import "errors"
+
+var ErrNotFound = errors.New("webhook subscription not found")
+
+// ValidationError is returned when a subscription is invalid.
+type ValidationError struct {
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
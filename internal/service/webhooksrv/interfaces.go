@@ -0,0 +1,19 @@
+package webhooksrv
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+)
+
+type WebhookService interface {
+	NewInstance(ctx context.Context) *entity.WebhookSubscription
+
+	CreateSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error
+	// UpdateSubscription keeps the existing secret if the new one is empty.
+	UpdateSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id string) (*entity.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error)
+
+	ListDeliveries(ctx context.Context, id string) ([]*entity.WebhookDelivery, error)
+}
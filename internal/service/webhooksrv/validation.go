@@ -0,0 +1,34 @@
+package webhooksrv
+
+import (
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"net/url"
+)
+
+const minSecretLength = 16
+
+func validate(subscription *entity.WebhookSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return &ValidationError{Reason: "url must be an absolute http or https url"}
+	}
+	if len(subscription.Secret) < minSecretLength {
+		return &ValidationError{Reason: fmt.Sprintf("secret must be at least %d characters long", minSecretLength)}
+	}
+	for _, eventType := range subscription.EventTypes {
+		if !isKnownEventType(eventType) {
+			return &ValidationError{Reason: fmt.Sprintf("unknown event type '%s'", eventType)}
+		}
+	}
+	return nil
+}
+
+func isKnownEventType(eventType entity.EventType) bool {
+	for _, known := range entity.AllEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package webhooksrv
+
+import (
+	"context"
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database/dbrepo"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+type WebhookServiceImpl struct {
+	repository dbrepo.Repository
+}
+
+func Create() WebhookService {
+	service := &WebhookServiceImpl{
+		repository: database.GetRepository(),
+	}
+	return service
+}
+
+func (s *WebhookServiceImpl) NewInstance(ctx context.Context) *entity.WebhookSubscription {
+	return &entity.WebhookSubscription{}
+}
+
+func (s *WebhookServiceImpl) CreateSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	err := validate(subscription)
+	if err != nil {
+		log.Ctx(ctx).Warn().Msgf("webhook subscription rejected: %v", err.Error())
+		return err
+	}
+
+	subscription.ID = uuid.New().String()
+	subscription.CreatedAt = time.Now()
+	err = s.repository.AddWebhookSubscription(ctx, subscription)
+	if err != nil {
+		return err
+	}
+	log.Ctx(ctx).Info().Msgf("webhook subscription %s created", subscription.ID)
+	return nil
+}
+
+func (s *WebhookServiceImpl) UpdateSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	existing, err := s.GetSubscription(ctx, subscription.ID)
+	if err != nil {
+		return err
+	}
+	if subscription.Secret == "" {
+		subscription.Secret = existing.Secret
+	}
+	subscription.CreatedAt = existing.CreatedAt
+
+	err = validate(subscription)
+	if err != nil {
+		log.Ctx(ctx).Warn().Msgf("webhook subscription update rejected: %v", err.Error())
+		return err
+	}
+	return mapNotFound(s.repository.UpdateWebhookSubscription(ctx, subscription))
+}
+
+func (s *WebhookServiceImpl) GetSubscription(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	subscription, err := s.repository.GetWebhookSubscription(ctx, id)
+	return subscription, mapNotFound(err)
+}
+
+func (s *WebhookServiceImpl) DeleteSubscription(ctx context.Context, id string) error {
+	err := mapNotFound(s.repository.DeleteWebhookSubscription(ctx, id))
+	if err == nil {
+		log.Ctx(ctx).Info().Msgf("webhook subscription %s deleted", id)
+	}
+	return err
+}
+
+func (s *WebhookServiceImpl) ListSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	return s.repository.ListWebhookSubscriptions(ctx)
+}
+
+func (s *WebhookServiceImpl) ListDeliveries(ctx context.Context, id string) ([]*entity.WebhookDelivery, error) {
+	deliveries, err := s.repository.ListWebhookDeliveries(ctx, id)
+	return deliveries, mapNotFound(err)
+}
+
+func mapNotFound(err error) error {
+	if errors.Is(err, dbrepo.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
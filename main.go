@@ -1,18 +1,47 @@
 package main
 
 import (
+	"context"
 	_ "github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
 	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database"
 	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/mailsender"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/messaging"
 	"github.com/StephanHCB/go-mailer-service/internal/repository/metricspush"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
 	"github.com/StephanHCB/go-mailer-service/web"
+	"github.com/rs/zerolog/log"
+	"os"
 )
 
 func main() {
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run keeps the deferred flushes and closes in a function of their own, os.Exit would skip them
+func run() error {
 	logging.Setup()
 	configuration.Setup()
 	logging.PostConfigSetup()
 	metricspush.Setup()
+	defer metricspush.Shutdown()
+	tracing.Setup()
+	defer tracing.Shutdown(context.Background())
+	database.Open()
+	defer database.Close()
+	audit.Setup()
+	defer audit.Close()
+	mailsender.Setup()
+	messaging.Setup()
+	defer messaging.Close()
 
-	web.Serve()
+	if err := web.Serve(); err != nil {
+		log.Error().Err(err).Msg(err.Error())
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,6 @@
+#! /bin/bash
+
+# needs protoc, protoc-gen-go v1.27.1 and protoc-gen-go-grpc v1.1.0 on the path
+protoc --go_out=. --go_opt=paths=source_relative \
+    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+    api/grpc/emailpb/email.proto
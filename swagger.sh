@@ -1,4 +1,5 @@
 #! /bin/bash
 
-swagger generate spec -o docs/swagger.json --scan-models
+# writes to docs/swagger.json unless another file is given, docs/spec_test.go uses this to check for drift
+swagger generate spec -o "${1:-docs/swagger.json}" --scan-models
 
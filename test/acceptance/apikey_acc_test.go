@@ -0,0 +1,81 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/audit"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// keys configured in test/resources/validconfig/secrets.yaml
+
+func tstPerformWithApiKey(method string, relativeUrlWithLeadingSlash string, requestBody string, apiKey string) (tstWebResponse, error) {
+	return tstPerformWithHeaders(method, relativeUrlWithLeadingSlash, strings.NewReader(requestBody), "application/json",
+		tstUnauthenticated(), map[string]string{"X-API-Key": apiKey})
+}
+
+func TestApiKey_AdminKeyShouldBeAllowed(t *testing.T) {
+	docs.Given("Given a running application with an api key that has role admin")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a legacy client sends an email using that api key")
+	response, err := tstPerformWithApiKey(http.MethodPost, "/api/rest/v1/sendmail", tstRenderJson(tstValidEmailDto()), "test-admin-key")
+	require.Nil(t, err)
+
+	docs.Then("Then the email is accepted and audited with the key owner as the caller")
+	require.Equal(t, http.StatusOK, response.status)
+	response, err = tstPerformWithApiKey(http.MethodGet, "/api/rest/v1/audit?subject=legacy-crm", "", "test-admin-key")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	entries := audit.AuditEntryListDto{}
+	require.Nil(t, tstParseJson(response.body, &entries))
+	require.NotEmpty(t, entries.Entries)
+	require.Equal(t, []string{"admin"}, entries.Entries[0].Roles)
+}
+
+func TestApiKey_KeyWithoutRoleShouldBeForbidden(t *testing.T) {
+	docs.Given("Given a running application with an api key that has no roles")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a client calls an admin endpoint using that api key")
+	response, err := tstPerformWithApiKey(http.MethodGet, "/api/rest/v1/audit", "", "test-reader-key")
+	require.Nil(t, err)
+
+	docs.Then("Then the request is forbidden")
+	require.Equal(t, http.StatusForbidden, response.status)
+}
+
+func TestApiKey_UnknownOrExpiredKeyShouldBeUnauthorized(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a client calls with an unknown api key and with an expired one")
+	unknown, err := tstPerformWithApiKey(http.MethodGet, "/api/rest/v1/audit", "", "guessed-key")
+	require.Nil(t, err)
+	expired, err := tstPerformWithApiKey(http.MethodGet, "/api/rest/v1/audit", "", "test-expired-key")
+	require.Nil(t, err)
+
+	docs.Then("Then both requests are rejected as unauthorized")
+	require.Equal(t, http.StatusUnauthorized, unknown.status)
+	require.Equal(t, http.StatusUnauthorized, expired.status)
+}
+
+func TestApiKey_InvalidTokenShouldNotFallBackToKey(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a client sends an email with an invalid token and a valid api key")
+	response, err := tstPerformWithHeaders(http.MethodPost, "/api/rest/v1/sendmail", strings.NewReader(tstRenderJson(tstValidEmailDto())),
+		"application/json", tstValidAdminToken()+"x", map[string]string{"X-API-Key": "test-admin-key"})
+	require.Nil(t, err)
+
+	docs.Then("Then the request is rejected as unauthorized and the email is not sent")
+	require.Equal(t, http.StatusUnauthorized, response.status)
+	require.Empty(t, sentEmails.Sent())
+}
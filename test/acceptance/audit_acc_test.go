@@ -0,0 +1,102 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/audit"
+	"github.com/StephanHCB/go-mailer-service/api/v1/email"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAudit_ShouldRecordSendAttempts(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin sends a valid email and an invalid one")
+	response, err := tstPerformWithHeaders(http.MethodPost, "/api/rest/v1/sendmail", strings.NewReader(tstRenderJson(tstValidEmailDto())),
+		"application/json", tstValidAdminToken(), map[string]string{"X-Request-Id": "audit-req-1"})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	result := email.EmailResultDto{}
+	require.Nil(t, tstParseJson(response.body, &result))
+	invalid := tstValidEmailDto()
+	invalid.SendAt = "2999-01-01T00:00:00Z"
+	response, err = tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(invalid), tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+
+	docs.Then("Then the audit log records who sent what to whom, with hashed recipients")
+	response, err = tstPerformGet("/api/rest/v1/audit?email_id="+result.Id, tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	entries := audit.AuditEntryListDto{}
+	require.Nil(t, tstParseJson(response.body, &entries))
+	require.Len(t, entries.Entries, 2)
+	require.Equal(t, "accepted", entries.Entries[0].Outcome)
+	require.Equal(t, "sent", entries.Entries[1].Outcome)
+	require.Equal(t, "1234567890", entries.Entries[0].Subject)
+	require.Equal(t, []string{"admin"}, entries.Entries[0].Roles)
+	require.Equal(t, "audit-req-1", entries.Entries[0].RequestId)
+	require.NotContains(t, response.body, "someone@example.com")
+	require.Regexp(t, "^hmac-sha256:[0-9a-f]{64}$", entries.Entries[0].Recipients[0])
+
+	docs.Then("And the rejection can be found by recipient")
+	response, err = tstPerformGet("/api/rest/v1/audit?outcome=rejected&recipient=someone@example.com", tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	entries = audit.AuditEntryListDto{}
+	require.Nil(t, tstParseJson(response.body, &entries))
+	require.Len(t, entries.Entries, 1)
+	require.NotEmpty(t, entries.Entries[0].Detail)
+
+	docs.Then("And the hash chain verifies")
+	response, err = tstPerformGet("/api/rest/v1/audit/verify", tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	verification := audit.AuditVerificationDto{}
+	require.Nil(t, tstParseJson(response.body, &verification))
+	require.Equal(t, audit.AuditVerificationDto{Entries: 3, Valid: true}, verification)
+}
+
+func TestAudit_InvalidLimit_ShouldBeRejected(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin queries the audit log with an invalid limit")
+	response, err := tstPerformGet("/api/rest/v1/audit?limit=0", tstValidAdminToken())
+
+	docs.Then("Then the request is rejected")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	require.Contains(t, response.body, "audit.query.error")
+}
+
+func TestAudit_Unauthenticated_ShouldBeDenied(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an anonymous caller queries the audit log")
+	response, err := tstPerformGet("/api/rest/v1/audit", tstUnauthenticated())
+
+	docs.Then("Then the request is denied")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.status)
+}
+
+func TestAudit_NoAdmin_ShouldBeForbidden(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a user without the admin role verifies the audit log")
+	response, err := tstPerformGet("/api/rest/v1/audit/verify", tstValidUserToken())
+
+	docs.Then("Then the request is forbidden")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+}
@@ -0,0 +1,85 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/bounce"
+	"github.com/StephanHCB/go-mailer-service/api/v1/email"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func tstHardBounceFor(emailId string) string {
+	return "From: MAILER-DAEMON@mx.example.com\r\n" +
+		"To: mailer@localhost\r\n" +
+		"Content-Type: multipart/report; report-type=delivery-status; boundary=\"BOUNDARY\"\r\n" +
+		"\r\n" +
+		"--BOUNDARY\r\n" +
+		"Content-Type: message/delivery-status\r\n" +
+		"\r\n" +
+		"Reporting-MTA: dns; mx.example.com\r\n" +
+		"\r\n" +
+		"Final-Recipient: rfc822; someone@example.com\r\n" +
+		"Action: failed\r\n" +
+		"Status: 5.1.1\r\n" +
+		"--BOUNDARY\r\n" +
+		"Content-Type: text/rfc822-headers\r\n" +
+		"\r\n" +
+		"Message-ID: <" + emailId + "@localhost>\r\n" +
+		"--BOUNDARY--\r\n"
+}
+
+func TestSubmitBounce_HardBounce_ShouldSuppressAddress(t *testing.T) {
+	docs.Given("Given a running application that has sent an email")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(tstValidEmailDto()), tstUnauthenticated())
+	require.Nil(t, err)
+	result := email.EmailResultDto{}
+	require.Nil(t, tstParseJson(response.body, &result))
+
+	docs.When("When an admin submits a hard bounce for that email")
+	response, err = tstPerformPostRaw("/api/rest/v1/bounces", tstHardBounceFor(result.Id), "message/rfc822", tstValidAdminToken())
+
+	docs.Then("Then the bounce is correlated to the email")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	bounceResult := bounce.BounceResultDto{}
+	require.Nil(t, tstParseJson(response.body, &bounceResult))
+	require.Equal(t, result.Id, bounceResult.EmailId)
+	require.Equal(t, "hard", bounceResult.Kind)
+
+	docs.Then("And further emails to the address are rejected")
+	response, err = tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(tstValidEmailDto()), tstUnauthenticated())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+}
+
+func TestSubmitBounce_Uncorrelated_ShouldReturnNotFound(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin submits a bounce for an unknown email")
+	response, err := tstPerformPostRaw("/api/rest/v1/bounces", tstHardBounceFor("unknown"), "message/rfc822", tstValidAdminToken())
+
+	docs.Then("Then it is rejected as not correlated")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.status)
+}
+
+func TestSubmitBounce_Security(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a bounce is submitted anonymously or without the admin role")
+	anonymous, err1 := tstPerformPostRaw("/api/rest/v1/bounces", tstHardBounceFor("x"), "message/rfc822", tstUnauthenticated())
+	user, err2 := tstPerformPostRaw("/api/rest/v1/bounces", tstHardBounceFor("x"), "message/rfc822", tstValidUserToken())
+
+	docs.Then("Then the request is denied")
+	require.Nil(t, err1)
+	require.Equal(t, http.StatusUnauthorized, anonymous.status)
+	require.Nil(t, err2)
+	require.Equal(t, http.StatusForbidden, user.status)
+}
@@ -0,0 +1,83 @@
+package acceptance
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/api/v1/commands"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/messaging"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func tstSendCommand(t *testing.T, key string, value string) {
+	message := &messaging.Message{Key: []byte(key), Value: []byte(value), Headers: map[string]string{"origin": "acceptance-test"}}
+	require.Nil(t, broker.Publish(context.Background(), configuration.KafkaCommandTopic(), message))
+}
+
+func tstWaitForCommitted(count int) {
+	for i := 0; i < 100 && len(broker.Committed()) < count; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCommands_ValidCommand_ShouldSend(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a valid send-email command arrives on the command topic")
+	tstSendCommand(t, "cmd1", tstRenderJson(tstValidEmailDto()))
+	tstWaitForCommitted(1)
+
+	docs.Then("Then the email is sent and the command committed")
+	require.Len(t, broker.Committed(), 1)
+	require.Len(t, sentEmails.Sent(), 1)
+	require.Equal(t, "Reminder", sentEmails.Sent()[0].Subject)
+
+	docs.Then("And nothing is published to the error topic")
+	require.Empty(t, broker.Published(configuration.KafkaErrorTopic()))
+}
+
+func TestCommands_InvalidCommands_ShouldPublishErrors(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a command that is not json, a command scheduled too far into the future and an empty command arrive")
+	tstSendCommand(t, "cmd1", "this is not json")
+	dto := tstValidEmailDto()
+	dto.SendAt = time.Now().Add(configuration.SchedulerMaxHorizon() + time.Hour).Format(time.RFC3339)
+	tstSendCommand(t, "cmd2", tstRenderJson(dto))
+	tstSendCommand(t, "cmd3", "{}")
+	tstWaitForCommitted(3)
+
+	docs.Then("Then all are committed and nothing is sent")
+	require.Len(t, broker.Committed(), 3)
+	require.Empty(t, sentEmails.Sent())
+
+	docs.Then("And all are published to the error topic with their original key and headers")
+	errors := broker.Published(configuration.KafkaErrorTopic())
+	require.Len(t, errors, 3)
+
+	parseError := commands.CommandErrorDto{}
+	require.Nil(t, json.Unmarshal(errors[0].Value, &parseError))
+	require.Equal(t, "cmd1", string(errors[0].Key))
+	require.Equal(t, "acceptance-test", errors[0].Headers["origin"])
+	require.Equal(t, "email.parse.error", parseError.Message)
+	require.Equal(t, `"this is not json"`, string(parseError.Command))
+
+	validationError := commands.CommandErrorDto{}
+	require.Nil(t, json.Unmarshal(errors[1].Value, &validationError))
+	require.Equal(t, "cmd2", string(errors[1].Key))
+	require.Equal(t, "email.validation.error", validationError.Message)
+	require.JSONEq(t, tstRenderJson(dto), string(validationError.Command))
+
+	emptyError := commands.CommandErrorDto{}
+	require.Nil(t, json.Unmarshal(errors[2].Value, &emptyError))
+	require.Equal(t, "cmd3", string(errors[2].Key))
+	require.Equal(t, "email.validation.error", emptyError.Message)
+	require.Equal(t, []string{"to_address must be an email address"}, emptyError.Details)
+}
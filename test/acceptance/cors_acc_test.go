@@ -0,0 +1,90 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+const tstAllowedOrigin = "https://admin.example.com"
+
+func TestCors_PreflightFromAllowedOrigin_ShouldAllow(t *testing.T) {
+	docs.Given("Given a running application that allows cross origin requests from the admin frontend")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When the browser of the admin frontend asks whether it may list emails with a token")
+	response, err := tstPerformWithHeaders(http.MethodOptions, "/api/rest/v2/emails", nil, "", tstUnauthenticated(),
+		map[string]string{
+			headers.Origin:                      tstAllowedOrigin,
+			headers.AccessControlRequestMethod:  http.MethodGet,
+			headers.AccessControlRequestHeaders: "authorization",
+		})
+
+	docs.Then("Then the preflight request is answered without asking for a token, and allows the request")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNoContent, response.status)
+	require.Equal(t, tstAllowedOrigin, response.header.Get(headers.AccessControlAllowOrigin))
+	require.Equal(t, "true", response.header.Get(headers.AccessControlAllowCredentials))
+	require.Equal(t, "GET, POST, PUT, PATCH, DELETE", response.header.Get(headers.AccessControlAllowMethods))
+	require.Contains(t, response.header.Get(headers.AccessControlAllowHeaders), "Authorization")
+	require.Equal(t, "600", response.header.Get(headers.AccessControlMaxAge))
+	require.Equal(t, headers.Origin, response.header.Get(headers.Vary))
+}
+
+func TestCors_RequestFromAllowedOrigin_ShouldBeReadable(t *testing.T) {
+	docs.Given("Given a running application that allows cross origin requests from the admin frontend")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When the admin frontend lists emails")
+	response, err := tstPerformWithHeaders(http.MethodGet, "/api/rest/v2/emails", nil, "", tstValidAdminToken(),
+		map[string]string{headers.Origin: tstAllowedOrigin})
+
+	docs.Then("Then the response lets the browser read it, including the request id")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, tstAllowedOrigin, response.header.Get(headers.AccessControlAllowOrigin))
+	require.Equal(t, "true", response.header.Get(headers.AccessControlAllowCredentials))
+	require.Contains(t, response.header.Get(headers.AccessControlExposeHeaders), "X-Request-Id")
+}
+
+func TestCors_PreflightFromRejectedOrigin_ShouldForbid(t *testing.T) {
+	docs.Given("Given a running application that allows cross origin requests from the admin frontend")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When the browser of some other site asks whether it may send an email")
+	response, err := tstPerformWithHeaders(http.MethodOptions, "/api/rest/v2/emails", nil, "", tstUnauthenticated(),
+		map[string]string{
+			headers.Origin:                      "https://evil.example.com",
+			headers.AccessControlRequestMethod:  http.MethodPost,
+			headers.AccessControlRequestHeaders: "content-type",
+		})
+
+	docs.Then("Then the preflight request is forbidden, so the browser does not send the email")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+	require.Empty(t, response.header.Get(headers.AccessControlAllowOrigin))
+	require.Empty(t, response.header.Get(headers.AccessControlAllowMethods))
+	require.Empty(t, sentEmails.Sent())
+}
+
+func TestCors_RequestFromRejectedOrigin_ShouldNotBeReadable(t *testing.T) {
+	docs.Given("Given a running application that allows cross origin requests from the admin frontend")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When some other site lists emails with a token it got hold of")
+	response, err := tstPerformWithHeaders(http.MethodGet, "/api/rest/v2/emails", nil, "", tstValidAdminToken(),
+		map[string]string{headers.Origin: "https://evil.example.com"})
+
+	docs.Then("Then the response does not let the browser read it")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Empty(t, response.header.Get(headers.AccessControlAllowOrigin))
+	require.Empty(t, response.header.Get(headers.AccessControlAllowCredentials))
+	require.Empty(t, response.header.Get(headers.AccessControlExposeHeaders))
+}
@@ -0,0 +1,257 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/email"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tstValidEmailDto() email.EmailDto {
+	return email.EmailDto{
+		ToAddress: "someone@example.com",
+		Subject:   "Reminder",
+		Body:      "Do not forget.",
+	}
+}
+
+func TestSendEmail_Immediate_ShouldSend(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a valid email without send_at is submitted")
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(tstValidEmailDto()), tstUnauthenticated())
+
+	docs.Then("Then it is sent right away")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	result := email.EmailResultDto{}
+	require.Nil(t, tstParseJson(response.body, &result))
+	require.Equal(t, "sent", result.Status)
+	require.Len(t, sentEmails.Sent(), 1)
+	require.Equal(t, result.Id, sentEmails.Sent()[0].ID)
+}
+
+func TestSendEmail_Scheduled_ShouldHoldUntilCancelled(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a valid email with send_at in the future is submitted")
+	dto := tstValidEmailDto()
+	dto.SendAt = time.Now().Add(time.Hour).Format(time.RFC3339)
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(dto), tstUnauthenticated())
+
+	docs.Then("Then it is held as scheduled and not sent")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	result := email.EmailResultDto{}
+	require.Nil(t, tstParseJson(response.body, &result))
+	require.Equal(t, "scheduled", result.Status)
+	require.Empty(t, sentEmails.Sent())
+
+	docs.Then("And it can be cancelled exactly once")
+	response, err = tstPerformDelete("/api/rest/v1/scheduled/"+result.Id, tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	response, err = tstPerformDelete("/api/rest/v1/scheduled/"+result.Id, tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusConflict, response.status)
+}
+
+func TestSendEmail_ScheduledBeyondHorizon_ShouldReject(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an email is submitted with send_at beyond the configured maximum horizon")
+	dto := tstValidEmailDto()
+	dto.SendAt = time.Now().Add(24 * 365 * time.Hour).Format(time.RFC3339)
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(dto), tstUnauthenticated())
+
+	docs.Then("Then it is rejected with a validation error")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, "email.validation.error", errorDto.Message)
+	require.Empty(t, sentEmails.Sent())
+}
+
+func TestSendEmail_InvalidSendAt_ShouldReject(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an email is submitted with a send_at that is not an RFC 3339 timestamp")
+	dto := tstValidEmailDto()
+	dto.SendAt = "tomorrow"
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(dto), tstUnauthenticated())
+
+	docs.Then("Then it is rejected because it does not match the api spec")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, "request.validation.error", errorDto.Message)
+	require.Equal(t, []string{`/send_at: string doesn't match the format "date-time"`}, errorDto.Details)
+}
+
+func TestCancelEmail_Unauthenticated_ShouldDeny(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an anonymous caller tries to cancel a scheduled email")
+	response, err := tstPerformDelete("/api/rest/v1/scheduled/some-id", tstUnauthenticated())
+
+	docs.Then("Then the request is denied")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.status)
+}
+
+func TestCancelEmail_OtherUser_ShouldDeny(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.Given("And a scheduled email submitted by a logged in user")
+	dto := tstValidEmailDto()
+	dto.SendAt = time.Now().Add(time.Hour).Format(time.RFC3339)
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(dto), tstValidUserToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	result := email.EmailResultDto{}
+	require.Nil(t, tstParseJson(response.body, &result))
+
+	docs.When("When another user who is not admin tries to cancel it")
+	response, err = tstPerformDelete("/api/rest/v1/scheduled/"+result.Id, tstValidOtherUserToken())
+
+	docs.Then("Then the request is denied")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+
+	docs.Then("And the user who submitted it can still cancel it")
+	response, err = tstPerformDelete("/api/rest/v1/scheduled/"+result.Id, tstValidUserToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNoContent, response.status)
+}
+
+func TestCancelEmail_Unknown_ShouldReturnNotFound(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin tries to cancel an email that does not exist")
+	response, err := tstPerformDelete("/api/rest/v1/scheduled/does-not-exist", tstValidAdminToken())
+
+	docs.Then("Then the request fails with not found")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.status)
+}
+
+func TestCancelEmail_UnknownAsUser_ShouldDeny(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a user who is not admin tries to cancel an email that does not exist")
+	response, err := tstPerformDelete("/api/rest/v1/scheduled/does-not-exist", tstValidUserToken())
+
+	docs.Then("Then the request is denied, the same as for an email of another user")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+}
+
+func TestSendEmail_UnknownFieldAndTrailingData_ShouldRejectWithDetails(t *testing.T) {
+	docs.Given("Given a running application with strict json decoding")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When emails are submitted with an additional field, and with data after the json object")
+	additional, err := tstPerformPost("/api/rest/v1/sendmail", `{"to_address":"someone@example.com","subject":"Reminder","body":"Hi","priority":1}`, tstUnauthenticated())
+	require.Nil(t, err)
+	trailing, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(tstValidEmailDto())+`{"to_address":"other@example.com"}`, tstUnauthenticated())
+	require.Nil(t, err)
+
+	docs.Then("Then all are rejected, naming the field and the problem")
+	for i, expected := range []struct {
+		response tstWebResponse
+		detail   string
+	}{
+		{additional, "priority: is not a known field"},
+		{trailing, "body: must contain a single json object and nothing after it"},
+	} {
+		require.Equal(t, http.StatusBadRequest, expected.response.status, "case %d", i)
+		errorDto := apierrors.ErrorDto{}
+		require.Nil(t, tstParseJson(expected.response.body, &errorDto))
+		require.Equal(t, "email.parse.error", errorDto.Message)
+		require.Equal(t, []string{expected.detail}, errorDto.Details)
+	}
+	require.Empty(t, sentEmails.Sent())
+}
+
+func TestSendEmail_TooLarge_ShouldReject(t *testing.T) {
+	docs.Given("Given a running application with a request body limit of 16 KiB")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an email with a larger body is submitted")
+	dto := tstValidEmailDto()
+	dto.Body = strings.Repeat("x", 20000)
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(dto), tstUnauthenticated())
+
+	docs.Then("Then it is rejected as too large")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusRequestEntityTooLarge, response.status)
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, "request.toolarge.error", errorDto.Message)
+	require.NotEmpty(t, errorDto.RequestId)
+	require.Empty(t, sentEmails.Sent())
+}
+
+func TestSendEmail_ViolatingSpec_ShouldRejectWithJsonPointers(t *testing.T) {
+	docs.Given("Given a running application that validates requests against its api spec")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an email is submitted with a number as the address and without a subject")
+	response, err := tstPerformPost("/api/rest/v1/sendmail", `{"to_address":42,"body":"Hi"}`, tstUnauthenticated())
+
+	docs.Then("Then it is rejected, listing the violations by json pointer")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, "request.validation.error", errorDto.Message)
+	require.Equal(t, []string{
+		`/subject: property "subject" is missing`,
+		"/to_address: Field must be set to string or not be present",
+	}, errorDto.Details)
+	require.Empty(t, sentEmails.Sent())
+}
+
+func TestSendEmail_InvalidAddress_ShouldRejectWithJsonPointer(t *testing.T) {
+	docs.Given("Given a running application that validates requests against its api spec")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an email is submitted with something that is not an email address")
+	dto := tstValidEmailDto()
+	dto.ToAddress = "someone at example dot com"
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(dto), tstUnauthenticated())
+
+	docs.Then("Then it is rejected because of the format of to_address")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, []string{`/to_address: string doesn't match the format "email"`}, errorDto.Details)
+}
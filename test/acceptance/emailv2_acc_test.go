@@ -0,0 +1,269 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/api/v2/email"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func tstValidNewEmailDto() email.NewEmailDto {
+	return email.NewEmailDto{
+		ToAddress: "someone@example.com",
+		Subject:   "Reminder",
+		Body:      "Do not forget.",
+	}
+}
+
+// submits as the user of tstValidUserToken
+func tstCreateEmailV2(t *testing.T, dto email.NewEmailDto) email.EmailResourceDto {
+	response, err := tstPerformPost("/api/rest/v2/emails", tstRenderJson(dto), tstValidUserToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, response.status)
+	created := email.EmailResourceDto{}
+	require.Nil(t, tstParseJson(response.body, &created))
+	return created
+}
+
+func TestCreateEmailV2_Immediate_ShouldSendAndPointToIt(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an email is created through the v2 api")
+	response, err := tstPerformPost("/api/rest/v2/emails", tstRenderJson(tstValidNewEmailDto()), tstUnauthenticated())
+
+	docs.Then("Then it is sent, and the response is a 201 with the email and its location")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, response.status)
+	created := email.EmailResourceDto{}
+	require.Nil(t, tstParseJson(response.body, &created))
+	require.NotEmpty(t, created.Id)
+	require.Equal(t, "/api/rest/v2/emails/"+created.Id, response.location)
+	require.Equal(t, "sent", created.Status)
+	require.Equal(t, "someone@example.com", created.ToAddress)
+	require.Equal(t, "Reminder", created.Subject)
+	require.Equal(t, 1, created.Attempts)
+	require.NotEmpty(t, created.CreatedAt)
+	require.NotEmpty(t, created.SentAt)
+	require.Len(t, sentEmails.Sent(), 1)
+	require.Empty(t, response.header.Get("Deprecation"))
+}
+
+func TestGetEmailV2_ShouldReturnCurrentStatus(t *testing.T) {
+	docs.Given("Given a running application with a scheduled email")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	dto := tstValidNewEmailDto()
+	dto.SendAt = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	created := tstCreateEmailV2(t, dto)
+	require.Equal(t, "scheduled", created.Status)
+
+	docs.When("When it is cancelled and then fetched from its location")
+	response, err := tstPerformDelete("/api/rest/v2/emails/"+created.Id, tstValidUserToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNoContent, response.status)
+	response, err = tstPerformGet("/api/rest/v2/emails/"+created.Id, tstValidUserToken())
+
+	docs.Then("Then it is returned with its new status")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	fetched := email.EmailResourceDto{}
+	require.Nil(t, tstParseJson(response.body, &fetched))
+	require.Equal(t, created.Id, fetched.Id)
+	require.Equal(t, "cancelled", fetched.Status)
+	require.Equal(t, dto.SendAt, fetched.SendAt)
+	require.Empty(t, fetched.SentAt)
+	require.Empty(t, sentEmails.Sent())
+}
+
+func TestGetEmailV2_Unauthenticated_ShouldDeny(t *testing.T) {
+	docs.Given("Given a running application with an email")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	created := tstCreateEmailV2(t, tstValidNewEmailDto())
+
+	docs.When("When an anonymous caller tries to fetch it")
+	response, err := tstPerformGet("/api/rest/v2/emails/"+created.Id, tstUnauthenticated())
+
+	docs.Then("Then the request is denied")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.status)
+}
+
+func TestGetEmailV2_OtherUser_ShouldForbid(t *testing.T) {
+	docs.Given("Given a running application with an email submitted by a logged in user")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	created := tstCreateEmailV2(t, tstValidNewEmailDto())
+
+	docs.When("When another user who is not admin tries to fetch it")
+	response, err := tstPerformGet("/api/rest/v2/emails/"+created.Id, tstValidOtherUserToken())
+
+	docs.Then("Then the request is forbidden")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+
+	docs.Then("And an admin may still fetch it")
+	response, err = tstPerformGet("/api/rest/v2/emails/"+created.Id, tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+}
+
+func TestGetEmailV2_Unknown_ShouldReturnNotFound(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin fetches an email that does not exist")
+	response, err := tstPerformGet("/api/rest/v2/emails/does-not-exist", tstValidAdminToken())
+
+	docs.Then("Then the request fails with not found")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.status)
+}
+
+func TestGetEmailV2_UnknownAsUser_ShouldForbid(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a user who is not admin fetches an email that does not exist")
+	response, err := tstPerformGet("/api/rest/v2/emails/does-not-exist", tstValidUserToken())
+
+	docs.Then("Then the request is forbidden, just like for an email of someone else")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+}
+
+func TestDeleteEmailV2_Sent_ShouldConflict(t *testing.T) {
+	docs.Given("Given a running application with an email that was sent right away")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	created := tstCreateEmailV2(t, tstValidNewEmailDto())
+
+	docs.When("When its submitter tries to cancel it")
+	response, err := tstPerformDelete("/api/rest/v2/emails/"+created.Id, tstValidUserToken())
+
+	docs.Then("Then the request fails because the email is no longer pending")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusConflict, response.status)
+	require.Len(t, sentEmails.Sent(), 1)
+}
+
+func TestDeleteEmailV2_OtherUser_ShouldForbid(t *testing.T) {
+	docs.Given("Given a running application with a scheduled email submitted by a logged in user")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	dto := tstValidNewEmailDto()
+	dto.SendAt = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	created := tstCreateEmailV2(t, dto)
+
+	docs.When("When another user who is not admin tries to cancel it")
+	response, err := tstPerformDelete("/api/rest/v2/emails/"+created.Id, tstValidOtherUserToken())
+
+	docs.Then("Then the request is forbidden and the email stays scheduled")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+	response, err = tstPerformGet("/api/rest/v2/emails/"+created.Id, tstValidUserToken())
+	require.Nil(t, err)
+	fetched := email.EmailResourceDto{}
+	require.Nil(t, tstParseJson(response.body, &fetched))
+	require.Equal(t, "scheduled", fetched.Status)
+}
+
+func TestListEmailsV2_ShouldPageWithCursor(t *testing.T) {
+	docs.Given("Given a running application with three emails")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	ids := []string{}
+	for i := 0; i < 3; i++ {
+		ids = append(ids, tstCreateEmailV2(t, tstValidNewEmailDto()).Id)
+	}
+
+	docs.When("When an admin lists them two at a time")
+	response, err := tstPerformGet("/api/rest/v2/emails?limit=2", tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	first := email.EmailPageDto{}
+	require.Nil(t, tstParseJson(response.body, &first))
+	require.NotEmpty(t, first.NextCursor)
+	response, err = tstPerformGet("/api/rest/v2/emails?limit=2&cursor="+first.NextCursor, tstValidAdminToken())
+
+	docs.Then("Then the second page holds the remaining email and has no next cursor")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	second := email.EmailPageDto{}
+	require.Nil(t, tstParseJson(response.body, &second))
+	require.Empty(t, second.NextCursor)
+	require.Len(t, first.Items, 2)
+	require.Len(t, second.Items, 1)
+	require.ElementsMatch(t, ids, []string{first.Items[0].Id, first.Items[1].Id, second.Items[0].Id})
+}
+
+func TestListEmailsV2_NotAdmin_ShouldForbid(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a caller without the admin role lists emails")
+	response, err := tstPerformGet("/api/rest/v2/emails", tstValidUserToken())
+
+	docs.Then("Then the request is forbidden")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+}
+
+func TestListEmailsV2_InvalidCursor_ShouldReject(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin lists emails with a made up cursor")
+	response, err := tstPerformGet("/api/rest/v2/emails?cursor=bm9uc2Vuc2U", tstValidAdminToken())
+
+	docs.Then("Then the request is rejected as an invalid query")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, "email.query.error", errorDto.Message)
+}
+
+func TestSendEmailV1_ShouldAnnounceDeprecation(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an email is sent through the v1 api")
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(tstValidEmailDto()), tstUnauthenticated())
+
+	docs.Then("Then it is sent, but the response announces the deprecation, the sunset and the successor")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, "@1792368000", response.header.Get("Deprecation"))
+	require.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", response.header.Get("Sunset"))
+	require.Equal(t, `</api/rest/v2/emails>; rel="successor-version"`, response.header.Get("Link"))
+	require.Len(t, sentEmails.Sent(), 1)
+}
+
+func TestCancelEmailV1_ShouldAnnounceDeprecation(t *testing.T) {
+	docs.Given("Given a running application with a scheduled email")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	dto := tstValidNewEmailDto()
+	dto.SendAt = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	created := tstCreateEmailV2(t, dto)
+
+	docs.When("When it is cancelled through the v1 api")
+	response, err := tstPerformDelete("/api/rest/v1/scheduled/"+created.Id, tstValidUserToken())
+
+	docs.Then("Then it is cancelled, but the response announces the deprecation and the successor for this email")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNoContent, response.status)
+	require.Equal(t, "@1792368000", response.header.Get("Deprecation"))
+	require.Equal(t, `</api/rest/v2/emails/`+created.Id+`>; rel="successor-version"`, response.header.Get("Link"))
+}
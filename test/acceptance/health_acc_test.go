@@ -0,0 +1,74 @@
+package acceptance
+
+import (
+	"context"
+	"errors"
+	apihealth "github.com/StephanHCB/go-mailer-service/api/v1/health"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/health"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHealth_Live(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When the liveness probe is called")
+	response, err := tstPerformGet("/health/live", tstUnauthenticated())
+
+	docs.Then("Then it reports up")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	report := apihealth.HealthReportDto{}
+	require.Nil(t, tstParseJson(response.body, &report))
+	require.Equal(t, "UP", report.Status)
+}
+
+func TestHealth_Ready_AllUp(t *testing.T) {
+	docs.Given("Given a running application with all components available")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When the readiness probe is called")
+	response, err := tstPerformGet("/health/ready", tstUnauthenticated())
+
+	docs.Then("Then it reports up with a result for each component")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	report := apihealth.HealthReportDto{}
+	require.Nil(t, tstParseJson(response.body, &report))
+	require.Equal(t, "UP", report.Status)
+	require.Len(t, report.Components, 2)
+	require.Equal(t, "configuration", report.Components[0].Name)
+	require.Equal(t, "UP", report.Components[0].Status)
+	require.Equal(t, "storage", report.Components[1].Name)
+	require.Equal(t, "UP", report.Components[1].Status)
+}
+
+func TestHealth_Ready_ComponentDown(t *testing.T) {
+	docs.Given("Given a running application whose mail relay is unreachable")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	health.Register("mail", 50*time.Millisecond, func(ctx context.Context) error {
+		return errors.New("dial tcp: connection refused")
+	})
+
+	docs.When("When the readiness probe is called")
+	response, err := tstPerformGet("/health/ready", tstUnauthenticated())
+
+	docs.Then("Then it reports down and says which component failed")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, response.status)
+	report := apihealth.HealthReportDto{}
+	require.Nil(t, tstParseJson(response.body, &report))
+	require.Equal(t, "DOWN", report.Status)
+	require.Len(t, report.Components, 3)
+	require.Equal(t, "mail", report.Components[1].Name)
+	require.Equal(t, "DOWN", report.Components[1].Status)
+	require.Equal(t, "dial tcp: connection refused", report.Components[1].Error)
+	require.Equal(t, "UP", report.Components[2].Status)
+}
@@ -0,0 +1,108 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/management"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/buildinfo"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestManagement_Info(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the build info")
+	response, err := tstPerformGet("/management/info", tstValidAdminToken())
+
+	docs.Then("Then it contains the version and service name")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	info := management.InfoDto{}
+	require.Nil(t, tstParseJson(response.body, &info))
+	require.Equal(t, "mailer-service", info.ServiceName)
+	require.Equal(t, buildinfo.Version, info.Version)
+	require.NotEmpty(t, info.GoVersion)
+}
+
+func TestManagement_Config_ShouldRedactSecrets(t *testing.T) {
+	docs.Given("Given a running application with a configured secret")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin requests the effective configuration")
+	response, err := tstPerformGet("/management/config", tstValidAdminToken())
+
+	docs.Then("Then it lists all values, but the secret is redacted")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.NotContains(t, response.body, "demosecret")
+	config := management.ConfigDto{}
+	require.Nil(t, tstParseJson(response.body, &config))
+	values := map[string]interface{}{}
+	for _, item := range config.Items {
+		values[item.Key] = item.Value
+	}
+	require.Equal(t, "mailer-service", values["service.name"])
+	require.Equal(t, float64(8080), values["server.port"])
+	require.Equal(t, "*****", values["security.secret"])
+	require.Equal(t, "", values["mail.smtp.password"])
+}
+
+func TestManagement_Loggers_ShouldChangeLevel(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	original := logging.Level()
+	defer func() { _ = logging.SetLevel(original) }()
+
+	docs.When("When an admin changes the root log level to debug")
+	response, err := tstPerformPut("/management/loggers/root", tstRenderJson(management.LoggerDto{Level: "debug"}), tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+
+	docs.Then("Then the new level is reported")
+	response, err = tstPerformGet("/management/loggers", tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	loggers := management.LoggersDto{}
+	require.Nil(t, tstParseJson(response.body, &loggers))
+	require.Equal(t, []management.LoggerDto{{Name: "root", Level: "debug"}, {Name: "requestlogging", Level: "info"}}, loggers.Loggers)
+
+	docs.Then("And package levels from the configuration can be changed too")
+	response, err = tstPerformPut("/management/loggers/requestlogging", tstRenderJson(management.LoggerDto{Level: "warn"}), tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	logger := management.LoggerDto{}
+	require.Nil(t, tstParseJson(response.body, &logger))
+	require.Equal(t, management.LoggerDto{Name: "requestlogging", Level: "warn"}, logger)
+
+	docs.Then("And unknown levels and loggers are rejected")
+	response, err = tstPerformPut("/management/loggers/root", tstRenderJson(management.LoggerDto{Level: "verbose"}), tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	response, err = tstPerformPut("/management/loggers/nosuchlogger", tstRenderJson(management.LoggerDto{Level: "info"}), tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.status)
+}
+
+func TestManagement_ShouldRequireAdmin(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When the management endpoints are called anonymously or without the admin role")
+	for _, path := range []string{"/management/info", "/management/config", "/management/loggers"} {
+		anonymous, err := tstPerformGet(path, tstUnauthenticated())
+		require.Nil(t, err)
+		user, err := tstPerformGet(path, tstValidUserToken())
+		require.Nil(t, err)
+
+		docs.Then("Then the request is denied")
+		require.Equal(t, http.StatusUnauthorized, anonymous.status, path)
+		require.Equal(t, http.StatusForbidden, user.status, path)
+	}
+}
@@ -0,0 +1,109 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestProblem_NotFound_ShouldRenderProblem(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a client that accepts problems tries to cancel an email that does not exist")
+	response, err := tstPerformWithHeaders(http.MethodDelete, "/api/rest/v1/scheduled/does-not-exist", nil, "", tstValidAdminToken(),
+		map[string]string{headers.Accept: "application/problem+json"})
+
+	docs.Then("Then the error is reported as an RFC 7807 problem")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.status)
+	require.True(t, strings.HasPrefix(response.contentType, "application/problem+json"))
+	problem := apierrors.ProblemDto{}
+	require.Nil(t, tstParseJson(response.body, &problem))
+	require.Equal(t, "urn:mailer-service:problem:email.notfound.error", problem.Type)
+	require.Equal(t, "Email not found", problem.Title)
+	require.Equal(t, http.StatusNotFound, problem.Status)
+	require.Empty(t, problem.Detail)
+	require.Equal(t, "/api/rest/v1/scheduled/does-not-exist", problem.Instance)
+	require.NotEmpty(t, problem.RequestId)
+	require.NotEmpty(t, problem.Timestamp)
+}
+
+func TestProblem_ValidationError_ShouldRenderDetail(t *testing.T) {
+	docs.Given("Given a running application that validates requests against its api spec")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a client that accepts problems submits an email without a subject")
+	response, err := tstPerformWithHeaders(http.MethodPost, "/api/rest/v1/sendmail", strings.NewReader(`{"to_address":"someone@example.com","body":"Hi"}`),
+		"application/json", tstUnauthenticated(), map[string]string{headers.Accept: "application/problem+json"})
+
+	docs.Then("Then the problem names the violation in its detail")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	problem := apierrors.ProblemDto{}
+	require.Nil(t, tstParseJson(response.body, &problem))
+	require.Equal(t, "urn:mailer-service:problem:request.validation.error", problem.Type)
+	require.Equal(t, http.StatusBadRequest, problem.Status)
+	require.Equal(t, `/subject: property "subject" is missing`, problem.Detail)
+	require.Empty(t, sentEmails.Sent())
+}
+
+func TestProblem_JsonPreferred_ShouldRenderErrorDto(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a client that prefers plain json over problems tries to cancel an email that does not exist")
+	response, err := tstPerformWithHeaders(http.MethodDelete, "/api/rest/v1/scheduled/does-not-exist", nil, "", tstValidAdminToken(),
+		map[string]string{headers.Accept: "application/json, application/problem+json"})
+
+	docs.Then("Then the error is reported as the default error response")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.status)
+	require.True(t, strings.HasPrefix(response.contentType, "application/json"))
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, "email.notfound.error", errorDto.Message)
+}
+
+func TestProblem_NoAcceptHeader_ShouldRenderErrorDto(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a client that does not say what it accepts tries to cancel an email that does not exist")
+	response, err := tstPerformDelete("/api/rest/v1/scheduled/does-not-exist", tstValidAdminToken())
+
+	docs.Then("Then the error is reported as the default error response")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.status)
+	require.True(t, strings.HasPrefix(response.contentType, "application/json"))
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, "email.notfound.error", errorDto.Message)
+}
+
+func TestProblem_InvalidToken_ShouldRenderProblem(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a client that accepts problems presents an invalid token")
+	response, err := tstPerformWithHeaders(http.MethodGet, "/api/rest/v2/emails/some-id", nil, "", "not-a-token",
+		map[string]string{headers.Accept: "application/problem+json"})
+
+	docs.Then("Then the request is denied with a problem, like any other unauthorized request")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.status)
+	require.True(t, strings.HasPrefix(response.contentType, "application/problem+json"))
+	problem := apierrors.ProblemDto{}
+	require.Nil(t, tstParseJson(response.body, &problem))
+	require.Equal(t, "urn:mailer-service:problem:auth.unauthorized.error", problem.Type)
+	require.Equal(t, http.StatusUnauthorized, problem.Status)
+}
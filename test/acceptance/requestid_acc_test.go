@@ -0,0 +1,66 @@
+package acceptance
+
+import (
+	"context"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/messaging"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestId_FromTrustedCaller_ShouldBePassedOn(t *testing.T) {
+	docs.Given("Given a running application that trusts requests from localhost")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an email is submitted with an X-Request-Id header")
+	response, err := tstPerformWithHeaders(http.MethodPost, "/api/rest/v1/sendmail", strings.NewReader(tstRenderJson(tstValidEmailDto())),
+		"application/json", tstUnauthenticated(), map[string]string{"X-Request-Id": "caller-id-42"})
+
+	docs.Then("Then the request id is returned and passed on to the sent email")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, "caller-id-42", response.requestId)
+	require.Len(t, sentEmails.Sent(), 1)
+	require.Equal(t, "caller-id-42", sentEmails.Sent()[0].RequestID)
+}
+
+func TestRequestId_Missing_ShouldBeGeneratedAndReturnedInErrors(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an invalid request without X-Request-Id header is made")
+	response, err := tstPerformPost("/api/rest/v1/sendmail", "{", tstUnauthenticated())
+
+	docs.Then("Then a request id is generated and included in the error response")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	require.NotEmpty(t, response.requestId)
+	errorDto := apierrors.ErrorDto{}
+	require.Nil(t, tstParseJson(response.body, &errorDto))
+	require.Equal(t, response.requestId, errorDto.RequestId)
+}
+
+func TestRequestId_FromCommandHeader_ShouldBePassedOn(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a send-email command with an X-Request-Id header arrives")
+	message := &messaging.Message{Key: []byte("cmd1"), Value: []byte(tstRenderJson(tstValidEmailDto())), Headers: map[string]string{"X-Request-Id": "producer-id-7"}}
+	require.Nil(t, broker.Publish(context.Background(), configuration.KafkaCommandTopic(), message))
+	tstWaitForCommitted(1)
+
+	docs.Then("Then the request id is passed on to the sent email")
+	for i := 0; i < 100 && len(sentEmails.Sent()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Len(t, sentEmails.Sent(), 1)
+	require.Equal(t, "producer-id-7", sentEmails.Sent()[0].RequestID)
+}
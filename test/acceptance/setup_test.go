@@ -1,17 +1,30 @@
 package acceptance
 
 import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
 	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/health"
 	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/mailsender"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/messaging"
+	"github.com/StephanHCB/go-mailer-service/internal/service/commandsrv"
 	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/StephanHCB/go-mailer-service/internal/service/eventsrv"
+	"github.com/StephanHCB/go-mailer-service/internal/service/webhooksrv"
 	"github.com/StephanHCB/go-mailer-service/web"
 	"net/http/httptest"
+	"time"
 )
 
 // placing these here because they are package global
 
 var (
 	ts *httptest.Server
+	sentEmails *mailsender.InMemorySender
+	webhookDispatcher *webhooksrv.Dispatcher
+	broker *messaging.InMemoryBroker
+	commandConsumer *commandsrv.Consumer
 	failures []error
 	warnings []string
 )
@@ -37,6 +50,7 @@ func tstSetupConfig(configPath string, secretsPath string) {
 	failures = []error{}
 	warnings = []string{}
 	logging.SetupForTesting()
+	health.ResetForTesting()
 	configuration.SetupForIntegrationTest(tstFail, tstWarn, configPath, secretsPath)
 	if !tstHadFailures() {
 		logging.PostConfigSetup()
@@ -48,13 +62,32 @@ func tstHadFailures() bool {
 }
 
 func tstSetupHttpTestServer() {
-	router := web.Create()
-	web.AddRoutes(router, emailsrv.Create())
+	database.Open()
+	audit.Setup()
+	sentEmails = mailsender.CreateInMemorySender()
+	mailsender.ActiveMailSender = sentEmails
+	eventsrv.ResetForTesting()
+	webhookDispatcher = webhooksrv.StartDispatcher(database.GetRepository(), 3, 10*time.Millisecond, time.Second, eventsrv.ModeStructured)
+	eventsrv.Register(webhookDispatcher)
+
+	emailService := emailsrv.Create()
+	broker = messaging.CreateInMemoryBroker(configuration.KafkaCommandTopic())
+	commandConsumer = commandsrv.StartConsumer(broker, emailService, configuration.KafkaErrorTopic())
+
+	router, err := web.Create()
+	if err != nil {
+		panic(err)
+	}
+	web.AddRoutes(router, emailService)
 	ts = httptest.NewServer(router)
 }
 
 func tstShutdown() {
 	if !tstHadFailures() {
 		ts.Close()
+		commandConsumer.Stop()
+		webhookDispatcher.Stop()
+		audit.Close()
+		database.Close()
 	}
 }
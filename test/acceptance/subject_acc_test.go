@@ -0,0 +1,83 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/email"
+	"github.com/StephanHCB/go-mailer-service/api/v1/subject"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestEraseSubject_ShouldDeleteStoredEmails(t *testing.T) {
+	docs.Given("Given a running application that has sent an email")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	response, err := tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(tstValidEmailDto()), tstUnauthenticated())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	result := email.EmailResultDto{}
+	require.Nil(t, tstParseJson(response.body, &result))
+
+	docs.When("When an admin erases the recipient")
+	response, err = tstPerformDelete("/api/rest/v1/subjects/someone@example.com", tstValidAdminToken())
+
+	docs.Then("Then the report lists the deleted email and the retained hashed audit entries")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	report := subject.ErasureReportDto{}
+	require.Nil(t, tstParseJson(response.body, &report))
+	require.Equal(t, subject.ErasureReportDto{
+		DeletedEmails:        []string{result.Id},
+		AuditEntriesRetained: 2,
+		AuditRecipients:      "hashed",
+	}, report)
+
+	docs.Then("And erasing again finds nothing left to delete")
+	response, err = tstPerformDelete("/api/rest/v1/subjects/someone@example.com", tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	report = subject.ErasureReportDto{}
+	require.Nil(t, tstParseJson(response.body, &report))
+	require.Empty(t, report.DeletedEmails)
+}
+
+func TestEraseSubject_InvalidAddress_ShouldBeRejected(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin erases something that is not an email address")
+	response, err := tstPerformDelete("/api/rest/v1/subjects/nobody", tstValidAdminToken())
+
+	docs.Then("Then the request is rejected")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+	require.Contains(t, response.body, "subject.address.invalid")
+}
+
+func TestEraseSubject_Unauthenticated_ShouldBeDenied(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an anonymous caller tries to erase a recipient")
+	response, err := tstPerformDelete("/api/rest/v1/subjects/someone@example.com", tstUnauthenticated())
+
+	docs.Then("Then the request is denied")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.status)
+}
+
+func TestEraseSubject_NoAdmin_ShouldBeForbidden(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When a user without the admin role tries to erase a recipient")
+	response, err := tstPerformDelete("/api/rest/v1/subjects/someone@example.com", tstValidUserToken())
+
+	docs.Then("Then the request is forbidden")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusForbidden, response.status)
+}
@@ -0,0 +1,45 @@
+package acceptance
+
+import (
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestSwagger_V2(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When the swagger 2.0 spec is requested")
+	response, err := tstPerformGet("/swagger.json", tstUnauthenticated())
+
+	docs.Then("Then it is served with the configured address as host")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	spec := map[string]interface{}{}
+	require.Nil(t, tstParseJson(response.body, &spec))
+	require.Equal(t, "2.0", spec["swagger"])
+	require.Equal(t, "localhost:8080", spec["host"])
+	require.Equal(t, []interface{}{"http"}, spec["schemes"])
+	require.Contains(t, spec["paths"], "/api/rest/v1/sendmail")
+}
+
+func TestSwagger_V3(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When the openapi 3.0 spec is requested")
+	response, err := tstPerformGet("/openapi.json", tstUnauthenticated())
+
+	docs.Then("Then it is served with the configured address as the only server")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	spec := map[string]interface{}{}
+	require.Nil(t, tstParseJson(response.body, &spec))
+	require.Equal(t, "3.0.3", spec["openapi"])
+	require.Equal(t, []interface{}{map[string]interface{}{"url": "http://localhost:8080"}}, spec["servers"])
+	require.Contains(t, spec["paths"], "/api/rest/v1/sendmail")
+}
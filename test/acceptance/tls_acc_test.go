@@ -0,0 +1,81 @@
+package acceptance
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"github.com/StephanHCB/go-mailer-service/api/v1/audit"
+	"github.com/StephanHCB/go-mailer-service/api/v1/email"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/certificates"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/certificates/certtest"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serves the same router as ts, but with tls and optional client certificates
+func tstSetupTlsServer(t *testing.T) (*httptest.Server, *certtest.PKI) {
+	pki, err := certtest.CreatePKI(t.TempDir())
+	require.Nil(t, err)
+	reloader, err := certificates.Create(pki.CertFile, pki.KeyFile, pki.CaFile)
+	require.Nil(t, err)
+	server := httptest.NewUnstartedServer(ts.Config.Handler)
+	server.TLS = reloader.TLSConfig(tls.VersionTLS12, false)
+	server.StartTLS()
+	return server, pki
+}
+
+func tstTlsClient(pki *certtest.PKI, certificates ...tls.Certificate) *http.Client {
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pki.CaPool, Certificates: certificates}}}
+}
+
+func TestTls_ClientCertificate_ShouldIdentifyCaller(t *testing.T) {
+	docs.Given("Given a running application serving https with optional client certificates")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	server, pki := tstSetupTlsServer(t)
+	defer server.Close()
+	clientCert, err := pki.ClientCertificate(pkix.Name{CommonName: "campaign-service", Organization: []string{"Example"}})
+	require.Nil(t, err)
+
+	docs.When("When a service sends an email using its client certificate instead of a token")
+	response, err := tstTlsClient(pki, clientCert).Post(server.URL+"/api/rest/v1/sendmail", "application/json", strings.NewReader(tstRenderJson(tstValidEmailDto())))
+	require.Nil(t, err)
+	webResponse, err := tstWebResponseFromResponse(response)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, webResponse.status)
+	result := email.EmailResultDto{}
+	require.Nil(t, tstParseJson(webResponse.body, &result))
+
+	docs.Then("Then the certificate subject is recorded as the caller")
+	auditResponse, err := tstPerformGet("/api/rest/v1/audit?email_id="+result.Id, tstValidAdminToken())
+	require.Nil(t, err)
+	entries := audit.AuditEntryListDto{}
+	require.Nil(t, tstParseJson(auditResponse.body, &entries))
+	require.NotEmpty(t, entries.Entries)
+	require.Equal(t, "CN=campaign-service,O=Example", entries.Entries[0].Subject)
+
+	docs.Then("And the certificate alone does not grant the admin role")
+	response, err = tstTlsClient(pki, clientCert).Get(server.URL + "/api/rest/v1/audit")
+	require.Nil(t, err)
+	require.Nil(t, response.Body.Close())
+	require.Equal(t, http.StatusForbidden, response.StatusCode)
+}
+
+func TestTls_NoClientCertificate_ShouldBeAnonymous(t *testing.T) {
+	docs.Given("Given a running application serving https with optional client certificates")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	server, pki := tstSetupTlsServer(t)
+	defer server.Close()
+
+	docs.When("When a caller without a client certificate or token accesses an admin endpoint")
+	response, err := tstTlsClient(pki).Get(server.URL + "/api/rest/v1/audit")
+
+	docs.Then("Then the connection is accepted, but the request is denied")
+	require.Nil(t, err)
+	require.Nil(t, response.Body.Close())
+	require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
@@ -1,10 +1,13 @@
 package acceptance
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/go-http-utils/headers"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 // placing these here because they are package global
@@ -14,6 +17,8 @@ type tstWebResponse struct {
 	body        string
 	contentType string
 	location    string
+	requestId   string
+	header      http.Header
 }
 
 func tstWebResponseFromResponse(response *http.Response) (tstWebResponse, error) {
@@ -26,6 +31,7 @@ func tstWebResponseFromResponse(response *http.Response) (tstWebResponse, error)
 	if val, ok := response.Header[headers.Location]; ok {
 		loc = val[0]
 	}
+	requestId := response.Header.Get("X-Request-Id")
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
 		return tstWebResponse{}, err
@@ -39,23 +45,68 @@ func tstWebResponseFromResponse(response *http.Response) (tstWebResponse, error)
 		body:        string(body),
 		contentType: ct,
 		location:    loc,
+		requestId:   requestId,
+		header:      response.Header,
 	}, nil
 }
 
 func tstPerformGet(relativeUrlWithLeadingSlash string, bearerToken string) (tstWebResponse, error) {
+	return tstPerform(http.MethodGet, relativeUrlWithLeadingSlash, nil, bearerToken)
+}
+
+func tstPerformPost(relativeUrlWithLeadingSlash string, requestBody string, bearerToken string) (tstWebResponse, error) {
+	return tstPerform(http.MethodPost, relativeUrlWithLeadingSlash, strings.NewReader(requestBody), bearerToken)
+}
+
+func tstPerformPostRaw(relativeUrlWithLeadingSlash string, requestBody string, contentType string, bearerToken string) (tstWebResponse, error) {
+	return tstPerformWithContentType(http.MethodPost, relativeUrlWithLeadingSlash, strings.NewReader(requestBody), contentType, bearerToken)
+}
+
+func tstPerformPut(relativeUrlWithLeadingSlash string, requestBody string, bearerToken string) (tstWebResponse, error) {
+	return tstPerform(http.MethodPut, relativeUrlWithLeadingSlash, strings.NewReader(requestBody), bearerToken)
+}
+
+func tstPerformDelete(relativeUrlWithLeadingSlash string, bearerToken string) (tstWebResponse, error) {
+	return tstPerform(http.MethodDelete, relativeUrlWithLeadingSlash, nil, bearerToken)
+}
+
+func tstPerform(method string, relativeUrlWithLeadingSlash string, requestBody io.Reader, bearerToken string) (tstWebResponse, error) {
+	return tstPerformWithContentType(method, relativeUrlWithLeadingSlash, requestBody, "application/json", bearerToken)
+}
+
+func tstPerformWithContentType(method string, relativeUrlWithLeadingSlash string, requestBody io.Reader, contentType string, bearerToken string) (tstWebResponse, error) {
+	return tstPerformWithHeaders(method, relativeUrlWithLeadingSlash, requestBody, contentType, bearerToken, nil)
+}
+
+func tstPerformWithHeaders(method string, relativeUrlWithLeadingSlash string, requestBody io.Reader, contentType string, bearerToken string, additionalHeaders map[string]string) (tstWebResponse, error) {
 	if ts == nil {
 		return tstWebResponse{}, errors.New("test web server was not initialized")
 	}
-	request, err := http.NewRequest(http.MethodGet, ts.URL+relativeUrlWithLeadingSlash, nil)
+	request, err := http.NewRequest(method, ts.URL+relativeUrlWithLeadingSlash, requestBody)
 	if err != nil {
 		return tstWebResponse{}, err
 	}
+	if requestBody != nil {
+		request.Header.Set(headers.ContentType, contentType)
+	}
 	if bearerToken != "" {
 		request.Header.Set(headers.Authorization, "Bearer "+bearerToken)
 	}
+	for name, value := range additionalHeaders {
+		request.Header.Set(name, value)
+	}
 	response, err := http.DefaultClient.Do(request)
 	if err != nil {
 		return tstWebResponse{}, err
 	}
 	return tstWebResponseFromResponse(response)
 }
+
+func tstRenderJson(v interface{}) string {
+	representationBytes, _ := json.Marshal(v)
+	return string(representationBytes)
+}
+
+func tstParseJson(body string, dto interface{}) error {
+	return json.Unmarshal([]byte(body), dto)
+}
@@ -0,0 +1,170 @@
+package acceptance
+
+import (
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/api/v1/events"
+	"github.com/StephanHCB/go-mailer-service/api/v1/webhook"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/internal/service/webhooksrv"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const tstWebhookSecret = "acceptance-test-secret"
+
+type tstWebhookReceiver struct {
+	mu       sync.Mutex
+	received []events.CloudEventDto
+}
+
+func (r *tstWebhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	if webhooksrv.Sign(tstWebhookSecret, req.Header.Get(webhooksrv.TimestampHeader), body) != req.Header.Get(webhooksrv.SignatureHeader) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	dto := events.CloudEventDto{}
+	_ = json.Unmarshal(body, &dto)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received = append(r.received, dto)
+}
+
+func (r *tstWebhookReceiver) receivedTypes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := []string{}
+	for _, dto := range r.received {
+		result = append(result, dto.Type)
+	}
+	return result
+}
+
+func TestWebhooks_SubscribeAndReceiveEvents(t *testing.T) {
+	docs.Given("Given a running application and a webhook receiver")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+	receiver := &tstWebhookReceiver{}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+
+	docs.When("When an admin subscribes the receiver to sent events")
+	subscription := webhook.WebhookSubscriptionDto{Url: receiverServer.URL, Secret: tstWebhookSecret, EventTypes: []string{"sent"}}
+	response, err := tstPerformPost("/api/rest/v1/webhooks", tstRenderJson(subscription), tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, response.status)
+	created := webhook.WebhookSubscriptionDto{}
+	require.Nil(t, tstParseJson(response.body, &created))
+	require.Equal(t, "/api/rest/v1/webhooks/"+created.Id, response.location)
+	require.Empty(t, created.Secret)
+
+	docs.When("And an email is sent")
+	response, err = tstPerformPost("/api/rest/v1/sendmail", tstRenderJson(tstValidEmailDto()), tstUnauthenticated())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+
+	docs.Then("Then the receiver gets a signed sent event")
+	for i := 0; i < 100 && len(receiver.receivedTypes()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, []string{"com.example.mailer.email.sent.v1"}, receiver.receivedTypes())
+
+	docs.Then("And the delivery is logged")
+	deliveries := webhook.WebhookDeliveryListDto{}
+	// the delivery is logged only after the receiver has responded
+	for i := 0; i < 100 && len(deliveries.Deliveries) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		response, err = tstPerformGet("/api/rest/v1/webhooks/"+created.Id+"/deliveries", tstValidAdminToken())
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, response.status)
+		require.Nil(t, tstParseJson(response.body, &deliveries))
+	}
+	require.Len(t, deliveries.Deliveries, 1)
+	require.True(t, deliveries.Deliveries[0].Success)
+}
+
+func TestWebhooks_Crud(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin creates, updates, lists and deletes a subscription")
+	subscription := webhook.WebhookSubscriptionDto{Url: "https://example.com/hook", Secret: tstWebhookSecret}
+	response, err := tstPerformPost("/api/rest/v1/webhooks", tstRenderJson(subscription), tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, response.status)
+	created := webhook.WebhookSubscriptionDto{}
+	require.Nil(t, tstParseJson(response.body, &created))
+
+	update := webhook.WebhookSubscriptionDto{Url: "https://example.com/other", EventTypes: []string{"bounced"}}
+	response, err = tstPerformPut("/api/rest/v1/webhooks/"+created.Id, tstRenderJson(update), tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+
+	response, err = tstPerformGet("/api/rest/v1/webhooks", tstValidAdminToken())
+	require.Nil(t, err)
+	list := webhook.WebhookSubscriptionListDto{}
+	require.Nil(t, tstParseJson(response.body, &list))
+
+	docs.Then("Then every step reflects the changes")
+	require.Len(t, list.Subscriptions, 1)
+	require.Equal(t, "https://example.com/other", list.Subscriptions[0].Url)
+	require.Equal(t, []string{"bounced"}, list.Subscriptions[0].EventTypes)
+
+	response, err = tstPerformDelete("/api/rest/v1/webhooks/"+created.Id, tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNoContent, response.status)
+
+	response, err = tstPerformGet("/api/rest/v1/webhooks/"+created.Id, tstValidAdminToken())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.status)
+}
+
+func TestWebhooks_InvalidSubscription_ShouldReject(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an admin tries to subscribe with an unknown event type")
+	subscription := webhook.WebhookSubscriptionDto{Url: "https://example.com/hook", Secret: tstWebhookSecret, EventTypes: []string{"opened"}}
+	response, err := tstPerformPost("/api/rest/v1/webhooks", tstRenderJson(subscription), tstValidAdminToken())
+
+	docs.Then("Then the request is rejected")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusBadRequest, response.status)
+}
+
+func TestWebhooks_Security(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When webhooks are listed anonymously or without the admin role")
+	anonymous, err1 := tstPerformGet("/api/rest/v1/webhooks", tstUnauthenticated())
+	user, err2 := tstPerformGet("/api/rest/v1/webhooks", tstValidUserToken())
+
+	docs.Then("Then the request is denied")
+	require.Nil(t, err1)
+	require.Equal(t, http.StatusUnauthorized, anonymous.status)
+	require.Nil(t, err2)
+	require.Equal(t, http.StatusForbidden, user.status)
+}
+
+func TestWebhooks_InvalidSubscriptionAnonymous_ShouldDenyBeforeValidating(t *testing.T) {
+	docs.Given("Given a running application")
+	tstSetup(tstValidConfigurationPath)
+	defer tstShutdown()
+
+	docs.When("When an anonymous caller tries to subscribe with an invalid body")
+	response, err := tstPerformPost("/api/rest/v1/webhooks", `{"url":17}`, tstUnauthenticated())
+
+	docs.Then("Then the request is denied without revealing the schema")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusUnauthorized, response.status)
+	require.NotContains(t, response.body, "violations")
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"github.com/StephanHCB/go-mailer-service/internal/entity"
 	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
 	"github.com/StephanHCB/go-mailer-service/web"
 	"github.com/stretchr/testify/mock"
 	"net/http/httptest"
@@ -34,7 +36,10 @@ func tstSetupConfig() {
 }
 
 func tstSetupHttpTestServer() {
-	server := web.Create()
+	server, err := web.Create()
+	if err != nil {
+		panic(err)
+	}
 	web.AddRoutes(server, &MockEmailService{})
 	ts = httptest.NewServer(server)
 }
@@ -55,3 +60,19 @@ func (s *MockEmailService) SendEmail(ctx context.Context, email *entity.Email) e
 	// TODO use mock to verify data for contract tests
 	return nil
 }
+
+func (s *MockEmailService) GetEmail(ctx context.Context, caller identity.Caller, id string) (*entity.Email, error) {
+	return nil, emailsrv.ErrNotFound
+}
+
+func (s *MockEmailService) ListEmails(ctx context.Context, cursor string, limit int) ([]*entity.Email, string, error) {
+	return []*entity.Email{}, "", nil
+}
+
+func (s *MockEmailService) CancelEmail(ctx context.Context, caller identity.Caller, id string) error {
+	return nil
+}
+
+func (s *MockEmailService) DispatchDueEmails(ctx context.Context) error {
+	return nil
+}
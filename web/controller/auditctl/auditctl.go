@@ -0,0 +1,90 @@
+package auditctl
+
+import (
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	apiaudit "github.com/StephanHCB/go-mailer-service/api/v1/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type AuditController struct {
+	l *audit.Log
+}
+
+func Create(server *gin.Engine, auditLog *audit.Log) apiaudit.AuditApi {
+	controller := &AuditController{l: auditLog}
+	controller.SetupRoutes(server)
+	return controller
+}
+
+func (c *AuditController) SetupRoutes(server *gin.Engine) {
+	server.GET("/api/rest/v1/audit", c.QueryAudit)
+	server.GET("/api/rest/v1/audit/verify", c.VerifyAudit)
+}
+
+func (c *AuditController) QueryAudit(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	filter, err := parseFilter(ginctx)
+	if err != nil {
+		log.Ctx(ginctx.Request.Context()).Warn().Err(err).Msgf("invalid audit query: %v", err)
+		errorhandlers.ErrorHandler(ginctx, apierrors.AuditQuery, []string{err.Error()})
+		return
+	}
+	entries, err := c.l.Query(filter)
+	if err != nil {
+		auditErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapEntriesToListDto(entries))
+}
+
+func (c *AuditController) VerifyAudit(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	report, err := c.l.Verify()
+	if err != nil {
+		auditErrorHandler(ginctx, err)
+		return
+	}
+	if !report.Valid {
+		log.Ctx(ginctx.Request.Context()).Error().Msgf("audit log verification failed at entry %d: %s", report.BrokenAt, report.Reason)
+	}
+	ginctx.JSON(http.StatusOK, mapReportToVerificationDto(report))
+}
+
+func parseFilter(ginctx *gin.Context) (audit.Filter, error) {
+	filter := audit.Filter{
+		EmailId:   ginctx.Query("email_id"),
+		Subject:   ginctx.Query("subject"),
+		Recipient: ginctx.Query("recipient"),
+		Outcome:   audit.Outcome(ginctx.Query("outcome")),
+		Limit:     defaultLimit,
+	}
+	if value := ginctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return filter, fmt.Errorf("limit must be a number between 1 and %d", maxLimit)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+func auditErrorHandler(ginctx *gin.Context, err error) {
+	log.Ctx(ginctx.Request.Context()).Error().Err(err).Msgf("error reading audit log: %v", err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.AuditRead, []string{})
+}
@@ -0,0 +1,37 @@
+package auditctl
+
+import (
+	apiaudit "github.com/StephanHCB/go-mailer-service/api/v1/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"time"
+)
+
+func mapEntriesToListDto(entries []audit.Entry) *apiaudit.AuditEntryListDto {
+	dto := &apiaudit.AuditEntryListDto{Entries: []apiaudit.AuditEntryDto{}}
+	for _, entry := range entries {
+		dto.Entries = append(dto.Entries, apiaudit.AuditEntryDto{
+			Sequence:   entry.Sequence,
+			Timestamp:  entry.Timestamp.Format(time.RFC3339Nano),
+			Subject:    entry.Subject,
+			Roles:      entry.Roles,
+			RequestId:  entry.RequestId,
+			EmailId:    entry.EmailId,
+			Recipients: entry.Recipients,
+			TemplateId: entry.TemplateId,
+			Outcome:    string(entry.Outcome),
+			Detail:     entry.Detail,
+			PrevHash:   entry.PrevHash,
+			Hash:       entry.Hash,
+		})
+	}
+	return dto
+}
+
+func mapReportToVerificationDto(report *audit.Report) *apiaudit.AuditVerificationDto {
+	return &apiaudit.AuditVerificationDto{
+		Entries:  report.Entries,
+		Valid:    report.Valid,
+		BrokenAt: report.BrokenAt,
+		Reason:   report.Reason,
+	}
+}
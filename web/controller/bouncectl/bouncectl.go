@@ -0,0 +1,57 @@
+package bouncectl
+
+import (
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/bounce"
+	"github.com/StephanHCB/go-mailer-service/internal/service/bouncesrv"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+type BounceController struct {
+	s bouncesrv.BounceService
+}
+
+func Create(server *gin.Engine, bounceService bouncesrv.BounceService) bounce.BounceApi {
+	controller := &BounceController{s: bounceService}
+	controller.SetupRoutes(server)
+	return controller
+}
+
+func (c *BounceController) SetupRoutes(server *gin.Engine) {
+	server.POST("/api/rest/v1/bounces", c.SubmitBounce)
+}
+
+func (c *BounceController) SubmitBounce(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	ctx := ginctx.Request.Context()
+
+	report, err := c.s.ProcessReport(ctx, ginctx.Request.Body)
+	if err != nil {
+		bounceErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapReportToResultDto(report))
+}
+
+func bounceErrorHandler(ginctx *gin.Context, err error) {
+	ctx := ginctx.Request.Context()
+	if errors.Is(err, bouncesrv.ErrNotAReport) {
+		log.Ctx(ctx).Warn().Err(err).Msgf("bounce could not be parsed: %v", err)
+		errorhandlers.ErrorHandler(ginctx, apierrors.BounceParse, []string{})
+		return
+	}
+	if errors.Is(err, bouncesrv.ErrNotCorrelated) {
+		log.Ctx(ctx).Warn().Err(err).Msgf("bounce could not be correlated: %v", err)
+		errorhandlers.ErrorHandler(ginctx, apierrors.BounceNotCorrelated, []string{})
+		return
+	}
+	log.Ctx(ctx).Error().Err(err).Msgf("error processing bounce: %v", err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.BounceProcess, []string{})
+}
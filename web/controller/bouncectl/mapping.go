@@ -0,0 +1,16 @@
+package bouncectl
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/bounce"
+	"github.com/StephanHCB/go-mailer-service/internal/service/bouncesrv"
+)
+
+func mapReportToResultDto(report *bouncesrv.Report) *bounce.BounceResultDto {
+	return &bounce.BounceResultDto{
+		EmailId:      report.EmailID,
+		Kind:         string(report.Kind),
+		Recipient:    report.Recipient,
+		Status:       report.Status,
+		FeedbackType: report.FeedbackType,
+	}
+}
@@ -1,15 +1,20 @@
 package emailctl
 
 import (
-	"encoding/json"
+	"errors"
 	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
 	"github.com/StephanHCB/go-mailer-service/api/v1/email"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
 	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/StephanHCB/go-mailer-service/web/controller/emailv2ctl"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/controller/requestbody"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/deprecation"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
-	"github.com/thanhhh/gin-requestid"
 	"net/http"
-	"time"
 )
 
 type EmailController struct {
@@ -23,7 +28,12 @@ func Create(server *gin.Engine, emailService emailsrv.EmailService) email.EmailA
 }
 
 func (c *EmailController) SetupRoutes(server *gin.Engine) {
-	server.POST("/api/rest/v1/sendmail", c.SendEmail)
+	server.POST("/api/rest/v1/sendmail",
+		deprecation.MarkDeprecated(configuration.ApiV1Deprecation(), configuration.ApiV1Sunset(), emailv2ctl.EmailsPath),
+		c.SendEmail)
+	server.DELETE("/api/rest/v1/scheduled/:id",
+		deprecation.MarkDeprecated(configuration.ApiV1Deprecation(), configuration.ApiV1Sunset(), emailv2ctl.EmailsPath+"/:id"),
+		c.CancelEmail)
 }
 
 func (c *EmailController) SendEmail(ginctx *gin.Context) {
@@ -37,9 +47,9 @@ func (c *EmailController) SendEmail(ginctx *gin.Context) {
 
 	ctx := ginctx.Request.Context()
 	email := c.s.NewInstance(ctx)
-	err = mapDtoToEmail(dto, email)
+	err = dto.MapToEmail(email)
 	if err != nil {
-		emailParseErrorHandler(ginctx, err)
+		emailParseErrorHandler(ginctx, requestbody.FieldError("send_at", "must be an RFC 3339 timestamp"))
 		return
 	}
 
@@ -48,13 +58,28 @@ func (c *EmailController) SendEmail(ginctx *gin.Context) {
 		emailSendErrorHandler(ginctx, err)
 		return
 	}
-	ginctx.Writer.WriteHeader(http.StatusOK)
+	ginctx.JSON(http.StatusOK, mapEmailToResultDto(email))
+}
+
+func (c *EmailController) CancelEmail(ginctx *gin.Context) {
+	ctx := ginctx.Request.Context()
+	if err := authentication.CheckUserIsLoggedIn(ctx); err != nil {
+		errorhandlers.UnauthorizedErrorHandler(ginctx, err)
+		return
+	}
+
+	id := ginctx.Param("id")
+	err := c.s.CancelEmail(ctx, identity.CallerOf(ctx), id)
+	if err != nil {
+		emailCancelErrorHandler(ginctx, id, err)
+		return
+	}
+	ginctx.Status(http.StatusNoContent)
 }
 
 func parseBodyToEmailDto(ginctx *gin.Context) (*email.EmailDto, error) {
-	decoder := json.NewDecoder(ginctx.Request.Body)
 	dto := &email.EmailDto{}
-	err := decoder.Decode(dto)
+	err := requestbody.DecodeJson(ginctx, dto, configuration.ServerRequestStrictJson())
 	if err != nil {
 		dto = &email.EmailDto{}
 	}
@@ -62,21 +87,34 @@ func parseBodyToEmailDto(ginctx *gin.Context) (*email.EmailDto, error) {
 }
 
 func emailParseErrorHandler(ginctx *gin.Context, err error) {
-	// TODO better way to deal with request related errors? Also how will it get requestId?
-	ctx := ginctx.Request.Context()
-	log.Ctx(ctx).Warn().Err(err).Msgf("email body could not be parsed: %v", err)
-	errorHandler(ginctx, "email.parse.error", http.StatusBadRequest, []string{})
+	requestbody.ParseErrorHandler(ginctx, apierrors.EmailParse, err)
 }
 
 func emailSendErrorHandler(ginctx *gin.Context, err error) {
 	ctx := ginctx.Request.Context()
+	var validationErr *emailsrv.ValidationError
+	if errors.As(err, &validationErr) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.EmailValidation, []string{validationErr.Reason})
+		return
+	}
 	log.Ctx(ctx).Warn().Err(err).Msgf("error sending email: %v", err)
-	errorHandler(ginctx, "email.send.error", http.StatusInternalServerError, []string{})
+	errorhandlers.ErrorHandler(ginctx, apierrors.EmailSend, []string{})
 }
 
-func errorHandler(ginctx *gin.Context, msg string, status int, details []string) {
-	timestamp := time.Now().Format(time.RFC3339)
-	requestId := requestid.GetReqID(ginctx)
-	response := apierrors.ErrorDto{Message: msg, Timestamp: timestamp, Details: details, RequestId: requestId}
-	ginctx.JSON(status, response)
+func emailCancelErrorHandler(ginctx *gin.Context, id string, err error) {
+	ctx := ginctx.Request.Context()
+	if errors.Is(err, emailsrv.ErrNotFound) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.EmailNotFound, []string{})
+		return
+	}
+	if errors.Is(err, emailsrv.ErrNotPending) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.EmailNotPending, []string{})
+		return
+	}
+	if errors.Is(err, emailsrv.ErrForbidden) {
+		errorhandlers.ForbiddenErrorHandler(ginctx, err)
+		return
+	}
+	log.Ctx(ctx).Warn().Err(err).Msgf("error cancelling email %s: %v", id, err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.EmailCancel, []string{})
 }
@@ -5,9 +5,9 @@ import (
 	"github.com/StephanHCB/go-mailer-service/internal/entity"
 )
 
-func mapDtoToEmail(dto *email.EmailDto, c *entity.Email) error {
-	c.Subject = dto.Subject
-	c.Body	= dto.Body
-	c.ToAddress = dto.ToAddress
-	return nil
+func mapEmailToResultDto(c *entity.Email) *email.EmailResultDto {
+	return &email.EmailResultDto{
+		Id:     c.ID,
+		Status: string(c.Status),
+	}
 }
@@ -0,0 +1,177 @@
+package emailv2ctl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/api/v2/email"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/controller/requestbody"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// EmailsPath is where the email resources live, also the successor of the v1 sendmail endpoint.
+const EmailsPath = "/api/rest/v2/emails"
+
+type EmailController struct {
+	s emailsrv.EmailService
+}
+
+func Create(server *gin.Engine, emailService emailsrv.EmailService) email.EmailApi {
+	controller := &EmailController{s: emailService}
+	controller.SetupRoutes(server)
+	return controller
+}
+
+func (c *EmailController) SetupRoutes(server *gin.Engine) {
+	server.POST(EmailsPath, c.CreateEmail)
+	server.GET(EmailsPath+"/:id", c.GetEmail)
+	server.GET(EmailsPath, c.ListEmails)
+	server.DELETE(EmailsPath+"/:id", c.DeleteEmail)
+}
+
+func (c *EmailController) CreateEmail(ginctx *gin.Context) {
+	dto := &email.NewEmailDto{}
+	if err := requestbody.DecodeJson(ginctx, dto, configuration.ServerRequestStrictJson()); err != nil {
+		requestbody.ParseErrorHandler(ginctx, apierrors.EmailParse, err)
+		return
+	}
+
+	ctx := ginctx.Request.Context()
+	mail := c.s.NewInstance(ctx)
+	if err := mapDtoToEmail(dto, mail); err != nil {
+		requestbody.ParseErrorHandler(ginctx, apierrors.EmailParse, err)
+		return
+	}
+
+	if err := c.s.SendEmail(ctx, mail); err != nil {
+		emailSendErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.Header("Location", EmailsPath+"/"+mail.ID)
+	ginctx.JSON(http.StatusCreated, mapEmailToResourceDto(mail))
+}
+
+func (c *EmailController) GetEmail(ginctx *gin.Context) {
+	ctx := ginctx.Request.Context()
+	if err := authentication.CheckUserIsLoggedIn(ctx); err != nil {
+		errorhandlers.UnauthorizedErrorHandler(ginctx, err)
+		return
+	}
+
+	id := ginctx.Param("id")
+	mail, err := c.s.GetEmail(ctx, identity.CallerOf(ctx), id)
+	if err != nil {
+		emailReadErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapEmailToResourceDto(mail))
+}
+
+func (c *EmailController) ListEmails(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	ctx := ginctx.Request.Context()
+
+	limit, err := parseLimit(ginctx)
+	if err != nil {
+		emailQueryErrorHandler(ginctx, err)
+		return
+	}
+	emails, next, err := c.s.ListEmails(ctx, ginctx.Query("cursor"), limit)
+	if err != nil {
+		emailReadErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapEmailsToPageDto(emails, next))
+}
+
+func (c *EmailController) DeleteEmail(ginctx *gin.Context) {
+	ctx := ginctx.Request.Context()
+	if err := authentication.CheckUserIsLoggedIn(ctx); err != nil {
+		errorhandlers.UnauthorizedErrorHandler(ginctx, err)
+		return
+	}
+
+	id := ginctx.Param("id")
+	if err := c.s.CancelEmail(ctx, identity.CallerOf(ctx), id); err != nil {
+		emailCancelErrorHandler(ginctx, id, err)
+		return
+	}
+	ginctx.Status(http.StatusNoContent)
+}
+
+func parseLimit(ginctx *gin.Context) (int, error) {
+	value := ginctx.Query("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf("limit must be a number between 1 and %d", maxLimit)
+	}
+	return limit, nil
+}
+
+func emailSendErrorHandler(ginctx *gin.Context, err error) {
+	ctx := ginctx.Request.Context()
+	var validationErr *emailsrv.ValidationError
+	if errors.As(err, &validationErr) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.EmailValidation, []string{validationErr.Reason})
+		return
+	}
+	log.Ctx(ctx).Warn().Err(err).Msgf("error sending email: %v", err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.EmailSend, []string{})
+}
+
+func emailQueryErrorHandler(ginctx *gin.Context, err error) {
+	log.Ctx(ginctx.Request.Context()).Warn().Err(err).Msgf("invalid email query: %v", err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.EmailQuery, []string{err.Error()})
+}
+
+func emailReadErrorHandler(ginctx *gin.Context, err error) {
+	if errors.Is(err, emailsrv.ErrNotFound) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.EmailNotFound, []string{})
+		return
+	}
+	if errors.Is(err, emailsrv.ErrForbidden) {
+		errorhandlers.ForbiddenErrorHandler(ginctx, err)
+		return
+	}
+	if errors.Is(err, emailsrv.ErrInvalidCursor) {
+		emailQueryErrorHandler(ginctx, fmt.Errorf("cursor must be the next_cursor of a previous page: %w", err))
+		return
+	}
+	log.Ctx(ginctx.Request.Context()).Error().Err(err).Msgf("error reading emails: %v", err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.EmailRead, []string{})
+}
+
+func emailCancelErrorHandler(ginctx *gin.Context, id string, err error) {
+	if errors.Is(err, emailsrv.ErrNotFound) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.EmailNotFound, []string{})
+		return
+	}
+	if errors.Is(err, emailsrv.ErrNotPending) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.EmailNotPending, []string{})
+		return
+	}
+	if errors.Is(err, emailsrv.ErrForbidden) {
+		errorhandlers.ForbiddenErrorHandler(ginctx, err)
+		return
+	}
+	log.Ctx(ginctx.Request.Context()).Warn().Err(err).Msgf("error cancelling email %s: %v", id, err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.EmailCancel, []string{})
+}
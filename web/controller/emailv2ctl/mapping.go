@@ -0,0 +1,54 @@
+package emailv2ctl
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v2/email"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"github.com/StephanHCB/go-mailer-service/web/controller/requestbody"
+	"time"
+)
+
+func mapDtoToEmail(dto *email.NewEmailDto, e *entity.Email) error {
+	e.ToAddress = dto.ToAddress
+	e.Subject = dto.Subject
+	e.Body = dto.Body
+	if dto.SendAt != "" {
+		sendAt, err := time.Parse(time.RFC3339, dto.SendAt)
+		if err != nil {
+			return requestbody.FieldError("send_at", "must be an RFC 3339 timestamp")
+		}
+		e.SendAt = sendAt
+	}
+	return nil
+}
+
+func mapEmailToResourceDto(e *entity.Email) email.EmailResourceDto {
+	return email.EmailResourceDto{
+		Id:              e.ID,
+		ToAddress:       e.ToAddress,
+		Subject:         e.Subject,
+		Body:            e.Body,
+		Status:          string(e.Status),
+		StatusDetail:    e.StatusDetail,
+		Attempts:        e.Attempts,
+		CreatedAt:       formatTimestamp(e.CreatedAt),
+		SendAt:          formatTimestamp(e.SendAt),
+		SentAt:          formatTimestamp(e.SentAt),
+		ContentPurgedAt: formatTimestamp(e.ContentPurgedAt),
+	}
+}
+
+func mapEmailsToPageDto(emails []*entity.Email, nextCursor string) email.EmailPageDto {
+	items := make([]email.EmailResourceDto, 0, len(emails))
+	for _, e := range emails {
+		items = append(items, mapEmailToResourceDto(e))
+	}
+	return email.EmailPageDto{Items: items, NextCursor: nextCursor}
+}
+
+// blank for the zero time, so the field is left out
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,53 @@
+package errorhandlers
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// shared error responses for all controllers
+
+func UnauthorizedErrorHandler(ginctx *gin.Context, err error) {
+	ctx := ginctx.Request.Context()
+	log.Ctx(ctx).Warn().Err(err).Msgf("unauthorized: %v", err)
+	ErrorHandler(ginctx, apierrors.AuthUnauthorized, []string{})
+}
+
+func ForbiddenErrorHandler(ginctx *gin.Context, err error) {
+	ctx := ginctx.Request.Context()
+	log.Ctx(ctx).Warn().Err(err).Msgf("forbidden: %v", err)
+	ErrorHandler(ginctx, apierrors.AuthForbidden, []string{})
+}
+
+func PayloadTooLargeErrorHandler(ginctx *gin.Context, err error) {
+	ctx := ginctx.Request.Context()
+	log.Ctx(ctx).Warn().Err(err).Msgf("request body too large: %v", err)
+	ErrorHandler(ginctx, apierrors.RequestTooLarge, []string{})
+}
+
+// ErrorHandler responds with the status of code, as an ErrorDto or, if the client prefers it, as an RFC 7807 problem.
+func ErrorHandler(ginctx *gin.Context, code apierrors.ErrorCode, details []string) {
+	timestamp := time.Now().Format(time.RFC3339)
+	requestId := tracing.RequestId(ginctx.Request.Context())
+	if ginctx.NegotiateFormat(gin.MIMEJSON, apierrors.ProblemContentType) == apierrors.ProblemContentType {
+		problem := apierrors.ProblemDto{
+			Type:      apierrors.ProblemTypePrefix + code.Code,
+			Title:     code.Title,
+			Status:    code.Status,
+			Detail:    strings.Join(details, "; "),
+			Instance:  ginctx.Request.URL.Path,
+			RequestId: requestId,
+			Timestamp: timestamp,
+		}
+		// gin keeps a content type that is already set
+		ginctx.Header("Content-Type", apierrors.ProblemContentType)
+		ginctx.JSON(code.Status, problem)
+		return
+	}
+	response := apierrors.ErrorDto{Message: code.Code, Timestamp: timestamp, Details: details, RequestId: requestId}
+	ginctx.JSON(code.Status, response)
+}
@@ -1,16 +1,26 @@
 package healthctl
 
 import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/health"
+	healthrepo "github.com/StephanHCB/go-mailer-service/internal/repository/health"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
-func Create(server *gin.Engine) {
-	SetupRoutes(server)
+type HealthController struct {
 }
 
-func SetupRoutes(server *gin.Engine) {
+func Create(server *gin.Engine) health.HealthApi {
+	controller := &HealthController{}
+	controller.SetupRoutes(server)
+	return controller
+}
+
+func (c *HealthController) SetupRoutes(server *gin.Engine) {
+	// kept for existing monitoring, same as live
 	server.GET("/health", Health)
+	server.GET("/health/live", c.Live)
+	server.GET("/health/ready", c.Ready)
 }
 
 // actual endpoint implementation
@@ -18,3 +28,16 @@ func SetupRoutes(server *gin.Engine) {
 func Health(ctx *gin.Context) {
 	ctx.Writer.WriteHeader(http.StatusOK)
 }
+
+func (c *HealthController) Live(ginctx *gin.Context) {
+	ginctx.JSON(http.StatusOK, &health.HealthReportDto{Status: string(healthrepo.StatusUp)})
+}
+
+func (c *HealthController) Ready(ginctx *gin.Context) {
+	report := healthrepo.CheckAll(ginctx.Request.Context())
+	status := http.StatusOK
+	if report.Status != healthrepo.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	ginctx.JSON(status, mapReportToDto(report))
+}
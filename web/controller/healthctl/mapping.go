@@ -0,0 +1,22 @@
+package healthctl
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/health"
+	healthrepo "github.com/StephanHCB/go-mailer-service/internal/repository/health"
+)
+
+func mapReportToDto(report *healthrepo.Report) *health.HealthReportDto {
+	dto := &health.HealthReportDto{
+		Status:     string(report.Status),
+		Components: []health.ComponentHealthDto{},
+	}
+	for _, result := range report.Results {
+		dto.Components = append(dto.Components, health.ComponentHealthDto{
+			Name:       result.Name,
+			Status:     string(result.Status),
+			Error:      result.Error,
+			DurationMs: result.Duration.Milliseconds(),
+		})
+	}
+	return dto
+}
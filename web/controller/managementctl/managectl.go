@@ -1 +1,89 @@
 package managementctl
+
+import (
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/management"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/buildinfo"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/controller/requestbody"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ManagementController struct {
+}
+
+func Create(server *gin.Engine) management.ManagementApi {
+	controller := &ManagementController{}
+	controller.SetupRoutes(server)
+	return controller
+}
+
+func (c *ManagementController) SetupRoutes(server *gin.Engine) {
+	server.GET("/management/info", c.Info)
+	server.GET("/management/config", c.Config)
+	server.GET("/management/loggers", c.Loggers)
+	server.PUT("/management/loggers/:name", c.UpdateLogger)
+}
+
+func (c *ManagementController) Info(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	ginctx.JSON(http.StatusOK, &management.InfoDto{
+		ServiceName: configuration.ServiceName(),
+		Version:     buildinfo.Version,
+		GitCommit:   buildinfo.GitCommit,
+		BuildTime:   buildinfo.BuildTime,
+		GoVersion:   buildinfo.GoVersion(),
+		Profiles:    configuration.ActiveProfiles(),
+	})
+}
+
+func (c *ManagementController) Config(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapEffectiveValuesToDto(configuration.EffectiveValues()))
+}
+
+func (c *ManagementController) Loggers(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	ginctx.JSON(http.StatusOK, &management.LoggersDto{
+		Levels:  logging.AvailableLevels(),
+		Loggers: mapLoggerLevelsToDto(logging.Loggers()),
+	})
+}
+
+func (c *ManagementController) UpdateLogger(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+
+	name := ginctx.Param("name")
+	dto := &management.LoggerDto{}
+	if err := requestbody.DecodeJson(ginctx, dto, configuration.ServerRequestStrictJson()); err != nil {
+		requestbody.ParseErrorHandler(ginctx, apierrors.LoggerParse, err)
+		return
+	}
+	if err := logging.SetLoggerLevel(name, dto.Level); err != nil {
+		if errors.Is(err, logging.ErrUnknownLogger) {
+			errorhandlers.ErrorHandler(ginctx, apierrors.LoggerNotFound, []string{})
+			return
+		}
+		errorhandlers.ErrorHandler(ginctx, apierrors.LoggerValidation, []string{err.Error()})
+		return
+	}
+	for _, logger := range mapLoggerLevelsToDto(logging.Loggers()) {
+		if logger.Name == name {
+			ginctx.JSON(http.StatusOK, &logger)
+			return
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package managementctl
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/management"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+)
+
+func mapEffectiveValuesToDto(values []configuration.EffectiveValue) *management.ConfigDto {
+	dto := &management.ConfigDto{Items: []management.ConfigItemDto{}}
+	for _, value := range values {
+		dto.Items = append(dto.Items, management.ConfigItemDto{
+			Key:         value.Key,
+			Value:       value.Value,
+			Description: value.Description,
+		})
+	}
+	return dto
+}
+
+func mapLoggerLevelsToDto(levels []logging.LoggerLevel) []management.LoggerDto {
+	result := []management.LoggerDto{}
+	for _, level := range levels {
+		result = append(result, management.LoggerDto{Name: level.Name, Level: level.Level})
+	}
+	return result
+}
@@ -0,0 +1,13 @@
+package metricsctl
+
+import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/metricspush"
+	"github.com/gin-gonic/gin"
+)
+
+// Create offers /metrics for prometheus to scrape, but only if metrics.mode is pull or both.
+func Create(server *gin.Engine) {
+	if handler := metricspush.ScrapeHandler(); handler != nil {
+		server.GET("/metrics", gin.WrapH(handler))
+	}
+}
@@ -0,0 +1,127 @@
+package requestbody
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/bodylimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ParseError is returned when a request body cannot be decoded.
+//
+// Details are meant for the caller and name the offending field where possible, as "field: problem".
+type ParseError struct {
+	Details []string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %v", strings.Join(e.Details, ", "), e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError reports a field that decoded fine but has a value that cannot be used.
+func FieldError(field string, problem string) error {
+	return &ParseError{Details: []string{field + ": " + problem}, Err: fmt.Errorf("invalid value for %s", field)}
+}
+
+// DecodeJson decodes exactly one json object from the request body into dto.
+//
+// Anything after the object is rejected. If strict is set, so are fields that dto does not have.
+func DecodeJson(ginctx *gin.Context, dto interface{}, strict bool) error {
+	decoder := json.NewDecoder(ginctx.Request.Body)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(dto); err != nil {
+		return &ParseError{Details: []string{describe(err)}, Err: err}
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("unexpected data after the json object")
+		}
+		return &ParseError{Details: []string{"body: must contain a single json object and nothing after it"}, Err: err}
+	}
+	return nil
+}
+
+// ParseErrorHandler responds with 413 if the body was too large, otherwise with code and the details of err.
+func ParseErrorHandler(ginctx *gin.Context, code apierrors.ErrorCode, err error) {
+	if errors.Is(err, bodylimit.ErrBodyTooLarge) {
+		errorhandlers.PayloadTooLargeErrorHandler(ginctx, err)
+		return
+	}
+	ctx := ginctx.Request.Context()
+	log.Ctx(ctx).Warn().Err(err).Msgf("request body could not be parsed: %v", err)
+	details := []string{}
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		details = parseErr.Details
+	}
+	errorhandlers.ErrorHandler(ginctx, code, details)
+}
+
+func describe(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return "body: must not be empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "body: ends in the middle of the json document"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("body: invalid json at offset %d: %s", syntaxErr.Offset, strings.TrimPrefix(syntaxErr.Error(), "json: "))
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Sprintf("body: must be %s, not %s", jsonType(typeErr.Type), jsonValue(typeErr.Value))
+		}
+		return fmt.Sprintf("%s: must be %s, not %s", typeErr.Field, jsonType(typeErr.Type), jsonValue(typeErr.Value))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for this one
+		return strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`) + ": is not a known field"
+	default:
+		return "body: " + err.Error()
+	}
+}
+
+// jsonType names a go type the way a caller sees it in the json document
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// encoding/json reports values as bool, string, number, number 1.5, array or object
+func jsonValue(value string) string {
+	switch {
+	case value == "bool":
+		return "a boolean"
+	case strings.HasPrefix(value, "number"):
+		return "a number"
+	case value == "array" || value == "object":
+		return "an " + value
+	default:
+		return "a " + value
+	}
+}
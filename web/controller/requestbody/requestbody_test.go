@@ -0,0 +1,73 @@
+package requestbody
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type tstDto struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags"`
+}
+
+func tstDecode(body string, strict bool) (*tstDto, error) {
+	ginctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginctx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	dto := &tstDto{}
+	err := DecodeJson(ginctx, dto, strict)
+	return dto, err
+}
+
+func tstRequireDetails(t *testing.T, err error, expected ...string) {
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr), "expected a ParseError, got %v", err)
+	require.Equal(t, expected, parseErr.Details)
+}
+
+func TestDecodeJson_Valid(t *testing.T) {
+	dto, err := tstDecode(" {\"name\":\"x\",\"count\":2,\"unknown\":true}\n ", false)
+	require.Nil(t, err)
+	require.Equal(t, "x", dto.Name)
+	require.Equal(t, 2, dto.Count)
+}
+
+func TestDecodeJson_Strict_ShouldRejectUnknownFields(t *testing.T) {
+	_, err := tstDecode(`{"name":"x","colour":"red"}`, true)
+	tstRequireDetails(t, err, "colour: is not a known field")
+}
+
+func TestDecodeJson_ShouldRejectTrailingData(t *testing.T) {
+	_, err := tstDecode(`{"name":"x"} {"name":"y"}`, false)
+	tstRequireDetails(t, err, "body: must contain a single json object and nothing after it")
+
+	_, err = tstDecode(`{"name":"x"}garbage`, false)
+	tstRequireDetails(t, err, "body: must contain a single json object and nothing after it")
+}
+
+func TestDecodeJson_ShouldNameFieldsOfWrongType(t *testing.T) {
+	_, err := tstDecode(`{"count":"two"}`, false)
+	tstRequireDetails(t, err, "count: must be a number, not a string")
+
+	_, err = tstDecode(`{"tags":{"a":1}}`, false)
+	tstRequireDetails(t, err, "tags: must be an array, not an object")
+
+	_, err = tstDecode(`[1,2]`, false)
+	tstRequireDetails(t, err, "body: must be an object, not an array")
+}
+
+func TestDecodeJson_ShouldDescribeBrokenDocuments(t *testing.T) {
+	_, err := tstDecode(``, false)
+	tstRequireDetails(t, err, "body: must not be empty")
+
+	_, err = tstDecode(`{"name":"x"`, false)
+	tstRequireDetails(t, err, "body: ends in the middle of the json document")
+
+	_, err = tstDecode(`{"name":x}`, false)
+	tstRequireDetails(t, err, "body: invalid json at offset 9: invalid character 'x' looking for beginning of value")
+}
@@ -0,0 +1,20 @@
+package subjectctl
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/subject"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/service/retentionsrv"
+)
+
+func mapReportToDto(report *retentionsrv.ErasureReport) *subject.ErasureReportDto {
+	auditRecipients := audit.RecipientsClear
+	if report.AuditRecipientsHashed {
+		auditRecipients = audit.RecipientsHashed
+	}
+	return &subject.ErasureReportDto{
+		DeletedEmails:        report.EmailIDs,
+		SuppressionDeleted:   report.SuppressionDeleted,
+		AuditEntriesRetained: report.AuditEntriesRetained,
+		AuditRecipients:      auditRecipients,
+	}
+}
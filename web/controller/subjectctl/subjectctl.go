@@ -0,0 +1,52 @@
+package subjectctl
+
+import (
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/subject"
+	"github.com/StephanHCB/go-mailer-service/internal/service/retentionsrv"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+type SubjectController struct {
+	s retentionsrv.RetentionService
+}
+
+func Create(server *gin.Engine, retentionService retentionsrv.RetentionService) subject.SubjectApi {
+	controller := &SubjectController{s: retentionService}
+	controller.SetupRoutes(server)
+	return controller
+}
+
+func (c *SubjectController) SetupRoutes(server *gin.Engine) {
+	server.DELETE("/api/rest/v1/subjects/:address", c.EraseSubject)
+}
+
+func (c *SubjectController) EraseSubject(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	ctx := ginctx.Request.Context()
+
+	report, err := c.s.EraseSubject(ctx, ginctx.Param("address"))
+	if err != nil {
+		subjectErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapReportToDto(report))
+}
+
+func subjectErrorHandler(ginctx *gin.Context, err error) {
+	ctx := ginctx.Request.Context()
+	if errors.Is(err, retentionsrv.ErrInvalidAddress) {
+		log.Ctx(ctx).Warn().Err(err).Msgf("invalid subject address: %v", err)
+		errorhandlers.ErrorHandler(ginctx, apierrors.SubjectAddressInvalid, []string{})
+		return
+	}
+	log.Ctx(ctx).Error().Err(err).Msgf("error erasing subject: %v", err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.SubjectErase, []string{})
+}
@@ -2,10 +2,75 @@ package swaggerctl
 
 import (
 	"github.com/StephanHCB/go-autumn-web-swagger-ui"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"io/fs"
+	"net"
+	"net/http"
+	"strings"
 )
 
 func SetupSwaggerRoutes(server *gin.Engine) {
 	server.StaticFS("/swagger-ui", auwebswaggerui.Assets)
-	server.StaticFile("swagger.json", "docs/swagger.json")
-}
\ No newline at end of file
+	// swagger-ui loads this one
+	server.GET("/swagger.json", SwaggerV2)
+	server.GET("/openapi.json", OpenApiV3)
+	// json schemas for the data of the cloudevents we emit
+	schemas, _ := fs.Sub(docs.Schemas, "schemas")
+	server.StaticFS("/schemas", http.FS(schemas))
+}
+
+// SwaggerV2 serves the swagger 2.0 spec, with host and scheme taken from the configuration.
+func SwaggerV2(ginctx *gin.Context) {
+	doc, err := docs.Swagger()
+	if err != nil {
+		specErrorHandler(ginctx, err)
+		return
+	}
+	scheme, host := serverLocation()
+	doc.Schemes = []string{scheme}
+	doc.Host = host
+	ginctx.JSON(http.StatusOK, doc)
+}
+
+// OpenApiV3 serves the spec converted to openapi 3.0, with the server url taken from the configuration.
+func OpenApiV3(ginctx *gin.Context) {
+	doc, err := docs.OpenApi3()
+	if err != nil {
+		specErrorHandler(ginctx, err)
+		return
+	}
+	scheme, host := serverLocation()
+	doc.Servers = openapi3.Servers{{URL: scheme + "://" + host}}
+	ginctx.JSON(http.StatusOK, doc)
+}
+
+// serverLocation returns the scheme and host:port we are listening on, localhost if no address is configured
+func serverLocation() (string, string) {
+	scheme := "http"
+	if configuration.ServerTlsEnabled() {
+		scheme = "https"
+	}
+	host, port, err := net.SplitHostPort(configuration.ServerAddress())
+	if err != nil {
+		return scheme, configuration.ServerAddress()
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return scheme, host + ":" + port
+}
+
+func specErrorHandler(ginctx *gin.Context, err error) {
+	ctx := ginctx.Request.Context()
+	log.Ctx(ctx).Error().Err(err).Msgf("embedded api spec could not be converted: %v", err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.SwaggerSpec, []string{})
+}
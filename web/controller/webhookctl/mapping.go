@@ -0,0 +1,54 @@
+package webhookctl
+
+import (
+	"github.com/StephanHCB/go-mailer-service/api/v1/webhook"
+	"github.com/StephanHCB/go-mailer-service/internal/entity"
+	"time"
+)
+
+func mapDtoToSubscription(dto *webhook.WebhookSubscriptionDto, s *entity.WebhookSubscription) {
+	s.URL = dto.Url
+	s.Secret = dto.Secret
+	s.EventTypes = make([]entity.EventType, 0, len(dto.EventTypes))
+	for _, eventType := range dto.EventTypes {
+		s.EventTypes = append(s.EventTypes, entity.EventType(eventType))
+	}
+}
+
+// never includes the secret
+func mapSubscriptionToDto(s *entity.WebhookSubscription) *webhook.WebhookSubscriptionDto {
+	eventTypes := make([]string, 0, len(s.EventTypes))
+	for _, eventType := range s.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+	return &webhook.WebhookSubscriptionDto{
+		Id:         s.ID,
+		Url:        s.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func mapSubscriptionsToListDto(subscriptions []*entity.WebhookSubscription) *webhook.WebhookSubscriptionListDto {
+	result := &webhook.WebhookSubscriptionListDto{Subscriptions: make([]webhook.WebhookSubscriptionDto, 0, len(subscriptions))}
+	for _, s := range subscriptions {
+		result.Subscriptions = append(result.Subscriptions, *mapSubscriptionToDto(s))
+	}
+	return result
+}
+
+func mapDeliveriesToListDto(deliveries []*entity.WebhookDelivery) *webhook.WebhookDeliveryListDto {
+	result := &webhook.WebhookDeliveryListDto{Deliveries: make([]webhook.WebhookDeliveryDto, 0, len(deliveries))}
+	for _, d := range deliveries {
+		result.Deliveries = append(result.Deliveries, webhook.WebhookDeliveryDto{
+			EventId:    d.EventID,
+			EventType:  string(d.EventType),
+			Attempt:    d.Attempt,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			Success:    d.Success,
+			Timestamp:  d.Timestamp.Format(time.RFC3339),
+		})
+	}
+	return result
+}
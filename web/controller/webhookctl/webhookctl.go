@@ -0,0 +1,156 @@
+package webhookctl
+
+import (
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/api/v1/webhook"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/service/webhooksrv"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/controller/requestbody"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+type WebhookController struct {
+	s webhooksrv.WebhookService
+}
+
+func Create(server *gin.Engine, webhookService webhooksrv.WebhookService) webhook.WebhookApi {
+	controller := &WebhookController{s: webhookService}
+	controller.SetupRoutes(server)
+	return controller
+}
+
+func (c *WebhookController) SetupRoutes(server *gin.Engine) {
+	server.POST("/api/rest/v1/webhooks", c.CreateWebhook)
+	server.GET("/api/rest/v1/webhooks", c.ListWebhooks)
+	server.GET("/api/rest/v1/webhooks/:id", c.GetWebhook)
+	server.PUT("/api/rest/v1/webhooks/:id", c.UpdateWebhook)
+	server.DELETE("/api/rest/v1/webhooks/:id", c.DeleteWebhook)
+	server.GET("/api/rest/v1/webhooks/:id/deliveries", c.ListWebhookDeliveries)
+}
+
+func (c *WebhookController) CreateWebhook(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	dto, err := parseBodyToWebhookDto(ginctx)
+	if err != nil {
+		webhookParseErrorHandler(ginctx, err)
+		return
+	}
+
+	ctx := ginctx.Request.Context()
+	subscription := c.s.NewInstance(ctx)
+	mapDtoToSubscription(dto, subscription)
+
+	err = c.s.CreateSubscription(ctx, subscription)
+	if err != nil {
+		webhookErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.Header("Location", "/api/rest/v1/webhooks/"+subscription.ID)
+	ginctx.JSON(http.StatusCreated, mapSubscriptionToDto(subscription))
+}
+
+func (c *WebhookController) ListWebhooks(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	subscriptions, err := c.s.ListSubscriptions(ginctx.Request.Context())
+	if err != nil {
+		webhookErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapSubscriptionsToListDto(subscriptions))
+}
+
+func (c *WebhookController) GetWebhook(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	subscription, err := c.s.GetSubscription(ginctx.Request.Context(), ginctx.Param("id"))
+	if err != nil {
+		webhookErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapSubscriptionToDto(subscription))
+}
+
+func (c *WebhookController) UpdateWebhook(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	dto, err := parseBodyToWebhookDto(ginctx)
+	if err != nil {
+		webhookParseErrorHandler(ginctx, err)
+		return
+	}
+
+	ctx := ginctx.Request.Context()
+	subscription := c.s.NewInstance(ctx)
+	mapDtoToSubscription(dto, subscription)
+	subscription.ID = ginctx.Param("id")
+
+	err = c.s.UpdateSubscription(ctx, subscription)
+	if err != nil {
+		webhookErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapSubscriptionToDto(subscription))
+}
+
+func (c *WebhookController) DeleteWebhook(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	err := c.s.DeleteSubscription(ginctx.Request.Context(), ginctx.Param("id"))
+	if err != nil {
+		webhookErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.Status(http.StatusNoContent)
+}
+
+func (c *WebhookController) ListWebhookDeliveries(ginctx *gin.Context) {
+	if !authentication.AuthorizeAdmin(ginctx) {
+		return
+	}
+	deliveries, err := c.s.ListDeliveries(ginctx.Request.Context(), ginctx.Param("id"))
+	if err != nil {
+		webhookErrorHandler(ginctx, err)
+		return
+	}
+	ginctx.JSON(http.StatusOK, mapDeliveriesToListDto(deliveries))
+}
+
+func parseBodyToWebhookDto(ginctx *gin.Context) (*webhook.WebhookSubscriptionDto, error) {
+	dto := &webhook.WebhookSubscriptionDto{}
+	err := requestbody.DecodeJson(ginctx, dto, configuration.ServerRequestStrictJson())
+	if err != nil {
+		dto = &webhook.WebhookSubscriptionDto{}
+	}
+	return dto, err
+}
+
+func webhookParseErrorHandler(ginctx *gin.Context, err error) {
+	requestbody.ParseErrorHandler(ginctx, apierrors.WebhookParse, err)
+}
+
+func webhookErrorHandler(ginctx *gin.Context, err error) {
+	ctx := ginctx.Request.Context()
+	var validationErr *webhooksrv.ValidationError
+	if errors.As(err, &validationErr) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.WebhookValidation, []string{validationErr.Reason})
+		return
+	}
+	if errors.Is(err, webhooksrv.ErrNotFound) {
+		errorhandlers.ErrorHandler(ginctx, apierrors.WebhookNotFound, []string{})
+		return
+	}
+	log.Ctx(ctx).Error().Err(err).Msgf("error handling webhook subscription: %v", err)
+	errorhandlers.ErrorHandler(ginctx, apierrors.WebhookFailed, []string{})
+}
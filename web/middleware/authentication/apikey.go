@@ -0,0 +1,69 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+const ApiKeyHeader = "X-API-Key"
+
+var (
+	ErrUnknownApiKey = errors.New("api key is not known")
+	ErrExpiredApiKey = errors.New("api key has expired")
+)
+
+// HashApiKey is how api keys are stored in the secrets file, sha256:<hex>.
+func HashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+// AddApiKeyInfoToContextHandlerFunc makes the owner and roles of the api key in the X-API-Key header
+// the caller identity, so CheckUserIsLoggedIn and CheckUserHasRole treat them like a token.
+//
+// Requests with a valid token ignore the header. Unknown or expired keys are rejected with a 401.
+func AddApiKeyInfoToContextHandlerFunc(keys []configuration.ApiKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(ApiKeyHeader)
+		_, tokenErr := ExtractRawTokenFromContext(c.Request.Context())
+		if key == "" || tokenErr == nil {
+			c.Next()
+			return
+		}
+
+		apiKey, err := findApiKey(keys, key, time.Now())
+		if err != nil {
+			errorhandlers.UnauthorizedErrorHandler(c, err)
+			c.Abort()
+			return
+		}
+		caller := identity.Caller{Subject: apiKey.Owner, Roles: apiKey.Roles}
+		c.Request = c.Request.WithContext(identity.WithCaller(c.Request.Context(), caller))
+		c.Next()
+	}
+}
+
+// compares against every key in constant time, so response times do not reveal how much of a hash matched
+func findApiKey(keys []configuration.ApiKey, key string, now time.Time) (*configuration.ApiKey, error) {
+	hash := []byte(HashApiKey(key))
+	var found *configuration.ApiKey
+	for i := range keys {
+		if subtle.ConstantTimeCompare(hash, []byte(keys[i].Hash)) == 1 {
+			found = &keys[i]
+		}
+	}
+	if found == nil {
+		return nil, ErrUnknownApiKey
+	}
+	if !found.Expires.IsZero() && now.After(found.Expires) {
+		return nil, ErrExpiredApiKey
+	}
+	return found, nil
+}
@@ -0,0 +1,61 @@
+package authentication
+
+import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func tstApiKeys() []configuration.ApiKey {
+	return []configuration.ApiKey{
+		{Owner: "legacy-crm", Hash: HashApiKey("crm-key"), Roles: []string{"admin"}},
+		{Owner: "legacy-archive", Hash: HashApiKey("archive-key"), Expires: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+// returns the status and the result of the admin role check
+func tstPerformWithApiKey(t *testing.T, apiKey string) (int, error) {
+	gin.SetMode(gin.ReleaseMode)
+	var roleErr error
+	router := gin.New()
+	router.Use(AddJWTTokenInfoToContextHandlerFunc("demosecret"), AddApiKeyInfoToContextHandlerFunc(tstApiKeys()))
+	router.GET("/", func(c *gin.Context) {
+		roleErr = CheckUserHasRole(c.Request.Context(), RoleAdmin)
+		c.Status(http.StatusNoContent)
+	})
+
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.Nil(t, err)
+	if apiKey != "" {
+		r.Header.Set(ApiKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w.Code, roleErr
+}
+
+func TestApiKey_ValidKey_ShouldGrantRoles(t *testing.T) {
+	status, roleErr := tstPerformWithApiKey(t, "crm-key")
+	require.Equal(t, http.StatusNoContent, status)
+	require.Nil(t, roleErr)
+}
+
+func TestApiKey_NoKey_ShouldBeAnonymous(t *testing.T) {
+	status, roleErr := tstPerformWithApiKey(t, "")
+	require.Equal(t, http.StatusNoContent, status)
+	require.NotNil(t, roleErr)
+}
+
+func TestApiKey_UnknownKey_ShouldBeRejected(t *testing.T) {
+	status, _ := tstPerformWithApiKey(t, "guessed-key")
+	require.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestApiKey_ExpiredKey_ShouldBeRejected(t *testing.T) {
+	status, _ := tstPerformWithApiKey(t, "archive-key")
+	require.Equal(t, http.StatusUnauthorized, status)
+}
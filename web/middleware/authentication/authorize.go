@@ -0,0 +1,21 @@
+package authentication
+
+import (
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthorizeAdmin is the check for administrative endpoints. Unless the caller is logged in and has the admin role,
+// it responds with 401 or 403 and returns false.
+func AuthorizeAdmin(ginctx *gin.Context) bool {
+	ctx := ginctx.Request.Context()
+	if err := CheckUserIsLoggedIn(ctx); err != nil {
+		errorhandlers.UnauthorizedErrorHandler(ginctx, err)
+		return false
+	}
+	if err := CheckUserHasRole(ctx, RoleAdmin); err != nil {
+		errorhandlers.ForbiddenErrorHandler(ginctx, err)
+		return false
+	}
+	return true
+}
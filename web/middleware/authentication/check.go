@@ -3,12 +3,16 @@ package authentication
 import (
 	"context"
 	"fmt"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
 	"github.com/dgrijalva/jwt-go"
 )
 
 // TODO role namespace url should come from config
 const RolesClaimKey = "https://github.com/StephanHCB/go-campaign-service/roles"
 
+// role required for administrative endpoints
+const RoleAdmin = identity.RoleAdmin
+
 func extractClaimFromTokenInContext(ctx context.Context, claimKey string) (interface{}, error) {
 	token, ok := ctx.Value("user").(*jwt.Token)
 	if !ok {
@@ -23,12 +27,46 @@ func extractClaimFromTokenInContext(ctx context.Context, claimKey string) (inter
 	return claimValue, nil
 }
 
+// CheckUserIsLoggedIn accepts a token, or any other caller identity such as a verified client certificate.
 func CheckUserIsLoggedIn(ctx context.Context) error {
 	_, err := extractClaimFromTokenInContext(ctx, "sub")
+	if err != nil && identity.CallerOf(ctx).Subject != "" {
+		return nil
+	}
 	return err
 }
 
+// Subject returns the subject of the token in the context, or of the other caller identity if there is no token,
+// or "" if the user is not logged in.
+func Subject(ctx context.Context) string {
+	subject, err := extractClaimFromTokenInContext(ctx, "sub")
+	if err != nil {
+		return identity.CallerOf(ctx).Subject
+	}
+	result, _ := subject.(string)
+	return result
+}
+
+// Roles returns the roles of the user in the context, skipping anything that is not a string.
+func Roles(ctx context.Context) []string {
+	result := []string{}
+	roles, err := extractClaimFromTokenInContext(ctx, RolesClaimKey)
+	if err != nil {
+		return result
+	}
+	rolesList, _ := roles.([]interface{})
+	for _, roleValue := range rolesList {
+		if role, ok := roleValue.(string); ok {
+			result = append(result, role)
+		}
+	}
+	return result
+}
+
 func CheckUserHasRole(ctx context.Context, role string) error {
+	if _, hasToken := ctx.Value("user").(*jwt.Token); !hasToken {
+		return checkCallerHasRole(ctx, role)
+	}
 	roles, err := extractClaimFromTokenInContext(ctx, RolesClaimKey)
 	if err != nil {
 		return err
@@ -51,3 +89,17 @@ func CheckUserHasRole(ctx context.Context, role string) error {
 
 	return fmt.Errorf("user does not have required role '%s'", role)
 }
+
+// callers without a token may still have roles, depending on how they authenticated
+func checkCallerHasRole(ctx context.Context, role string) error {
+	caller := identity.CallerOf(ctx)
+	if caller.Subject == "" {
+		return fmt.Errorf("no token found in context")
+	}
+	for _, callerRole := range caller.Roles {
+		if callerRole == role {
+			return nil
+		}
+	}
+	return fmt.Errorf("user does not have required role '%s'", role)
+}
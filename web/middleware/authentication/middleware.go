@@ -1,10 +1,17 @@
 package authentication
 
 import (
+	"context"
+	"crypto/tls"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
 	jwtmiddleware "github.com/auth0/go-jwt-middleware"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/go-http-utils/headers"
 	"net/http"
+	"time"
 )
 
 // TODO we need asymmetric crypto because we want to keep the key only in the IAM,
@@ -21,6 +28,8 @@ func createAndConfigureAuthenticationMiddleware(secret string) *jwtmiddleware.JW
 		SigningMethod: jwt.SigningMethodHS256,
 		// Allow missing credentials, will leave the "user" context key unset (which you should interpret as "not authenticated")
 		CredentialsOptional: true,
+		// the default writes a text/plain response, callers render the error like any other instead
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err string) {},
 	})
 	return jwtMiddleware
 }
@@ -34,13 +43,76 @@ func AddJWTTokenInfoToContextHandlerFunc(secret string) gin.HandlerFunc {
 
 		err := authMw.CheckJWT(w, r)
 		if err != nil {
-			// TODO react to error with some more detail than just a 401
 			// note that this error does not trigger if the Authorization header is missing completely, only if
 			// there is something wrong with it
-			w.WriteHeader(http.StatusUnauthorized)
+			errorhandlers.UnauthorizedErrorHandler(c, err)
+			c.Abort()
 			return
 		}
+		if subject := Subject(r.Context()); subject != "" {
+			c.Request = r.WithContext(identity.WithCaller(r.Context(), identity.Caller{Subject: subject, Roles: Roles(r.Context())}))
+		} else if subject := ClientCertificateSubject(r); subject != "" {
+			c.Request = r.WithContext(identity.WithCaller(r.Context(), identity.Caller{Subject: subject}))
+		}
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// AddCallerInfoToContext does for other protocols, such as grpc, what AddJWTTokenInfoToContextHandlerFunc and
+// AddApiKeyInfoToContextHandlerFunc do for http, so callers are identified the same way on every protocol.
+//
+// authorization and apiKey are what would be in the Authorization and X-API-Key headers, tlsState is the state of the
+// connection, nil without tls. A valid token wins over an api key, which wins over a verified client certificate.
+// Without any of them, ctx is returned unchanged. An invalid token or an unknown or expired api key is an error.
+func AddCallerInfoToContext(ctx context.Context, secret string, keys []configuration.ApiKey, authorization string, apiKey string, tlsState *tls.ConnectionState) (context.Context, error) {
+	ctx, err := addJWTTokenInfoToContext(ctx, secret, authorization)
+	if err != nil {
+		return ctx, err
+	}
+	if Subject(ctx) != "" {
+		return ctx, nil
+	}
+	if apiKey != "" {
+		found, err := findApiKey(keys, apiKey, time.Now())
+		if err != nil {
+			return ctx, err
+		}
+		return identity.WithCaller(ctx, identity.Caller{Subject: found.Owner, Roles: found.Roles}), nil
+	}
+	if subject := verifiedClientCertificateSubject(tlsState); subject != "" {
+		return identity.WithCaller(ctx, identity.Caller{Subject: subject}), nil
+	}
+	return ctx, nil
+}
+
+func addJWTTokenInfoToContext(ctx context.Context, secret string, authorization string) (context.Context, error) {
+	authMw := createAndConfigureAuthenticationMiddleware(secret)
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return ctx, err
+	}
+	r.Header.Set(headers.Authorization, authorization)
+	if err := authMw.CheckJWT(nil, r); err != nil {
+		return ctx, err
+	}
+	ctx = r.Context()
+	if subject := Subject(ctx); subject != "" {
+		ctx = identity.WithCaller(ctx, identity.Caller{Subject: subject, Roles: Roles(ctx)})
+	}
+	return ctx, nil
+}
+
+// ClientCertificateSubject returns the subject of the verified client certificate, e.g. CN=campaign-service,O=Example,
+// or "" if the client did not present one. Client certificates are only verified if server.tls.client.ca is set.
+func ClientCertificateSubject(r *http.Request) string {
+	return verifiedClientCertificateSubject(r.TLS)
+}
+
+func verifiedClientCertificateSubject(state *tls.ConnectionState) string {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return state.VerifiedChains[0][0].Subject.String()
+}
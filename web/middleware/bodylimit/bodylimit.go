@@ -0,0 +1,57 @@
+package bodylimit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/gin-gonic/gin"
+	"io"
+)
+
+// ErrBodyTooLarge is returned when reading more than the allowed number of bytes from a request body.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// LimitRequestBody rejects request bodies larger than maxBytes with a 413, 0 disables the limit.
+//
+// Requests that announce a larger Content-Length are rejected right away. For all others, reading the body
+// fails with ErrBodyTooLarge once the limit is exceeded, so the body is never read fully.
+func LimitRequestBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			errorhandlers.PayloadTooLargeErrorHandler(c, fmt.Errorf("content length %d exceeds %d bytes: %w", c.Request.ContentLength, maxBytes, ErrBodyTooLarge))
+			c.Abort()
+			return
+		}
+		c.Request.Body = &limitedBody{ReadCloser: c.Request.Body, remaining: maxBytes}
+		c.Next()
+	}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+	// read one byte more than allowed, so we notice a body that is exactly one byte too long
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.exceeded = true
+		return n, ErrBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,63 @@
+package bodylimit
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// returns the status, and what the handler read from the body
+func tstPerform(t *testing.T, maxBytes int64, body string, announceLength bool) (int, string, error) {
+	gin.SetMode(gin.ReleaseMode)
+	var read []byte
+	var readErr error
+	router := gin.New()
+	router.Use(LimitRequestBody(maxBytes))
+	router.POST("/", func(c *gin.Context) {
+		read, readErr = ioutil.ReadAll(c.Request.Body)
+		c.Status(http.StatusNoContent)
+	})
+
+	// a reader of unknown length makes the request look chunked
+	var reader io.Reader = strings.NewReader(body)
+	if !announceLength {
+		reader = ioutil.NopCloser(reader)
+	}
+	r, err := http.NewRequest(http.MethodPost, "/", reader)
+	require.Nil(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w.Code, string(read), readErr
+}
+
+func TestLimitRequestBody_WithinLimit_ShouldPassBody(t *testing.T) {
+	status, read, err := tstPerform(t, 10, "0123456789", false)
+	require.Equal(t, http.StatusNoContent, status)
+	require.Nil(t, err)
+	require.Equal(t, "0123456789", read)
+}
+
+func TestLimitRequestBody_AnnouncedTooLarge_ShouldRejectUpFront(t *testing.T) {
+	status, read, _ := tstPerform(t, 10, "0123456789a", true)
+	require.Equal(t, http.StatusRequestEntityTooLarge, status)
+	require.Empty(t, read)
+}
+
+func TestLimitRequestBody_ChunkedTooLarge_ShouldFailWhileReading(t *testing.T) {
+	_, read, err := tstPerform(t, 10, strings.Repeat("x", 100000), false)
+	require.True(t, errors.Is(err, ErrBodyTooLarge))
+	require.Equal(t, strings.Repeat("x", 10), read)
+}
+
+func TestLimitRequestBody_Disabled_ShouldPassBody(t *testing.T) {
+	status, read, err := tstPerform(t, 0, strings.Repeat("x", 100000), true)
+	require.Equal(t, http.StatusNoContent, status)
+	require.Nil(t, err)
+	require.Len(t, read, 100000)
+}
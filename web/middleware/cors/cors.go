@@ -0,0 +1,115 @@
+package cors
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-http-utils/headers"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Options struct {
+	// * allows any origin, empty turns cors off
+	Origins     []string
+	Methods     []string
+	Headers     []string
+	Expose      []string
+	Credentials bool
+	MaxAge      time.Duration
+}
+
+// HandleCors lets browsers call the api from the configured origins.
+//
+// Preflight requests are answered right here, with a 204 if the origin, method and headers are allowed and a 403
+// otherwise, so it must run before authentication, as browsers never send credentials with them. Other requests
+// are served as usual, but only responses to allowed origins carry the headers that let the browser read them.
+func HandleCors(options Options) gin.HandlerFunc {
+	anyOrigin := contains(options.Origins, "*")
+	allowedMethods := strings.Join(options.Methods, ", ")
+	allowedHeaders := strings.Join(options.Headers, ", ")
+	exposedHeaders := strings.Join(options.Expose, ", ")
+	maxAge := strconv.Itoa(int(options.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		if len(options.Origins) == 0 {
+			c.Next()
+			return
+		}
+		// the answer depends on the origin, so caches must not hand it to other origins
+		c.Writer.Header().Add(headers.Vary, headers.Origin)
+
+		origin := c.GetHeader(headers.Origin)
+		if origin == "" {
+			c.Next()
+			return
+		}
+		allowed := anyOrigin || contains(options.Origins, origin)
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader(headers.AccessControlRequestMethod) != "" {
+			if !allowed || !isPreflightAllowed(c.Request, options) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			setAllowOrigin(c, origin, anyOrigin, options.Credentials)
+			c.Header(headers.AccessControlAllowMethods, allowedMethods)
+			if allowedHeaders != "" {
+				c.Header(headers.AccessControlAllowHeaders, allowedHeaders)
+			}
+			c.Header(headers.AccessControlMaxAge, maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if allowed {
+			setAllowOrigin(c, origin, anyOrigin, options.Credentials)
+			if exposedHeaders != "" {
+				c.Header(headers.AccessControlExposeHeaders, exposedHeaders)
+			}
+		}
+		c.Next()
+	}
+}
+
+func isPreflightAllowed(r *http.Request, options Options) bool {
+	if !containsFold(options.Methods, r.Header.Get(headers.AccessControlRequestMethod)) {
+		return false
+	}
+	for _, header := range strings.Split(r.Header.Get(headers.AccessControlRequestHeaders), ",") {
+		if header = strings.TrimSpace(header); header != "" && !containsFold(options.Headers, header) {
+			return false
+		}
+	}
+	return true
+}
+
+// browsers reject * together with credentials, configuration does not allow that combination
+func setAllowOrigin(c *gin.Context, origin string, anyOrigin bool, credentials bool) {
+	if anyOrigin {
+		c.Header(headers.AccessControlAllowOrigin, "*")
+	} else {
+		c.Header(headers.AccessControlAllowOrigin, origin)
+	}
+	if credentials {
+		c.Header(headers.AccessControlAllowCredentials, "true")
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// header names are case insensitive, and methods are treated the same way as browsers upper case the common ones
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
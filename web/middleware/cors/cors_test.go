@@ -0,0 +1,75 @@
+package cors
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func tstOptions(origins ...string) Options {
+	return Options{
+		Origins: origins,
+		Methods: []string{"GET", "POST"},
+		Headers: []string{"Authorization", "Content-Type"},
+		Expose:  []string{"Location"},
+		MaxAge:  10 * time.Minute,
+	}
+}
+
+func tstPerform(t *testing.T, options Options, method string, requestHeaders map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(HandleCors(options))
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r, err := http.NewRequest(method, "/", nil)
+	require.Nil(t, err)
+	for name, value := range requestHeaders {
+		r.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandleCors_NoOrigins_ShouldAddNothing(t *testing.T) {
+	w := tstPerform(t, tstOptions(), http.MethodGet, map[string]string{headers.Origin: "https://admin.example.com"})
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Empty(t, w.Header().Get(headers.AccessControlAllowOrigin))
+	require.Empty(t, w.Header().Get(headers.Vary))
+}
+
+func TestHandleCors_AnyOrigin_ShouldAllowWithWildcard(t *testing.T) {
+	w := tstPerform(t, tstOptions("*"), http.MethodGet, map[string]string{headers.Origin: "https://elsewhere.example.com"})
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "*", w.Header().Get(headers.AccessControlAllowOrigin))
+	require.Equal(t, "Location", w.Header().Get(headers.AccessControlExposeHeaders))
+	require.Empty(t, w.Header().Get(headers.AccessControlAllowCredentials))
+}
+
+func TestHandleCors_PreflightWithUnknownHeader_ShouldForbid(t *testing.T) {
+	w := tstPerform(t, tstOptions("https://admin.example.com"), http.MethodOptions, map[string]string{
+		headers.Origin:                      "https://admin.example.com",
+		headers.AccessControlRequestMethod:  "POST",
+		headers.AccessControlRequestHeaders: "content-type, x-debug",
+	})
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Empty(t, w.Header().Get(headers.AccessControlAllowOrigin))
+}
+
+func TestHandleCors_PreflightWithUnknownMethod_ShouldForbid(t *testing.T) {
+	w := tstPerform(t, tstOptions("https://admin.example.com"), http.MethodOptions, map[string]string{
+		headers.Origin:                     "https://admin.example.com",
+		headers.AccessControlRequestMethod: "DELETE",
+	})
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
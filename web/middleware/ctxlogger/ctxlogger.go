@@ -1,9 +1,10 @@
 package ctxlogger
 
 import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
-	"github.com/thanhhh/gin-requestid"
 )
 
 func AddZerologLoggerToRequestContext() gin.HandlerFunc {
@@ -11,15 +12,17 @@ func AddZerologLoggerToRequestContext() gin.HandlerFunc {
 		r := c.Request
 		ctx := r.Context()
 
-		requestId := requestid.GetReqID(c)
+		requestId := tracing.RequestId(ctx)
 
-		sublogger := log.Logger.With().
-			Str("request-id", requestId).
-			Logger()
+		loggerContext := log.Logger.With().Str(logging.FieldRequestId, requestId)
+		if traceId := tracing.TraceId(ctx); traceId != "" {
+			loggerContext = loggerContext.Str(logging.FieldTraceId, traceId)
+		}
+		sublogger := loggerContext.Logger()
 		newCtx := sublogger.WithContext(ctx)
 
 		c.Request = r.WithContext(newCtx)
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,47 @@
+package deprecation
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MarkDeprecated announces that a route is deprecated and what replaces it.
+//
+// It sets Deprecation (RFC 9745) to since, Sunset (RFC 8594) to sunset unless that is the zero time,
+// and a Link to successor with rel="successor-version". The request itself is served as usual.
+//
+// Until since is set, the route is not deprecated and gets none of these headers.
+// Path parameters in successor, like :id, are filled in from the request.
+func MarkDeprecated(since time.Time, sunset time.Time, successor string) gin.HandlerFunc {
+	if since.IsZero() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	sunsetValue := ""
+	if !sunset.IsZero() {
+		sunsetValue = sunset.UTC().Format(http.TimeFormat)
+	}
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunsetValue != "" {
+			c.Header("Sunset", sunsetValue)
+		}
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, fillPathParams(c, successor)))
+		c.Next()
+	}
+}
+
+func fillPathParams(c *gin.Context, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = c.Param(segment[1:])
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,64 @@
+package deprecation
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func tstPerform(t *testing.T, since time.Time, sunset time.Time) *httptest.ResponseRecorder {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.GET("/old", MarkDeprecated(since, sunset, "/new"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r, err := http.NewRequest(http.MethodGet, "/old", nil)
+	require.Nil(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func TestMarkDeprecated_WithSunset_ShouldSetAllHeaders(t *testing.T) {
+	w := tstPerform(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2027, 4, 1, 12, 0, 0, 0, time.FixedZone("CEST", 7200)))
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	require.Equal(t, "Thu, 01 Apr 2027 10:00:00 GMT", w.Header().Get("Sunset"))
+	require.Equal(t, `</new>; rel="successor-version"`, w.Header().Get("Link"))
+}
+
+func TestMarkDeprecated_WithoutSunset_ShouldOmitSunset(t *testing.T) {
+	w := tstPerform(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Time{})
+
+	require.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	_, ok := w.Header()["Sunset"]
+	require.False(t, ok)
+}
+
+func TestMarkDeprecated_WithoutSince_ShouldSetNoHeaders(t *testing.T) {
+	w := tstPerform(t, time.Time{}, time.Date(2027, 4, 1, 12, 0, 0, 0, time.UTC))
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	for _, header := range []string{"Deprecation", "Sunset", "Link"} {
+		_, ok := w.Header()[header]
+		require.False(t, ok, header)
+	}
+}
+
+func TestMarkDeprecated_ShouldFillInPathParams(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.DELETE("/old/:id", MarkDeprecated(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Time{}, "/new/:id"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r, err := http.NewRequest(http.MethodDelete, "/old/abc", nil)
+	require.Nil(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	require.Equal(t, `</new/abc>; rel="successor-version"`, w.Header().Get("Link"))
+}
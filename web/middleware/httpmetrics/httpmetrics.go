@@ -0,0 +1,32 @@
+package httpmetrics
+
+import (
+	"github.com/armon/go-metrics"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// RecordRequestMetrics counts requests and measures their latency in milliseconds,
+// labelled with method, route template and status.
+//
+// The route template (e.g. /api/rest/v1/webhooks/:id) is used instead of the path to keep cardinality bounded.
+func RecordRequestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := []metrics.Label{
+			{Name: "method", Value: c.Request.Method},
+			{Name: "route", Value: route},
+			{Name: "status", Value: strconv.Itoa(c.Writer.Status())},
+		}
+		metrics.IncrCounterWithLabels([]string{"http", "requests"}, 1, labels)
+		metrics.MeasureSinceWithLabels([]string{"http", "request", "duration"}, start, labels)
+	}
+}
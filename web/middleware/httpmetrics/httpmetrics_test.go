@@ -0,0 +1,36 @@
+package httpmetrics
+
+import (
+	"github.com/armon/go-metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecordRequestMetrics_ShouldLabelByRouteTemplate(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	conf := metrics.DefaultConfig("test")
+	conf.EnableHostname = false
+	conf.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(conf, sink)
+	require.Nil(t, err)
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(RecordRequestMetrics())
+	router.GET("/things/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/things/1", "/things/2", "/nothing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	data := sink.Data()
+	require.NotEmpty(t, data)
+	counters := data[0].Counters
+	require.Equal(t, 2, counters["test.http.requests;method=GET;route=/things/:id;status=204"].Count)
+	require.Equal(t, 1, counters["test.http.requests;method=GET;route=unmatched;status=404"].Count)
+	require.Equal(t, 2, data[0].Samples["test.http.request.duration;method=GET;route=/things/:id;status=204"].Count)
+}
@@ -0,0 +1,40 @@
+package httptracing
+
+import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartServerSpan creates a server span for each request, continuing the trace of the caller
+// if it sent a W3C traceparent header.
+//
+// Must run before the context logger, so log entries can carry the trace id.
+func StartServerSpan() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := c.Request
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		route := c.FullPath()
+		name := r.Method + " " + route
+		if route == "" {
+			name = "HTTP " + r.Method
+		}
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(configuration.ServiceName(), route, r)...))
+		defer span.End()
+
+		c.Request = r.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+	}
+}
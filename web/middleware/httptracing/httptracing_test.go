@@ -0,0 +1,54 @@
+package httptracing
+
+import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func tstRouter() *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(StartServerSpan())
+	router.GET("/things/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, tracing.TraceId(c.Request.Context()))
+	})
+	router.GET("/broken", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	return router
+}
+
+func TestStartServerSpan_ShouldContinueIncomingTrace(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	recorder := tracing.SetupForTesting()
+
+	request := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	response := httptest.NewRecorder()
+	tstRouter().ServeHTTP(response, request)
+
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", response.Body.String())
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "GET /things/:id", spans[0].Name())
+	require.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	require.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	require.True(t, spans[0].Parent().IsRemote())
+}
+
+func TestStartServerSpan_ShouldStartNewTraceAndRecordErrors(t *testing.T) {
+	configuration.SetupForUnitTestDefaultsOnlyNoErrors()
+	recorder := tracing.SetupForTesting()
+
+	tstRouter().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/broken", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.False(t, spans[0].Parent().IsValid())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
@@ -0,0 +1,57 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/gin-gonic/gin"
+	"net"
+	"regexp"
+)
+
+const Header = "X-Request-Id"
+
+// generous enough for uuids and the ids of common proxies, but keeps header injection and log spam out
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// AddRequestIdToContext places the request id in the request context and the X-Request-Id response header.
+//
+// Callers from the trusted networks may pass in their own request id in the X-Request-Id header, so it
+// stays the same across services. Everybody else, and invalid ids, get a newly generated random id.
+func AddRequestIdToContext(trusted []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(Header)
+		if requestId == "" || !validRequestId.MatchString(requestId) || !isTrusted(c.Request.RemoteAddr, trusted) {
+			requestId = generate()
+		}
+
+		c.Request = c.Request.WithContext(tracing.WithRequestId(c.Request.Context(), requestId))
+		c.Header(Header, requestId)
+
+		c.Next()
+	}
+}
+
+// deliberately uses the peer address and not X-Forwarded-For, which anybody can set
+func isTrusted(remoteAddr string, trusted []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func generate() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,60 @@
+package requestid
+
+import (
+	"github.com/StephanHCB/go-mailer-service/internal/repository/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func tstRequest(remoteAddr string, requestId string) *httptest.ResponseRecorder {
+	_, trusted, _ := net.ParseCIDR("10.0.0.0/8")
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(AddRequestIdToContext([]*net.IPNet{trusted}))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, tracing.RequestId(c.Request.Context()))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.RemoteAddr = remoteAddr
+	if requestId != "" {
+		request.Header.Set(Header, requestId)
+	}
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response
+}
+
+func TestAddRequestIdToContext_ShouldAcceptIdFromTrustedCaller(t *testing.T) {
+	response := tstRequest("10.1.2.3:4711", "caller-id-42")
+
+	require.Equal(t, "caller-id-42", response.Body.String())
+	require.Equal(t, "caller-id-42", response.Header().Get(Header))
+}
+
+func TestAddRequestIdToContext_ShouldIgnoreIdFromUntrustedCaller(t *testing.T) {
+	response := tstRequest("192.168.1.1:4711", "caller-id-42")
+
+	require.Len(t, response.Body.String(), 16)
+	require.NotEqual(t, "caller-id-42", response.Body.String())
+	require.Equal(t, response.Body.String(), response.Header().Get(Header))
+}
+
+func TestAddRequestIdToContext_ShouldReplaceInvalidId(t *testing.T) {
+	response := tstRequest("10.1.2.3:4711", "evil\tid")
+
+	require.Len(t, response.Body.String(), 16)
+}
+
+func TestAddRequestIdToContext_ShouldGenerateIdIfMissing(t *testing.T) {
+	first := tstRequest("10.1.2.3:4711", "")
+	second := tstRequest("10.1.2.3:4711", "")
+
+	require.Len(t, first.Body.String(), 16)
+	require.NotEqual(t, first.Body.String(), second.Body.String())
+}
@@ -0,0 +1,63 @@
+package requestlogging
+
+import (
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+// LogRequests logs every request once it has completed, using ECS http.*, url.*, user.* and event.* fields.
+//
+// Must run after the context logger, so the entry carries the request id and trace id. Client errors are
+// logged as warnings and server errors as errors. Request and response bodies are never logged.
+func LogRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		r := c.Request
+		ctx := r.Context()
+		status := c.Writer.Status()
+
+		level := zerolog.InfoLevel
+		if status >= http.StatusInternalServerError {
+			level = zerolog.ErrorLevel
+		} else if status >= http.StatusBadRequest {
+			level = zerolog.WarnLevel
+		}
+
+		event := log.Ctx(ctx).WithLevel(level).
+			Str("event.kind", "event").
+			Str("event.category", "web").
+			Int64("event.duration", time.Since(start).Nanoseconds()).
+			Str("http.version", fmt.Sprintf("%d.%d", r.ProtoMajor, r.ProtoMinor)).
+			Str("http.request.method", r.Method).
+			Int("http.response.status_code", status).
+			Str("url.path", r.URL.Path).
+			Str("client.ip", c.ClientIP())
+		if r.ContentLength > 0 {
+			event = event.Int64("http.request.body.bytes", r.ContentLength)
+		}
+		if size := c.Writer.Size(); size > 0 {
+			event = event.Int("http.response.body.bytes", size)
+		}
+		if r.URL.RawQuery != "" {
+			event = event.Str("url.query", r.URL.RawQuery)
+		}
+		if userAgent := r.UserAgent(); userAgent != "" {
+			event = event.Str("user_agent.original", userAgent)
+		}
+		if userId := authentication.Subject(ctx); userId != "" {
+			event = event.Str("user.id", userId)
+		}
+		if len(c.Errors) > 0 {
+			event = event.Str("error.message", c.Errors.String())
+		}
+		event.Msgf("%s %s %d", r.Method, r.URL.Path, status)
+	}
+}
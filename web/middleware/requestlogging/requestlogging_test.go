@@ -0,0 +1,67 @@
+package requestlogging
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/logging"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/ctxlogger"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/requestid"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func tstLoggedEntry(t *testing.T, request *http.Request) map[string]interface{} {
+	logging.SetupForTesting()
+	logging.RecordedLogForTesting.Reset()
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(requestid.AddRequestIdToContext(nil), ctxlogger.AddZerologLoggerToRequestContext(), LogRequests())
+	router.Use(func(c *gin.Context) {
+		// stands in for the authentication middleware
+		token := &jwt.Token{Claims: jwt.MapClaims{"sub": "alice"}}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), "user", token))
+	})
+	router.POST("/things", func(c *gin.Context) { c.String(http.StatusCreated, "created") })
+
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	lines := strings.Split(strings.TrimSpace(logging.RecordedLogForTesting.String()), "\n")
+	require.Len(t, lines, 1)
+	entry := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal([]byte(lines[0]), &entry))
+	return entry
+}
+
+func TestLogRequests_ShouldLogEcsFields(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/things?debug=1", strings.NewReader("some body"))
+	request.Header.Set("User-Agent", "test-agent")
+
+	entry := tstLoggedEntry(t, request)
+
+	require.Equal(t, "info", entry["log.level"])
+	require.Equal(t, "POST /things 201", entry["message"])
+	require.Equal(t, "POST", entry["http.request.method"])
+	require.Equal(t, float64(201), entry["http.response.status_code"])
+	require.Equal(t, float64(9), entry["http.request.body.bytes"])
+	require.Equal(t, float64(7), entry["http.response.body.bytes"])
+	require.Equal(t, "/things", entry["url.path"])
+	require.Equal(t, "debug=1", entry["url.query"])
+	require.Equal(t, "test-agent", entry["user_agent.original"])
+	require.Equal(t, "alice", entry["user.id"])
+	require.NotEmpty(t, entry["http.request.id"])
+	require.Greater(t, entry["event.duration"], float64(0))
+	require.NotContains(t, entry, "http.request.body.content")
+}
+
+func TestLogRequests_ShouldWarnOnClientErrors(t *testing.T) {
+	entry := tstLoggedEntry(t, httptest.NewRequest(http.MethodGet, "/nothing", nil))
+
+	require.Equal(t, "warn", entry["log.level"])
+	require.Equal(t, float64(404), entry["http.response.status_code"])
+}
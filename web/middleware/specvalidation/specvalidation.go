@@ -0,0 +1,285 @@
+package specvalidation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/StephanHCB/go-mailer-service/api/v1/apierrors"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/StephanHCB/go-mailer-service/web/controller/errorhandlers"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/bodylimit"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Spec is the api description that requests and responses are validated against.
+type Spec struct {
+	// the router in kin-openapi v0.80 writes to the route it has found, so each request borrows a router of its own
+	routers sync.Pool
+}
+
+// Parse prepares an openapi 3 spec for validation, it must not be changed afterwards.
+func Parse(doc *openapi3.T) (*Spec, error) {
+	// the spec documents localhost, but we want to match requests no matter which host name they used
+	doc.Servers = nil
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	spec := &Spec{}
+	spec.routers.New = func() interface{} {
+		// cannot fail, the same spec has already been turned into a router
+		router, _ := gorillamux.NewRouter(doc)
+		return router
+	}
+	spec.routers.Put(router)
+	return spec, nil
+}
+
+func (s *Spec) findRoute(r *http.Request) (*routers.Route, map[string]string, error) {
+	router := s.routers.Get().(routers.Router)
+	defer s.routers.Put(router)
+	route, pathParams, err := router.FindRoute(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	copied := *route
+	return &copied, pathParams, nil
+}
+
+// ValidateRequests rejects requests that do not match the spec with a 400, listing the violations as json pointers.
+//
+// Requests for paths that are not in the spec, such as the health endpoints, are passed on unchecked.
+// Bodies that are not valid json are also passed on, so the controllers can explain what is wrong with them.
+//
+// Operations with security requirements in the spec are answered with a 401 if no caller was identified, before
+// any violation is reported, so anonymous callers learn nothing about them. Roles are left to the controllers.
+//
+// If validateResponses is set, responses are checked as well and replaced by a 500 if they violate the spec.
+// This is meant for tests, because every response is held in memory until the handler is done.
+func ValidateRequests(spec *Spec, validateResponses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := spec.findRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: requireCaller,
+			},
+		}
+		err = openapi3filter.ValidateRequest(c.Request.Context(), input)
+		var securityErr *openapi3filter.SecurityRequirementsError
+		if errors.As(err, &securityErr) {
+			errorhandlers.UnauthorizedErrorHandler(c, securityErr)
+			c.Abort()
+			return
+		}
+		if err := violations(err); err != nil {
+			if errors.Is(err, bodylimit.ErrBodyTooLarge) {
+				errorhandlers.PayloadTooLargeErrorHandler(c, err)
+			} else {
+				requestValidationErrorHandler(c, err)
+			}
+			c.Abort()
+			return
+		}
+
+		if !validateResponses {
+			c.Next()
+			return
+		}
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		if err := validateResponse(c, input, writer); err != nil {
+			responseValidationErrorHandler(c, err)
+			return
+		}
+		writer.flush()
+	}
+}
+
+// every security scheme in the spec identifies the caller, so any of them will do, and so will a client certificate
+func requireCaller(ctx context.Context, _ *openapi3filter.AuthenticationInput) error {
+	if identity.CallerOf(ctx).Subject == "" {
+		return errors.New("no caller identified")
+	}
+	return nil
+}
+
+// violations drops errors about bodies that could not be parsed, the controllers explain those better.
+//
+// The validator has placed a copy of the body in the request, which is what the handler gets to read.
+func violations(err error) error {
+	if err == nil {
+		return nil
+	}
+	var multiErr openapi3.MultiError
+	if !errors.As(err, &multiErr) {
+		multiErr = openapi3.MultiError{err}
+	}
+	result := openapi3.MultiError{}
+	for _, e := range multiErr {
+		var parseErr *openapi3filter.ParseError
+		if !errors.As(e, &parseErr) {
+			result = append(result, e)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func validateResponse(c *gin.Context, input *openapi3filter.RequestValidationInput, writer *bufferedWriter) error {
+	return openapi3filter.ValidateResponse(c.Request.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 writer.status,
+		Header:                 writer.Header(),
+		Body:                   ioutil.NopCloser(bytes.NewReader(writer.body.Bytes())),
+		Options:                &openapi3filter.Options{MultiError: true},
+	})
+}
+
+func requestValidationErrorHandler(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	log.Ctx(ctx).Warn().Err(err).Msgf("request does not match the api spec: %v", err)
+	errorhandlers.ErrorHandler(c, apierrors.RequestValidation, describe(err))
+}
+
+func responseValidationErrorHandler(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	log.Ctx(ctx).Error().Err(err).Msgf("response does not match the api spec: %v", err)
+	errorhandlers.ErrorHandler(c, apierrors.ResponseValidation, describe(err))
+}
+
+// describe lists the violations as "<json pointer>: problem" for the body, and "<in> <name>: problem" for parameters
+func describe(err error) []string {
+	var requestErr *openapi3filter.RequestError
+	var responseErr *openapi3filter.ResponseError
+	// not errors.As, which would find the MultiError inside of a RequestError and lose the location
+	if multiErr, ok := err.(openapi3.MultiError); ok {
+		details := []string{}
+		for _, e := range multiErr {
+			details = append(details, describe(e)...)
+		}
+		sort.Strings(details)
+		return details
+	}
+	switch {
+	case errors.As(err, &requestErr) && requestErr.Parameter != nil:
+		return located(fmt.Sprintf("%s %s", requestErr.Parameter.In, requestErr.Parameter.Name), false, requestErr.Reason, requestErr.Err)
+	case errors.As(err, &requestErr):
+		return located("body", true, requestErr.Reason, requestErr.Err)
+	case errors.As(err, &responseErr):
+		return located("response", true, responseErr.Reason, responseErr.Err)
+	default:
+		return []string{err.Error()}
+	}
+}
+
+// schema errors in a json document are reported by their json pointer, everything else by location
+func located(location string, document bool, reason string, cause error) []string {
+	var multiErr openapi3.MultiError
+	var schemaErr *openapi3.SchemaError
+	switch {
+	case errors.As(cause, &multiErr):
+		details := []string{}
+		for _, e := range multiErr {
+			details = append(details, located(location, document, reason, e)...)
+		}
+		return details
+	case errors.As(cause, &schemaErr) && document && len(schemaErr.JSONPointer()) > 0:
+		return []string{pointer(schemaErr.JSONPointer()) + ": " + reasonOf(schemaErr)}
+	case errors.As(cause, &schemaErr):
+		return []string{location + ": " + reasonOf(schemaErr)}
+	case cause != nil && reason != "":
+		return []string{location + ": " + reason + ": " + cause.Error()}
+	case cause != nil:
+		return []string{location + ": " + cause.Error()}
+	default:
+		return []string{location + ": " + reason}
+	}
+}
+
+// the format errors quote the whole regular expression, which does not help anyone
+func reasonOf(schemaErr *openapi3.SchemaError) string {
+	if i := strings.Index(schemaErr.Reason, " (regular expression"); i > 0 {
+		return schemaErr.Reason[:i]
+	}
+	return schemaErr.Reason
+}
+
+// pointer renders an RFC 6901 json pointer
+func pointer(path []string) string {
+	result := ""
+	for _, element := range path {
+		result += "/" + strings.ReplaceAll(strings.ReplaceAll(element, "~", "~0"), "/", "~1")
+	}
+	return result
+}
+
+// bufferedWriter holds back the response, so it can still be replaced if it fails validation.
+//
+// gin writes the status itself once all handlers are done, which is after flush.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	} else {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
@@ -0,0 +1,173 @@
+package specvalidation
+
+import (
+	"encoding/json"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/identity"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const tstSpec = `{
+  "swagger": "2.0",
+  "host": "localhost:8080",
+  "basePath": "/",
+  "paths": {
+    "/things/{id}": {
+      "put": {
+        "consumes": ["application/json"],
+        "produces": ["application/json"],
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "string"},
+          {"name": "Body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/thing"}}
+        ],
+        "responses": {
+          "200": {"description": "the thing", "schema": {"$ref": "#/definitions/thing"}}
+        }
+      }
+    },
+    "/owned/{id}": {
+      "put": {
+        "consumes": ["application/json"],
+        "produces": ["application/json"],
+        "security": [{"bearer": []}],
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "string"},
+          {"name": "Body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/thing"}}
+        ],
+        "responses": {
+          "200": {"description": "the thing", "schema": {"$ref": "#/definitions/thing"}}
+        }
+      }
+    }
+  },
+  "securityDefinitions": {
+    "bearer": {"type": "apiKey", "name": "Authorization", "in": "header"}
+  },
+  "definitions": {
+    "thing": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string"},
+        "owner": {"type": "string", "format": "email"},
+        "tags": {"type": "array", "items": {"type": "string"}}
+      }
+    }
+  }
+}`
+
+// the handler echoes the body, unless a response is given
+func tstPerform(t *testing.T, path string, body string, response string) (int, string) {
+	return tstPerformAs(t, identity.Caller{}, path, body, response)
+}
+
+func tstPerformAs(t *testing.T, caller identity.Caller, path string, body string, response string) (int, string) {
+	spec := tstParse(t)
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(identity.WithCaller(c.Request.Context(), caller))
+	}, ValidateRequests(spec, true))
+	router.PUT("/*path", func(c *gin.Context) {
+		if response == "" {
+			read, _ := ioutil.ReadAll(c.Request.Body)
+			response = string(read)
+		}
+		c.Data(http.StatusOK, "application/json", []byte(response))
+	})
+
+	r := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w.Code, w.Body.String()
+}
+
+func tstParse(t *testing.T) *Spec {
+	doc2 := &openapi2.T{}
+	require.Nil(t, json.Unmarshal([]byte(tstSpec), doc2))
+	doc3, err := openapi2conv.ToV3(doc2)
+	require.Nil(t, err)
+	spec, err := Parse(doc3)
+	require.Nil(t, err)
+	return spec
+}
+
+func TestValidateRequests_Valid_ShouldPass(t *testing.T) {
+	status, body := tstPerform(t, "/things/1", `{"name":"x","owner":"someone@example.com"}`, "")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `{"name":"x","owner":"someone@example.com"}`, body)
+}
+
+func TestValidateRequests_Violations_ShouldListJsonPointers(t *testing.T) {
+	status, body := tstPerform(t, "/things/1", `{"owner":"nobody","tags":["a",2]}`, "")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Contains(t, body, `"message":"request.validation.error"`)
+	require.Contains(t, body, `"details":["/name: property \"name\" is missing","/owner: string doesn't match the format \"email\"","/tags/1: Field must be set to string or not be present"]`)
+}
+
+func TestValidateRequests_UnparseableBody_ShouldBeLeftToHandler(t *testing.T) {
+	status, body := tstPerform(t, "/things/1", `{"name":`, `{"name":"reached the handler"}`)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `{"name":"reached the handler"}`, body)
+}
+
+func TestValidateRequests_PathNotInSpec_ShouldPass(t *testing.T) {
+	status, _ := tstPerform(t, "/other", `[]`, "")
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestValidateRequests_InvalidResponse_ShouldBeReplaced(t *testing.T) {
+	status, body := tstPerform(t, "/things/1", `{"name":"x"}`, `{"name":42}`)
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Contains(t, body, `"message":"response.validation.error"`)
+	require.Contains(t, body, `"details":["/name: Field must be set to string or not be present"]`)
+}
+
+func TestValidateRequests_SecuredAnonymous_ShouldDenyBeforeValidating(t *testing.T) {
+	status, body := tstPerform(t, "/owned/1", `{"owner":"nobody"}`, "")
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Contains(t, body, `"message":"auth.unauthorized.error"`)
+}
+
+func TestValidateRequests_SecuredWithCaller_ShouldValidate(t *testing.T) {
+	caller := identity.Caller{Subject: "someone"}
+	status, _ := tstPerformAs(t, caller, "/owned/1", `{"owner":"nobody"}`, "")
+	require.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = tstPerformAs(t, caller, "/owned/1", `{"name":"x"}`, "")
+	require.Equal(t, http.StatusOK, status)
+}
+
+// run with -race, the routers of kin-openapi v0.80 must not be shared between requests
+func TestValidateRequests_Concurrent_ShouldNotShareRouters(t *testing.T) {
+	spec := tstParse(t)
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(ValidateRequests(spec, false))
+	router.PUT("/*path", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodPut, "/things/1", strings.NewReader(`{"name":"x"}`))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			require.Equal(t, http.StatusNoContent, w.Code)
+		}()
+	}
+	wg.Wait()
+}
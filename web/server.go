@@ -1,61 +1,286 @@
 package web
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"github.com/StephanHCB/go-mailer-service/docs"
+	"github.com/StephanHCB/go-mailer-service/grpcserver"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/audit"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/certificates"
 	"github.com/StephanHCB/go-mailer-service/internal/repository/configuration"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/database"
+	"github.com/StephanHCB/go-mailer-service/internal/repository/messaging"
+	"github.com/StephanHCB/go-mailer-service/internal/service/bouncesrv"
+	"github.com/StephanHCB/go-mailer-service/internal/service/commandsrv"
 	"github.com/StephanHCB/go-mailer-service/internal/service/emailsrv"
+	"github.com/StephanHCB/go-mailer-service/internal/service/eventsrv"
+	"github.com/StephanHCB/go-mailer-service/internal/service/retentionsrv"
+	"github.com/StephanHCB/go-mailer-service/internal/service/webhooksrv"
+	"github.com/StephanHCB/go-mailer-service/web/controller/auditctl"
+	"github.com/StephanHCB/go-mailer-service/web/controller/bouncectl"
 	"github.com/StephanHCB/go-mailer-service/web/controller/emailctl"
+	"github.com/StephanHCB/go-mailer-service/web/controller/emailv2ctl"
 	"github.com/StephanHCB/go-mailer-service/web/controller/healthctl"
+	"github.com/StephanHCB/go-mailer-service/web/controller/managementctl"
+	"github.com/StephanHCB/go-mailer-service/web/controller/metricsctl"
+	"github.com/StephanHCB/go-mailer-service/web/controller/subjectctl"
 	"github.com/StephanHCB/go-mailer-service/web/controller/swaggerctl"
+	"github.com/StephanHCB/go-mailer-service/web/controller/webhookctl"
 	"github.com/StephanHCB/go-mailer-service/web/middleware/authentication"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/bodylimit"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/cors"
 	"github.com/StephanHCB/go-mailer-service/web/middleware/ctxlogger"
-	"github.com/gin-contrib/logger"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/httpmetrics"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/httptracing"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/requestid"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/requestlogging"
+	"github.com/StephanHCB/go-mailer-service/web/middleware/specvalidation"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
-	requestid "github.com/thanhhh/gin-requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
-// use this for easy mocking
-
-var failFunction = fail
-
-func Create() *gin.Engine {
+// Create sets up the router with all middlewares. Fails only if request validation is on and the spec cannot be loaded.
+func Create() (*gin.Engine, error) {
 	// turn off annoying printf logging from gin
 	gin.SetMode(gin.ReleaseMode)
 
 	server := gin.New()
-	server.Use(requestid.RequestID(),
-		logger.SetLogger(),
+	server.Use(requestid.AddRequestIdToContext(configuration.ServerRequestIdTrustedNetworks()),
+		httpmetrics.RecordRequestMetrics(),
+		httptracing.StartServerSpan(),
 		ctxlogger.AddZerologLoggerToRequestContext(),
+		requestlogging.LogRequests(),
+		// preflight requests come without credentials, so this must come before authentication
+		cors.HandleCors(cors.Options{
+			Origins:     configuration.CorsOrigins(),
+			Methods:     configuration.CorsMethods(),
+			Headers:     configuration.CorsHeaders(),
+			Expose:      configuration.CorsExpose(),
+			Credentials: configuration.CorsCredentials(),
+			MaxAge:      configuration.CorsMaxAge(),
+		}),
+		bodylimit.LimitRequestBody(configuration.ServerRequestMaxBodySize()),
 		// TODO secret should come from configuration
 		authentication.AddJWTTokenInfoToContextHandlerFunc(configuration.SecuritySecret()),
+		authentication.AddApiKeyInfoToContextHandlerFunc(configuration.SecurityApiKeys()),
 		gin.Recovery())
 
-	return server
+	if configuration.ServerRequestValidation() {
+		spec, err := loadSpec()
+		if err != nil {
+			return nil, fmt.Errorf("Fatal error while loading api spec for request validation: %s\n", err)
+		}
+		server.Use(specvalidation.ValidateRequests(spec, configuration.ServerResponseValidation()))
+	}
+
+	return server, nil
+}
+
+func loadSpec() (*specvalidation.Spec, error) {
+	doc, err := docs.OpenApi3()
+	if err != nil {
+		return nil, err
+	}
+	return specvalidation.Parse(doc)
 }
 
 func AddRoutes(server *gin.Engine, emailService emailsrv.EmailService) {
 	_ = emailctl.Create(server, emailService)
+	_ = emailv2ctl.Create(server, emailService)
+
+	_ = bouncectl.Create(server, bouncesrv.Create())
+
+	_ = webhookctl.Create(server, webhooksrv.Create())
+
+	_ = auditctl.Create(server, audit.Get())
+
+	_ = subjectctl.Create(server, retentionsrv.Create())
+
+	_ = healthctl.Create(server)
 
-	healthctl.Create(server)
+	_ = managementctl.Create(server)
+
+	metricsctl.Create(server)
 
 	swaggerctl.SetupSwaggerRoutes(server)
 }
 
-func Serve() {
-	server := Create()
+// Serve runs the web server, the grpc server and the background workers until SIGTERM or SIGINT.
+//
+// Failures are returned rather than exiting, so the caller can still flush and close what it set up.
+func Serve() error {
+	server, err := Create()
+	if err != nil {
+		return err
+	}
 
-	AddRoutes(server, emailsrv.Create())
+	emailService := emailsrv.Create()
+	AddRoutes(server, emailService)
+
+	workers, err := startBackgroundWorkers(emailService)
+	if err != nil {
+		return err
+	}
 
 	address := configuration.ServerAddress()
-	log.Info().Msg("Starting web server on " + address)
-	err := server.Run(address)
+	httpServer := &http.Server{Addr: address, Handler: server}
+	reloader, err := setupTls(httpServer)
 	if err != nil {
-		// TODO log a warning on intentional shutdown, and an error otherwise
-		failFunction(fmt.Errorf("Fatal error while starting web server: %s\n", err))
+		workers.stop(context.Background())
+		return err
+	}
+	if reloader != nil {
+		defer reloader.Stop()
 	}
+	// one for each server
+	serveErr := make(chan error, 2)
+	go func() {
+		if reloader != nil {
+			log.Info().Msg("Starting web server with tls on " + address)
+			// certificates come from the tls configuration
+			serveErr <- httpServer.ListenAndServeTLS("", "")
+		} else {
+			log.Info().Msg("Starting web server on " + address)
+			serveErr <- httpServer.ListenAndServe()
+		}
+	}()
+	grpcServer, err := startGrpcServer(emailService, httpServer.TLSConfig, serveErr)
+	if err != nil {
+		_ = httpServer.Close()
+		workers.stop(context.Background())
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+
+	gracePeriod := configuration.ServerShutdownGracePeriod()
+	select {
+	case err := <-serveErr:
+		// we never called Shutdown, so this is always a failure, such as the port being in use
+		ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+		defer cancel()
+		_ = httpServer.Close()
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		workers.stop(ctx)
+		return fmt.Errorf("Fatal error while starting web server: %s\n", err)
+	case sig := <-signals:
+		log.Warn().Msgf("received signal %v, shutting down gracefully within %v", sig, gracePeriod)
+		ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+		defer cancel()
+		// stops accepting connections and waits for in-flight requests
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Error().Err(err).Msgf("web server did not finish in-flight requests within the grace period: %v", err)
+		}
+		if grpcServer != nil {
+			grpcServer.Shutdown(ctx)
+		}
+		workers.stop(ctx)
+		log.Warn().Msg("shutdown complete")
+	}
+	return nil
 }
 
-func fail(err error) {
-	log.Fatal().Err(err).Msg(err.Error())
+// startGrpcServer serves the grpc api on its own port, with tls if the web server uses it. Returns nil if grpc.port is 0.
+//
+// The listener is opened right away, so a port that is in use fails the startup like any other configuration error.
+func startGrpcServer(emailService emailsrv.EmailService, tlsConfig *tls.Config, serveErr chan<- error) (*grpcserver.Server, error) {
+	if !configuration.GrpcEnabled() {
+		return nil, nil
+	}
+	address := configuration.GrpcAddress()
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("Fatal error while starting grpc server: %s\n", err)
+	}
+	options := []grpc.ServerOption{}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpcserver.Create(emailService, options...)
+	go func() {
+		log.Info().Msg("Starting grpc server on " + address)
+		if err := grpcServer.Serve(listener); err != nil {
+			serveErr <- fmt.Errorf("grpc: %w", err)
+		}
+	}()
+	return grpcServer, nil
+}
+
+// setupTls configures https if server.tls.cert is set, returns nil if plain http is to be served.
+func setupTls(httpServer *http.Server) (*certificates.Reloader, error) {
+	if !configuration.ServerTlsEnabled() {
+		return nil, nil
+	}
+	reloader, err := certificates.Create(configuration.ServerTlsCert(), configuration.ServerTlsKey(), configuration.ServerTlsClientCa())
+	if err != nil {
+		return nil, fmt.Errorf("Fatal error while loading tls certificates: %s\n", err)
+	}
+	if configuration.ServerTlsClientCa() != "" {
+		log.Info().Msgf("verifying client certificates against %s, required: %v", configuration.ServerTlsClientCa(), configuration.ServerTlsClientRequired())
+	}
+	httpServer.TLSConfig = reloader.TLSConfig(configuration.ServerTlsMinVersion(), configuration.ServerTlsClientRequired())
+	reloader.Start(configuration.ServerTlsReloadInterval())
+	return reloader, nil
+}
+
+type backgroundWorkers struct {
+	dispatcher *webhooksrv.Dispatcher
+	scheduler  *emailsrv.Scheduler
+	purger     *retentionsrv.Purger
+	// nil if not configured
+	poller   *bouncesrv.MailboxPoller
+	consumer *commandsrv.Consumer
+}
+
+func startBackgroundWorkers(emailService emailsrv.EmailService) (*backgroundWorkers, error) {
+	workers := &backgroundWorkers{}
+
+	workers.dispatcher = webhooksrv.StartDispatcher(database.GetRepository(), configuration.WebhooksMaxAttempts(),
+		configuration.WebhooksRetryBackoff(), configuration.WebhooksTimeout(), eventsrv.Mode(configuration.WebhooksCloudEventsMode()))
+	eventsrv.Register(workers.dispatcher)
+
+	workers.scheduler = emailsrv.StartScheduler(emailService, configuration.SchedulerPollInterval())
+
+	workers.purger = retentionsrv.StartPurger(retentionsrv.Create(), configuration.RetentionPurgeInterval())
+
+	if directory := configuration.BouncesMailboxDirectory(); directory != "" {
+		poller, err := bouncesrv.StartMailboxPoller(bouncesrv.Create(), directory, configuration.BouncesMailboxPollInterval())
+		if err != nil {
+			workers.stop(context.Background())
+			return nil, fmt.Errorf("Fatal error while starting bounce mailbox poller: %s\n", err)
+		}
+		workers.poller = poller
+	}
+
+	if broker := messaging.Get(); broker != nil {
+		workers.consumer = commandsrv.StartConsumer(broker, emailService, configuration.KafkaErrorTopic())
+	}
+	return workers, nil
+}
+
+// stop first stops everything that may produce events, then drains the webhook queue until ctx is done.
+func (w *backgroundWorkers) stop(ctx context.Context) {
+	if w.consumer != nil {
+		w.consumer.Stop()
+	}
+	if w.poller != nil {
+		w.poller.Stop()
+	}
+	w.scheduler.Stop()
+	w.purger.Stop()
+	if err := w.dispatcher.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msgf("pending webhook deliveries were abandoned: %v", err)
+	}
 }